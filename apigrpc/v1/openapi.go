@@ -0,0 +1,12 @@
+package startergrpc
+
+import (
+	// Embeds the OpenAPI spec.
+	_ "embed"
+)
+
+// OpenAPI holds the OpenAPI v2 spec of the API, generated from
+// starter.proto. The host is left to the server serving it.
+//
+//go:embed starter.swagger.json
+var OpenAPI []byte
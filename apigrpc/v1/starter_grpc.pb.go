@@ -22,11 +22,56 @@ type GoStarterClient interface {
 	// Health checking that determines whether backend instance responds properly.
 	Healthcheck(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Returns list of users
-	FindUsers(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FindUsersResponse, error)
+	FindUsers(ctx context.Context, in *FindUsersRequest, opts ...grpc.CallOption) (*FindUsersResponse, error)
 	// Creates a new user
 	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*CreateUserResponse, error)
 	// Returns a single user by ID.
 	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	// Updates the email of a user.
+	//
+	// Only the fields named by the update mask are changed. The gateway
+	// sets the mask to the fields sent in the PATCH body when not set.
+	//
+	// The update is applied only if the user is still at the given
+	// version, sent in the body or in the If-Match header. The gateway
+	// returns the version of the user in the ETag header.
+	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error)
+	// Deletes a user.
+	//
+	// The user is deleted only if it is still at the given version,
+	// sent in the request or in the If-Match header.
+	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Streams all the users, ordered by id.
+	//
+	// The users are read in batches and a batch is read only after
+	// the previous one was sent. Through the gateway every user is
+	// written on its own line, send "Accept: application/x-ndjson"
+	// to get the response as NDJSON.
+	StreamUsers(ctx context.Context, in *StreamUsersRequest, opts ...grpc.CallOption) (GoStarter_StreamUsersClient, error)
+	// Imports the streamed users.
+	//
+	// The mode of the first message tells what happens to the users
+	// that already exist: "skip", the default, leaves them untouched
+	// and "upsert" replaces their email. The users are imported in
+	// batches and the invalid or conflicting ones are reported as
+	// failed without stopping the import.
+	//
+	// Through the gateway the users are imported by uploading a CSV
+	// file to POST /v1/users:import.
+	ImportUsers(ctx context.Context, opts ...grpc.CallOption) (GoStarter_ImportUsersClient, error)
+	// Subscribes an endpoint to user events.
+	//
+	// The secret used to sign the payloads is returned only once,
+	// it is generated when not provided.
+	CreateWebhookSubscription(ctx context.Context, in *CreateWebhookSubscriptionRequest, opts ...grpc.CallOption) (*CreateWebhookSubscriptionResponse, error)
+	// Returns the list of webhook subscriptions.
+	ListWebhookSubscriptions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListWebhookSubscriptionsResponse, error)
+	// Removes a webhook subscription, its delivery log is kept.
+	DeleteWebhookSubscription(ctx context.Context, in *DeleteWebhookSubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Returns the newest deliveries of a webhook subscription.
+	ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error)
+	// Sends a webhook delivery again, including a dead one.
+	RedeliverWebhook(ctx context.Context, in *RedeliverWebhookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type goStarterClient struct {
@@ -46,7 +91,7 @@ func (c *goStarterClient) Healthcheck(ctx context.Context, in *emptypb.Empty, op
 	return out, nil
 }
 
-func (c *goStarterClient) FindUsers(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FindUsersResponse, error) {
+func (c *goStarterClient) FindUsers(ctx context.Context, in *FindUsersRequest, opts ...grpc.CallOption) (*FindUsersResponse, error) {
 	out := new(FindUsersResponse)
 	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/FindUsers", in, out, opts...)
 	if err != nil {
@@ -73,6 +118,135 @@ func (c *goStarterClient) GetUser(ctx context.Context, in *GetUserRequest, opts
 	return out, nil
 }
 
+func (c *goStarterClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error) {
+	out := new(UpdateUserResponse)
+	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/UpdateUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goStarterClient) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/DeleteUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goStarterClient) StreamUsers(ctx context.Context, in *StreamUsersRequest, opts ...grpc.CallOption) (GoStarter_StreamUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GoStarter_ServiceDesc.Streams[0], "/startergrpc.v1.GoStarter/StreamUsers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &goStarterStreamUsersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GoStarter_StreamUsersClient interface {
+	Recv() (*User, error)
+	grpc.ClientStream
+}
+
+type goStarterStreamUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *goStarterStreamUsersClient) Recv() (*User, error) {
+	m := new(User)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *goStarterClient) ImportUsers(ctx context.Context, opts ...grpc.CallOption) (GoStarter_ImportUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GoStarter_ServiceDesc.Streams[1], "/startergrpc.v1.GoStarter/ImportUsers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &goStarterImportUsersClient{stream}
+	return x, nil
+}
+
+type GoStarter_ImportUsersClient interface {
+	Send(*ImportUsersRequest) error
+	CloseAndRecv() (*ImportUsersResponse, error)
+	grpc.ClientStream
+}
+
+type goStarterImportUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *goStarterImportUsersClient) Send(m *ImportUsersRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *goStarterImportUsersClient) CloseAndRecv() (*ImportUsersResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportUsersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *goStarterClient) CreateWebhookSubscription(ctx context.Context, in *CreateWebhookSubscriptionRequest, opts ...grpc.CallOption) (*CreateWebhookSubscriptionResponse, error) {
+	out := new(CreateWebhookSubscriptionResponse)
+	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/CreateWebhookSubscription", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goStarterClient) ListWebhookSubscriptions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListWebhookSubscriptionsResponse, error) {
+	out := new(ListWebhookSubscriptionsResponse)
+	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/ListWebhookSubscriptions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goStarterClient) DeleteWebhookSubscription(ctx context.Context, in *DeleteWebhookSubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/DeleteWebhookSubscription", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goStarterClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error) {
+	out := new(ListWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/ListWebhookDeliveries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goStarterClient) RedeliverWebhook(ctx context.Context, in *RedeliverWebhookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/startergrpc.v1.GoStarter/RedeliverWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GoStarterServer is the server API for GoStarter service.
 // All implementations must embed UnimplementedGoStarterServer
 // for forward compatibility
@@ -80,11 +254,56 @@ type GoStarterServer interface {
 	// Health checking that determines whether backend instance responds properly.
 	Healthcheck(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	// Returns list of users
-	FindUsers(context.Context, *emptypb.Empty) (*FindUsersResponse, error)
+	FindUsers(context.Context, *FindUsersRequest) (*FindUsersResponse, error)
 	// Creates a new user
 	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
 	// Returns a single user by ID.
 	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
+	// Updates the email of a user.
+	//
+	// Only the fields named by the update mask are changed. The gateway
+	// sets the mask to the fields sent in the PATCH body when not set.
+	//
+	// The update is applied only if the user is still at the given
+	// version, sent in the body or in the If-Match header. The gateway
+	// returns the version of the user in the ETag header.
+	UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error)
+	// Deletes a user.
+	//
+	// The user is deleted only if it is still at the given version,
+	// sent in the request or in the If-Match header.
+	DeleteUser(context.Context, *DeleteUserRequest) (*emptypb.Empty, error)
+	// Streams all the users, ordered by id.
+	//
+	// The users are read in batches and a batch is read only after
+	// the previous one was sent. Through the gateway every user is
+	// written on its own line, send "Accept: application/x-ndjson"
+	// to get the response as NDJSON.
+	StreamUsers(*StreamUsersRequest, GoStarter_StreamUsersServer) error
+	// Imports the streamed users.
+	//
+	// The mode of the first message tells what happens to the users
+	// that already exist: "skip", the default, leaves them untouched
+	// and "upsert" replaces their email. The users are imported in
+	// batches and the invalid or conflicting ones are reported as
+	// failed without stopping the import.
+	//
+	// Through the gateway the users are imported by uploading a CSV
+	// file to POST /v1/users:import.
+	ImportUsers(GoStarter_ImportUsersServer) error
+	// Subscribes an endpoint to user events.
+	//
+	// The secret used to sign the payloads is returned only once,
+	// it is generated when not provided.
+	CreateWebhookSubscription(context.Context, *CreateWebhookSubscriptionRequest) (*CreateWebhookSubscriptionResponse, error)
+	// Returns the list of webhook subscriptions.
+	ListWebhookSubscriptions(context.Context, *emptypb.Empty) (*ListWebhookSubscriptionsResponse, error)
+	// Removes a webhook subscription, its delivery log is kept.
+	DeleteWebhookSubscription(context.Context, *DeleteWebhookSubscriptionRequest) (*emptypb.Empty, error)
+	// Returns the newest deliveries of a webhook subscription.
+	ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	// Sends a webhook delivery again, including a dead one.
+	RedeliverWebhook(context.Context, *RedeliverWebhookRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedGoStarterServer()
 }
 
@@ -95,7 +314,7 @@ type UnimplementedGoStarterServer struct {
 func (UnimplementedGoStarterServer) Healthcheck(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Healthcheck not implemented")
 }
-func (UnimplementedGoStarterServer) FindUsers(context.Context, *emptypb.Empty) (*FindUsersResponse, error) {
+func (UnimplementedGoStarterServer) FindUsers(context.Context, *FindUsersRequest) (*FindUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindUsers not implemented")
 }
 func (UnimplementedGoStarterServer) CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error) {
@@ -104,6 +323,33 @@ func (UnimplementedGoStarterServer) CreateUser(context.Context, *CreateUserReque
 func (UnimplementedGoStarterServer) GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
 }
+func (UnimplementedGoStarterServer) UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedGoStarterServer) DeleteUser(context.Context, *DeleteUserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
+}
+func (UnimplementedGoStarterServer) StreamUsers(*StreamUsersRequest, GoStarter_StreamUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUsers not implemented")
+}
+func (UnimplementedGoStarterServer) ImportUsers(GoStarter_ImportUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportUsers not implemented")
+}
+func (UnimplementedGoStarterServer) CreateWebhookSubscription(context.Context, *CreateWebhookSubscriptionRequest) (*CreateWebhookSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhookSubscription not implemented")
+}
+func (UnimplementedGoStarterServer) ListWebhookSubscriptions(context.Context, *emptypb.Empty) (*ListWebhookSubscriptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookSubscriptions not implemented")
+}
+func (UnimplementedGoStarterServer) DeleteWebhookSubscription(context.Context, *DeleteWebhookSubscriptionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhookSubscription not implemented")
+}
+func (UnimplementedGoStarterServer) ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedGoStarterServer) RedeliverWebhook(context.Context, *RedeliverWebhookRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedeliverWebhook not implemented")
+}
 func (UnimplementedGoStarterServer) mustEmbedUnimplementedGoStarterServer() {}
 
 // UnsafeGoStarterServer may be embedded to opt out of forward compatibility for this service.
@@ -136,7 +382,7 @@ func _GoStarter_Healthcheck_Handler(srv interface{}, ctx context.Context, dec fu
 }
 
 func _GoStarter_FindUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(FindUsersRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -148,7 +394,7 @@ func _GoStarter_FindUsers_Handler(srv interface{}, ctx context.Context, dec func
 		FullMethod: "/startergrpc.v1.GoStarter/FindUsers",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GoStarterServer).FindUsers(ctx, req.(*emptypb.Empty))
+		return srv.(GoStarterServer).FindUsers(ctx, req.(*FindUsersRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -189,6 +435,179 @@ func _GoStarter_GetUser_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GoStarter_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoStarterServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/startergrpc.v1.GoStarter/UpdateUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoStarterServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoStarter_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoStarterServer).DeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/startergrpc.v1.GoStarter/DeleteUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoStarterServer).DeleteUser(ctx, req.(*DeleteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoStarter_StreamUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUsersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GoStarterServer).StreamUsers(m, &goStarterStreamUsersServer{stream})
+}
+
+type GoStarter_StreamUsersServer interface {
+	Send(*User) error
+	grpc.ServerStream
+}
+
+type goStarterStreamUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *goStarterStreamUsersServer) Send(m *User) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GoStarter_ImportUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GoStarterServer).ImportUsers(&goStarterImportUsersServer{stream})
+}
+
+type GoStarter_ImportUsersServer interface {
+	SendAndClose(*ImportUsersResponse) error
+	Recv() (*ImportUsersRequest, error)
+	grpc.ServerStream
+}
+
+type goStarterImportUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *goStarterImportUsersServer) SendAndClose(m *ImportUsersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *goStarterImportUsersServer) Recv() (*ImportUsersRequest, error) {
+	m := new(ImportUsersRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _GoStarter_CreateWebhookSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoStarterServer).CreateWebhookSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/startergrpc.v1.GoStarter/CreateWebhookSubscription",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoStarterServer).CreateWebhookSubscription(ctx, req.(*CreateWebhookSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoStarter_ListWebhookSubscriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoStarterServer).ListWebhookSubscriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/startergrpc.v1.GoStarter/ListWebhookSubscriptions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoStarterServer).ListWebhookSubscriptions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoStarter_DeleteWebhookSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoStarterServer).DeleteWebhookSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/startergrpc.v1.GoStarter/DeleteWebhookSubscription",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoStarterServer).DeleteWebhookSubscription(ctx, req.(*DeleteWebhookSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoStarter_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoStarterServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/startergrpc.v1.GoStarter/ListWebhookDeliveries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoStarterServer).ListWebhookDeliveries(ctx, req.(*ListWebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoStarter_RedeliverWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedeliverWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoStarterServer).RedeliverWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/startergrpc.v1.GoStarter/RedeliverWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoStarterServer).RedeliverWebhook(ctx, req.(*RedeliverWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GoStarter_ServiceDesc is the grpc.ServiceDesc for GoStarter service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -212,7 +631,46 @@ var GoStarter_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUser",
 			Handler:    _GoStarter_GetUser_Handler,
 		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _GoStarter_UpdateUser_Handler,
+		},
+		{
+			MethodName: "DeleteUser",
+			Handler:    _GoStarter_DeleteUser_Handler,
+		},
+		{
+			MethodName: "CreateWebhookSubscription",
+			Handler:    _GoStarter_CreateWebhookSubscription_Handler,
+		},
+		{
+			MethodName: "ListWebhookSubscriptions",
+			Handler:    _GoStarter_ListWebhookSubscriptions_Handler,
+		},
+		{
+			MethodName: "DeleteWebhookSubscription",
+			Handler:    _GoStarter_DeleteWebhookSubscription_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _GoStarter_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "RedeliverWebhook",
+			Handler:    _GoStarter_RedeliverWebhook_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUsers",
+			Handler:       _GoStarter_StreamUsers_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportUsers",
+			Handler:       _GoStarter_ImportUsers_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "v1/starter.proto",
 }
@@ -0,0 +1,512 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+	"unicode"
+
+	startergrpc "github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/clientgrpc"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// tokenEnv is the environment variable holding the
+// token when the --token flag isn't set.
+const tokenEnv = "GOSTARTER_TOKEN"
+
+// ClientCmd groups the subcommands calling the RPCs
+// of a running server.
+var ClientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Call the RPCs of a running server.",
+	Long: "Call the RPCs of a running server, one subcommand per RPC. " +
+		"The request is given as JSON, - reads it from stdin, and " +
+		"the responses are written as JSON to stdout.",
+}
+
+// ClientHealthCmd subcommand that checks the health of the server.
+var ClientHealthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check the health of the server.",
+	Args:  cobra.NoArgs,
+	// The errors are the ones of the calls,
+	// the usage wouldn't help.
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(
+			context.Background(),
+			os.Interrupt,
+		)
+		defer cancel()
+
+		conn, err := newClientConn(cmd)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		service, err := cmd.Flags().GetString("service")
+		if err != nil {
+			return fmt.Errorf("get service flag: %w", err)
+		}
+
+		watch, err := cmd.Flags().GetBool("watch")
+		if err != nil {
+			return fmt.Errorf("get watch flag: %w", err)
+		}
+
+		client := grpc_health_v1.NewHealthClient(conn)
+		req := &grpc_health_v1.HealthCheckRequest{Service: service}
+
+		if !watch {
+			ctx, cancel := withCallTimeout(ctx, cmd)
+			defer cancel()
+
+			resp, err := client.Check(ctx, req)
+			if err != nil {
+				return fmt.Errorf("check: %w", err)
+			}
+
+			return writeMessage(cmd.OutOrStdout(), resp)
+		}
+
+		stream, err := client.Watch(ctx, req)
+		if err != nil {
+			return fmt.Errorf("watch: %w", err)
+		}
+
+		return receiveAll(cmd.OutOrStdout(), stream, func() proto.Message {
+			return new(grpc_health_v1.HealthCheckResponse)
+		})
+	},
+}
+
+// newRPCCmd returns the subcommand calling the given RPC.
+func newRPCCmd(method protoreflect.MethodDescriptor) *cobra.Command {
+	long := fmt.Sprintf(
+		"Call the %s RPC with the JSON request, {} when not given.",
+		method.Name(),
+	)
+
+	if method.IsStreamingClient() {
+		long = fmt.Sprintf(
+			"Call the %s RPC with the JSON requests read from stdin, "+
+				"one object after the other.",
+			method.Name(),
+		)
+	}
+
+	return &cobra.Command{
+		Use:   kebabCase(string(method.Name())) + " [JSON]",
+		Short: fmt.Sprintf("Call the %s RPC.", method.Name()),
+		Long:  long,
+		Args:  cobra.MaximumNArgs(1),
+		// The errors are the ones of the calls,
+		// the usage wouldn't help.
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(
+				context.Background(),
+				os.Interrupt,
+			)
+			defer cancel()
+
+			conn, err := newClientConn(cmd)
+			if err != nil {
+				return err
+			}
+
+			defer func() {
+				_ = conn.Close()
+			}()
+
+			input := "{}"
+
+			switch {
+			case method.IsStreamingClient():
+				input = "-"
+
+			case len(args) > 0:
+				input = args[0]
+			}
+
+			return callRPC(ctx, cmd, conn, method, input)
+		},
+	}
+}
+
+// callRPC calls the RPC with the JSON input, - reading it from
+// stdin, and writes the responses to the command output.
+func callRPC(
+	ctx context.Context,
+	cmd *cobra.Command,
+	conn *grpc.ClientConn,
+	method protoreflect.MethodDescriptor,
+	input string,
+) error {
+	fullMethod := fmt.Sprintf(
+		"/%s/%s",
+		method.Parent().FullName(),
+		method.Name(),
+	)
+
+	newRequest, err := messageConstructor(method.Input())
+	if err != nil {
+		return err
+	}
+
+	newResponse, err := messageConstructor(method.Output())
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = strings.NewReader(input)
+
+	if input == "-" {
+		in = cmd.InOrStdin()
+	}
+
+	requests := json.NewDecoder(in)
+
+	readRequest := func() (proto.Message, error) {
+		var raw json.RawMessage
+
+		err := requests.Decode(&raw)
+		if err != nil {
+			return nil, err
+		}
+
+		req := newRequest()
+
+		err = protojson.Unmarshal(raw, req)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal request: %w", err)
+		}
+
+		return req, nil
+	}
+
+	if !method.IsStreamingClient() && !method.IsStreamingServer() {
+		req, err := readRequest()
+		if err != nil {
+			return fmt.Errorf("read request: %w", err)
+		}
+
+		ctx, cancel := withCallTimeout(ctx, cmd)
+		defer cancel()
+
+		resp := newResponse()
+
+		err = conn.Invoke(ctx, fullMethod, req, resp)
+		if err != nil {
+			return fmt.Errorf("call %s: %w", method.Name(), err)
+		}
+
+		return writeMessage(cmd.OutOrStdout(), resp)
+	}
+
+	stream, err := conn.NewStream(
+		ctx,
+		&grpc.StreamDesc{
+			StreamName:    string(method.Name()),
+			ServerStreams: method.IsStreamingServer(),
+			ClientStreams: method.IsStreamingClient(),
+		},
+		fullMethod,
+	)
+	if err != nil {
+		return fmt.Errorf("open %s stream: %w", method.Name(), err)
+	}
+
+	for {
+		req, err := readRequest()
+		if errors.Is(err, io.EOF) && method.IsStreamingClient() {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("read request: %w", err)
+		}
+
+		err = stream.SendMsg(req)
+		if err != nil {
+			// The status of the call is returned by RecvMsg.
+			break
+		}
+
+		if !method.IsStreamingClient() {
+			break
+		}
+	}
+
+	err = stream.CloseSend()
+	if err != nil {
+		return fmt.Errorf("close send: %w", err)
+	}
+
+	return receiveAll(cmd.OutOrStdout(), stream, newResponse)
+}
+
+// receiveAll writes the messages received on stream
+// to w until the call ends.
+func receiveAll(
+	w io.Writer,
+	stream grpc.ClientStream,
+	newResponse func() proto.Message,
+) error {
+	for {
+		resp := newResponse()
+
+		err := stream.RecvMsg(resp)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("receive: %w", err)
+		}
+
+		err = writeMessage(w, resp)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// messageConstructor returns a function creating
+// messages of the given type.
+func messageConstructor(
+	desc protoreflect.MessageDescriptor,
+) (func() proto.Message, error) {
+	typ, err := protoregistry.GlobalTypes.FindMessageByName(desc.FullName())
+	if err != nil {
+		return nil, fmt.Errorf("find message %s: %w", desc.FullName(), err)
+	}
+
+	return func() proto.Message {
+		return typ.New().Interface()
+	}, nil
+}
+
+// writeMessage writes msg as JSON, with the field names of the
+// proto like the gateway.
+func writeMessage(w io.Writer, msg proto.Message) error {
+	b, err := protojson.MarshalOptions{
+		Multiline:       true,
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal response: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+
+	return err
+}
+
+// newClientConn connects to the server configured
+// by the flags of the client command.
+func newClientConn(cmd *cobra.Command) (*grpc.ClientConn, error) {
+	flags := cmd.Flags()
+
+	address, err := flags.GetString("address")
+	if err != nil {
+		return nil, fmt.Errorf("get address flag: %w", err)
+	}
+
+	token, err := flags.GetString("token")
+	if err != nil {
+		return nil, fmt.Errorf("get token flag: %w", err)
+	}
+
+	if token == "" {
+		token = os.Getenv(tokenEnv)
+	}
+
+	tlsConfig, err := clientTLSConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []clientgrpc.Option{
+		clientgrpc.WithNoTLS(),
+	}
+
+	if tlsConfig != nil {
+		opts = []clientgrpc.Option{
+			clientgrpc.WithTLS(tlsConfig),
+		}
+	}
+
+	if token != "" {
+		opts = append(opts, clientgrpc.WithBearerToken(token))
+	}
+
+	return clientgrpc.NewClientConn(address, opts...)
+}
+
+// clientTLSConfig returns the TLS config set by the flags of the
+// client command, nil when the connection isn't secured.
+func clientTLSConfig(cmd *cobra.Command) (*tls.Config, error) {
+	flags := cmd.Flags()
+
+	useTLS, err := flags.GetBool("tls")
+	if err != nil {
+		return nil, fmt.Errorf("get tls flag: %w", err)
+	}
+
+	caCert, err := flags.GetString("ca-cert")
+	if err != nil {
+		return nil, fmt.Errorf("get ca-cert flag: %w", err)
+	}
+
+	serverName, err := flags.GetString("server-name")
+	if err != nil {
+		return nil, fmt.Errorf("get server-name flag: %w", err)
+	}
+
+	skipVerify, err := flags.GetBool("insecure-skip-verify")
+	if err != nil {
+		return nil, fmt.Errorf("get insecure-skip-verify flag: %w", err)
+	}
+
+	if !useTLS && caCert == "" && serverName == "" && !skipVerify {
+		return nil, nil
+	}
+
+	// nolint: gosec // skipping the verification is opted in.
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: skipVerify,
+	}
+
+	if caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("read ca cert: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", caCert)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// withCallTimeout returns a copy of ctx ending after the
+// --timeout of the client command, if any.
+func withCallTimeout(
+	ctx context.Context,
+	cmd *cobra.Command,
+) (context.Context, context.CancelFunc) {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// kebabCase turns CreateUser into create-user.
+func kebabCase(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func init() {
+	const (
+		defaultAddress = "localhost:7349"
+		defaultTimeout = 10 * time.Second
+	)
+
+	flags := ClientCmd.PersistentFlags()
+
+	flags.String("address", defaultAddress, "address of the grpc server")
+	flags.String(
+		"token",
+		"",
+		"bearer token of the calls, "+tokenEnv+" when not set",
+	)
+	flags.Bool("tls", false, "connect with TLS")
+	flags.String(
+		"ca-cert",
+		"",
+		"PEM file of the CAs verifying the server, implies --tls",
+	)
+	flags.String(
+		"server-name",
+		"",
+		"name verified in the server certificate, implies --tls",
+	)
+	flags.Bool(
+		"insecure-skip-verify",
+		false,
+		"don't verify the server certificate, implies --tls",
+	)
+	flags.Duration(
+		"timeout",
+		defaultTimeout,
+		"timeout of the unary calls, 0 for none",
+	)
+
+	ClientHealthCmd.Flags().String(
+		"service",
+		"",
+		"service whose health is checked, the server when empty",
+	)
+	ClientHealthCmd.Flags().Bool(
+		"watch",
+		false,
+		"watch the health until interrupted",
+	)
+
+	methods := startergrpc.File_v1_starter_proto.Services().
+		ByName("GoStarter").
+		Methods()
+
+	for i := 0; i < methods.Len(); i++ {
+		ClientCmd.AddCommand(newRPCCmd(methods.Get(i)))
+	}
+
+	ClientCmd.AddCommand(ClientHealthCmd)
+
+	RootCmd.AddCommand(ClientCmd)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/logging"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/usercsv"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/service"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// ImportCmd groups the subcommands that import data.
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import data from files.",
+}
+
+// ImportUsersCmd subcommand that imports the users of a CSV file.
+var ImportUsersCmd = &cobra.Command{
+	Use:   "users FILE",
+	Short: "Import the users of a CSV file.",
+	Long: "Import the users of a CSV file, - reads the file from stdin. " +
+		"The header names an email column and, optionally, an id column. " +
+		"The report of every row is written as CSV to stdout.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(
+			context.Background(),
+			os.Interrupt,
+		)
+		defer cancel()
+
+		cfg, err := config.LoadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+
+		logger, _, err := logging.New(cfg)
+		if err != nil {
+			return fmt.Errorf("new logger: %w", err)
+		}
+
+		defer func() {
+			_ = logger.Sync()
+		}()
+
+		modeFlag, err := cmd.Flags().GetString("mode")
+		if err != nil {
+			return fmt.Errorf("get mode flag: %w", err)
+		}
+
+		mode, err := user.ParseImportMode(modeFlag)
+		if err != nil {
+			return fmt.Errorf("parse mode: %w", err)
+		}
+
+		var file io.Reader = os.Stdin
+
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("open file: %w", err)
+			}
+
+			defer func() {
+				_ = f.Close()
+			}()
+
+			file = f
+		}
+
+		reader, err := usercsv.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("new csv reader: %w", err)
+		}
+
+		app, cleanup := service.NewApplication(ctx, logger, cfg)
+		defer func() {
+			err := cleanup()
+			if err != nil {
+				logger.Error("error during cleanup", zap.Error(err))
+			}
+		}()
+
+		var (
+			report = usercsv.NewReportWriter(cmd.OutOrStdout())
+			counts = make(map[user.ImportStatus]int)
+			row    int
+		)
+
+		for {
+			rows, err := reader.ReadBatch(command.ImportBatchSize)
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return fmt.Errorf("read users: %w", err)
+			}
+
+			results, err := app.Commands.ImportUsers.Handle(
+				ctx,
+				command.ImportUsers{
+					Mode: mode,
+					Rows: rows,
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("import users: %w", err)
+			}
+
+			for _, res := range results {
+				row++
+				counts[res.Status]++
+
+				err = report.Write(usercsv.NewReportRow(row, res))
+				if err != nil {
+					return fmt.Errorf("write report: %w", err)
+				}
+			}
+
+			err = report.Flush()
+			if err != nil {
+				return fmt.Errorf("flush report: %w", err)
+			}
+		}
+
+		logger.Info(
+			"Users imported",
+			zap.Int("created", counts[user.ImportStatusCreated]),
+			zap.Int("updated", counts[user.ImportStatusUpdated]),
+			zap.Int("skipped", counts[user.ImportStatusSkipped]),
+			zap.Int("failed", counts[user.ImportStatusFailed]),
+		)
+
+		return nil
+	},
+}
+
+func init() {
+	ImportUsersCmd.Flags().String(
+		"mode",
+		string(user.ImportModeSkip),
+		"what happens to the existing users: skip or upsert",
+	)
+
+	ImportCmd.AddCommand(ImportUsersCmd)
+
+	RootCmd.AddCommand(ImportCmd)
+}
@@ -7,9 +7,10 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/purposeinplay/go-commons/logs"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/sqlite"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/logging"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -20,7 +21,12 @@ var migrateCmd = &cobra.Command{
 	Long: "Migrate database structures. " +
 		"This will create new tables and add missing columns and indexes.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		logger, err := logs.NewLogger()
+		c, err := config.LoadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+
+		logger, _, err := logging.New(c)
 		if err != nil {
 			return fmt.Errorf("new logger: %w", err)
 		}
@@ -29,9 +35,24 @@ var migrateCmd = &cobra.Command{
 			_ = logger.Sync()
 		}()
 
-		c, err := config.LoadConfig(cmd)
-		if err != nil {
-			return fmt.Errorf("load config: %w", err)
+		if c.DB.Driver == sqlite.DriverName {
+			db, err := sqlite.Open(c.DB.NAME)
+			if err != nil {
+				return fmt.Errorf("connect db: %w", err)
+			}
+
+			defer func() {
+				_ = db.Close()
+			}()
+
+			err = sqlite.Migrate(db)
+			if err != nil {
+				return fmt.Errorf("running migrations: %w", err)
+			}
+
+			logger.Info("successfully run migrations command")
+
+			return nil
 		}
 
 		db, err := psql.Connect(c)
@@ -10,8 +10,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/purposeinplay/go-commons/auth"
-	"github.com/purposeinplay/go-commons/logs"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/logging"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/admin"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/service"
 	"github.com/spf13/cobra"
@@ -27,28 +28,66 @@ var ServerCmd = &cobra.Command{
 			os.Kill,
 		)
 
-		logger, err := logs.NewLogger()
-		defer func() {
-			_ = logger.Sync()
-		}()
-
+		config, err := config.LoadConfig(cmd)
 		if err != nil {
-			return fmt.Errorf("could not create logger %w", err)
+			return fmt.Errorf("unable to read config %w", err)
 		}
 
-		config, err := config.LoadConfig(cmd)
+		logger, logLevel, err := logging.New(config)
 		if err != nil {
-			return fmt.Errorf("unable to read config %w", err)
+			return fmt.Errorf("could not create logger %w", err)
 		}
 
+		defer func() {
+			_ = logger.Sync()
+		}()
+
 		logger.Info("Openmatch API starting")
 
+		pools := service.NewPools()
+
+		if config.ADMIN.Address != "" {
+			adminServer, err := admin.NewServer(
+				config.ADMIN.Address,
+				admin.WithLogLevel(logLevel),
+				admin.WithConfig(config.Redacted()),
+				admin.WithDBStats(pools.Stats),
+			)
+			if err != nil {
+				return fmt.Errorf("new admin server: %w", err)
+			}
+
+			go func() {
+				err := adminServer.Serve()
+				if err != nil {
+					logger.Error("serve admin server", zap.Error(err))
+				}
+			}()
+
+			defer func() {
+				err := adminServer.Close()
+				if err != nil {
+					logger.Error("close admin server", zap.Error(err))
+				}
+			}()
+
+			logger.Info(
+				"admin server listening",
+				zap.Stringer("address", adminServer.Addr()),
+			)
+		}
+
 		jwtManager := auth.NewJWTManager(
 			config.JWT.Secret,
 			time.Duration(config.JWT.AccessTokenExp),
 		)
 
-		app, cleanup := service.NewApplication(ctx, logger, config)
+		app, cleanup := service.NewApplication(
+			ctx,
+			logger,
+			config,
+			service.WithPools(pools),
+		)
 		defer func() {
 			err := cleanup()
 			if err != nil {
@@ -56,7 +95,37 @@ var ServerCmd = &cobra.Command{
 			}
 		}()
 
-		server := grpc.NewGrpcServer(logger, config, app, jwtManager)
+		rateLimitStore, err := service.NewRateLimitStore(config)
+		if err != nil {
+			return fmt.Errorf("new rate limit store: %w", err)
+		}
+
+		idempotencyStore, cleanupIdempotency, err := service.
+			NewIdempotencyStore(logger, config, service.WithPools(pools))
+		if err != nil {
+			return fmt.Errorf("new idempotency store: %w", err)
+		}
+		defer func() {
+			err := cleanupIdempotency()
+			if err != nil {
+				logger.Error("close idempotency store", zap.Error(err))
+			}
+		}()
+
+		server, err := grpc.NewGrpcServer(
+			logger,
+			config,
+			app,
+			jwtManager,
+			grpc.WithRateLimitStore(rateLimitStore),
+			grpc.WithIdempotencyStore(
+				idempotencyStore,
+				config.IDEMPOTENCY.TTL,
+			),
+		)
+		if err != nil {
+			return fmt.Errorf("new grpc server: %w", err)
+		}
 		defer func() {
 			err := server.Close()
 			if err != nil {
@@ -74,5 +143,11 @@ var ServerCmd = &cobra.Command{
 }
 
 func init() {
+	ServerCmd.Flags().String(
+		"storage",
+		"database",
+		"where the data is kept: database or memory",
+	)
+
 	RootCmd.AddCommand(ServerCmd)
 }
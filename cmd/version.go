@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/buildinfo"
+	"github.com/spf13/cobra"
+)
+
+// VersionCmd subcommand that prints the build information.
+var VersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, commit and Go version of the binary.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := buildinfo.Read()
+
+		commit := info.Commit
+		if commit == "" {
+			commit = "unknown"
+		}
+
+		if info.Modified {
+			commit += " (modified)"
+		}
+
+		_, err := fmt.Fprintf(
+			cmd.OutOrStdout(),
+			"version: %s\ncommit: %s\ntime: %s\ngo: %s\n",
+			info.Version,
+			commit,
+			info.Time,
+			info.GoVersion,
+		)
+
+		return err
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(VersionCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/logging"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/service"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// WorkerCmd subcommand that runs the background jobs.
+var WorkerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run the background jobs.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(
+			context.Background(),
+			os.Interrupt,
+		)
+		defer cancel()
+
+		cfg, err := config.LoadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+
+		logger, _, err := logging.New(cfg)
+		if err != nil {
+			return fmt.Errorf("new logger: %w", err)
+		}
+
+		defer func() {
+			_ = logger.Sync()
+		}()
+
+		worker, cleanup := service.NewWorker(ctx, logger, cfg)
+		defer func() {
+			err := cleanup()
+			if err != nil {
+				logger.Error("error during cleanup", zap.Error(err))
+			}
+		}()
+
+		logger.Info("Worker started")
+
+		worker.Run(ctx)
+
+		logger.Info("Worker stopped")
+
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(WorkerCmd)
+}
@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores the cached values.
+type Backend interface {
+	// Get returns the value stored under key and a flag set to
+	// true if it was found and not expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores the value under key for ttl, forever when
+	// ttl is zero.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the values stored under the given keys.
+	Delete(ctx context.Context, keys ...string) error
+}
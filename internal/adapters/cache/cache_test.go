@@ -0,0 +1,410 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/cache"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"go.uber.org/zap"
+)
+
+func TestBackends(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	backends := map[string]func(t *testing.T) (
+		backend cache.Backend,
+		expire func(),
+	){
+		"LRU": func(t *testing.T) (cache.Backend, func()) {
+			return cache.NewLRU(10), func() {
+				time.Sleep(20 * time.Millisecond)
+			}
+		},
+		"Redis": func(t *testing.T) (cache.Backend, func()) {
+			srv := miniredis.RunT(t)
+
+			client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+			t.Cleanup(func() {
+				_ = client.Close()
+			})
+
+			return cache.NewRedis(client), func() {
+				srv.FastForward(time.Second)
+			}
+		},
+	}
+
+	for name, newBackend := range backends {
+		newBackend := newBackend
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			backend, expire := newBackend(t)
+
+			_, found, err := backend.Get(ctx, "a")
+			i.NoErr(err)
+			i.True(!found)
+
+			i.NoErr(backend.Set(ctx, "a", []byte("1"), 0))
+			i.NoErr(backend.Set(ctx, "b", []byte("2"), 10*time.Millisecond))
+
+			value, found, err := backend.Get(ctx, "a")
+			i.NoErr(err)
+			i.True(found)
+			i.Equal("1", string(value))
+
+			expire()
+
+			_, found, err = backend.Get(ctx, "b")
+			i.NoErr(err)
+			i.True(!found)
+
+			i.NoErr(backend.Delete(ctx, "a", "missing"))
+
+			_, found, err = backend.Get(ctx, "a")
+			i.NoErr(err)
+			i.True(!found)
+		})
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	var (
+		i   = is.New(t)
+		ctx = context.Background()
+		lru = cache.NewLRU(2)
+	)
+
+	i.NoErr(lru.Set(ctx, "a", []byte("1"), 0))
+	i.NoErr(lru.Set(ctx, "b", []byte("2"), 0))
+
+	_, found, _ := lru.Get(ctx, "a")
+	i.True(found)
+
+	i.NoErr(lru.Set(ctx, "c", []byte("3"), 0))
+	i.Equal(2, lru.Len())
+
+	_, found, _ = lru.Get(ctx, "b")
+	i.True(!found)
+
+	_, found, _ = lru.Get(ctx, "a")
+	i.True(found)
+}
+
+func TestUsers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newUsers := func(t *testing.T) (
+		*cache.Users,
+		*countingRepository,
+		cache.Backend,
+	) {
+		var (
+			backend = cache.NewLRU(100)
+			repo    = &countingRepository{
+				UserRepository: memory.NewUserRepository(),
+			}
+		)
+
+		return cache.NewUsers(zap.NewNop(), backend, repo), repo, backend
+	}
+
+	createUser := func(t *testing.T, users *cache.Users) *user.User {
+		u := user.MustNew(uuid.New(), uuid.New().String()+"@email.com")
+
+		is.New(t).NoErr(users.CreateUser(ctx, u))
+
+		return u
+	}
+
+	t.Run("CachesReads", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		users, repo, _ := newUsers(t)
+
+		u := createUser(t, users)
+
+		for n := 0; n < 3; n++ {
+			found, err := users.GetUserByID(ctx, u.ID())
+			i.NoErr(err)
+			i.Equal(u.Email(), found.Email)
+
+			found2, err := users.FindUsers(ctx, user.Filter{ID: u.ID()})
+			i.NoErr(err)
+			i.Equal(1, len(found2))
+		}
+
+		i.Equal(int64(1), repo.gets())
+		i.Equal(int64(1), repo.finds())
+	})
+
+	t.Run("InvalidatesOnUpdate", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		users, _, _ := newUsers(t)
+
+		u := createUser(t, users)
+
+		_, err := users.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+
+		_, err = users.FindUsers(ctx, user.Filter{})
+		i.NoErr(err)
+
+		email := uuid.New().String() + "@email.com"
+
+		err = users.UpdateUser(
+			ctx,
+			u.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.ChangeEmail(email)
+			},
+		)
+		i.NoErr(err)
+
+		found, err := users.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+		i.Equal(email, found.Email)
+
+		all, err := users.FindUsers(ctx, user.Filter{})
+		i.NoErr(err)
+		i.Equal([]query.User{found}, all)
+	})
+
+	t.Run("InvalidatesUnitOfWorkChanges", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		users, repo, backend := newUsers(t)
+
+		u := createUser(t, users)
+
+		_, err := users.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+
+		var (
+			uow = cache.NewUnitOfWork(
+				zap.NewNop(),
+				backend,
+				memory.NewUnitOfWork(repo.UserRepository),
+			)
+			email = uuid.New().String() + "@email.com"
+		)
+
+		err = uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+			return repos.Users.UpdateUser(
+				ctx,
+				u.ID(),
+				func(_ context.Context, u *user.User) (*user.User, error) {
+					return u, u.ChangeEmail(email)
+				},
+			)
+		})
+		i.NoErr(err)
+
+		found, err := users.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+		i.Equal(email, found.Email)
+		i.Equal(int64(2), repo.gets())
+	})
+
+	t.Run("InvalidatesOnEvent", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		users, repo, backend := newUsers(t)
+
+		u := createUser(t, users)
+
+		_, err := users.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+
+		// Changed by another instance.
+		err = repo.UpdateUser(
+			ctx,
+			u.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.Delete()
+			},
+		)
+		i.NoErr(err)
+
+		_, err = users.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+
+		invalidator := cache.NewUserInvalidator(zap.NewNop(), backend)
+
+		err = invalidator.Publish(ctx, outbox.Message{
+			AggregateType: user.AggregateType,
+			AggregateID:   u.ID().String(),
+			EventType:     user.EventNameUserDeleted,
+		})
+		i.NoErr(err)
+
+		_, err = users.GetUserByID(ctx, u.ID())
+		i.True(err != nil)
+	})
+
+	t.Run("DeduplicatesConcurrentMisses", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		users, repo, _ := newUsers(t)
+
+		u := createUser(t, users)
+
+		repo.gate = make(chan struct{})
+
+		const readers = 10
+
+		var (
+			wg    sync.WaitGroup
+			found = make([]query.User, readers)
+			errs  = make([]error, readers)
+		)
+
+		wg.Add(readers)
+
+		for n := 0; n < readers; n++ {
+			n := n
+
+			go func() {
+				defer wg.Done()
+
+				found[n], errs[n] = users.GetUserByID(ctx, u.ID())
+			}()
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		close(repo.gate)
+
+		wg.Wait()
+
+		for n := 0; n < readers; n++ {
+			i.NoErr(errs[n])
+			i.Equal(u.ID(), found[n].ID)
+		}
+
+		i.Equal(int64(1), repo.gets())
+	})
+
+	t.Run("CanceledMissDoesntFailOthers", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		users, repo, _ := newUsers(t)
+
+		u := createUser(t, users)
+
+		repo.gate = make(chan struct{})
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+
+		var (
+			wg               sync.WaitGroup
+			canceledErr, err error
+			found            query.User
+		)
+
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			_, canceledErr = users.GetUserByID(canceledCtx, u.ID())
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+
+		go func() {
+			defer wg.Done()
+
+			found, err = users.GetUserByID(ctx, u.ID())
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		close(repo.gate)
+
+		wg.Wait()
+
+		i.Equal(context.Canceled, canceledErr)
+		i.NoErr(err)
+		i.Equal(u.ID(), found.ID)
+		i.Equal(int64(1), repo.gets())
+	})
+}
+
+// countingRepository counts the reads sent to the repository,
+// blocking them until gate is closed when it is set. The gets
+// fail when their context is done.
+type countingRepository struct {
+	*memory.UserRepository
+
+	gate chan struct{}
+
+	getCalls, findCalls int64
+}
+
+func (r *countingRepository) GetUserByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (query.User, error) {
+	atomic.AddInt64(&r.getCalls, 1)
+
+	if r.gate != nil {
+		<-r.gate
+	}
+
+	if err := ctx.Err(); err != nil {
+		return query.User{}, err
+	}
+
+	return r.UserRepository.GetUserByID(ctx, id)
+}
+
+func (r *countingRepository) FindUsers(
+	ctx context.Context,
+	filter user.Filter,
+) ([]query.User, error) {
+	atomic.AddInt64(&r.findCalls, 1)
+
+	return r.UserRepository.FindUsers(ctx, filter)
+}
+
+func (r *countingRepository) gets() int64 {
+	return atomic.LoadInt64(&r.getCalls)
+}
+
+func (r *countingRepository) finds() int64 {
+	return atomic.LoadInt64(&r.findCalls)
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Backend = (*LRU)(nil)
+
+// LRU is an in-process Backend that evicts the least recently
+// used values once it holds more than its size.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	entries *list.List
+	index   map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU creates a new LRU holding at most size values.
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		entries: list.New(),
+		index:   make(map[string]*list.Element),
+	}
+}
+
+// Get satisfies the Backend interface.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := el.Value.(*lruEntry)
+
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		c.remove(el)
+
+		return nil, false, nil
+	}
+
+	c.entries.MoveToFront(el)
+
+	return e.value, true, nil
+}
+
+// Set satisfies the Backend interface.
+func (c *LRU) Set(
+	_ context.Context,
+	key string,
+	value []byte,
+	ttl time.Duration,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := c.index[key]; ok {
+		e := el.Value.(*lruEntry)
+
+		e.value = value
+		e.expiresAt = expiresAt
+
+		c.entries.MoveToFront(el)
+
+		return nil
+	}
+
+	c.index[key] = c.entries.PushFront(&lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+	}
+
+	return nil
+}
+
+// Delete satisfies the Backend interface.
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.index[key]; ok {
+			c.remove(el)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of values held, including the expired
+// ones not evicted yet.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.entries.Remove(el)
+
+	delete(c.index, el.Value.(*lruEntry).key)
+}
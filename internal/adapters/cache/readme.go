@@ -0,0 +1,3 @@
+// Package cache implements a cache-aside layer in front of the
+// read models, backed either by an in-process LRU or by Redis.
+package cache
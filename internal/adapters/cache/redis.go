@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var _ Backend = (*Redis)(nil)
+
+// Redis is a Backend storing the values in a server
+// speaking the Redis protocol.
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis creates a new Redis Backend using the given client.
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+// Get satisfies the Backend interface.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.WithContext(ctx).Get(key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("redis get: %w", err)
+	}
+
+	return value, true, nil
+}
+
+// Set satisfies the Backend interface.
+func (r *Redis) Set(
+	ctx context.Context,
+	key string,
+	value []byte,
+	ttl time.Duration,
+) error {
+	err := r.client.WithContext(ctx).Set(key, value, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("redis set: %w", err)
+	}
+
+	return nil
+}
+
+// Delete satisfies the Backend interface.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	err := r.client.WithContext(ctx).Del(keys...).Err()
+	if err != nil {
+		return fmt.Errorf("redis del: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"go.uber.org/zap"
+)
+
+var _ command.UnitOfWork = (*UnitOfWork)(nil)
+
+// UnitOfWork invalidates the users changed through a
+// command.UnitOfWork, which bypasses the Users decorator.
+//
+// The users are invalidated once the unit of work succeeds, so the
+// values cached while it runs, from the committed data, are not kept.
+type UnitOfWork struct {
+	uow         command.UnitOfWork
+	invalidator *UserInvalidator
+}
+
+// NewUnitOfWork creates a new UnitOfWork removing from backend
+// the users changed through uow.
+func NewUnitOfWork(
+	logger *zap.Logger,
+	backend Backend,
+	uow command.UnitOfWork,
+) *UnitOfWork {
+	return &UnitOfWork{
+		uow:         uow,
+		invalidator: NewUserInvalidator(logger, backend),
+	}
+}
+
+// Do satisfies the command.UnitOfWork interface.
+func (u *UnitOfWork) Do(
+	ctx context.Context,
+	fn func(ctx context.Context, repos command.Repositories) error,
+) error {
+	var changed []uuid.UUID
+
+	err := u.uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+		// The function may be retried, only the users changed by
+		// the attempt which succeeds are invalidated.
+		changed = changed[:0]
+
+		repos.Users = changedUsers{
+			Repository: repos.Users,
+			changed:    &changed,
+		}
+
+		return fn(ctx, repos)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range changed {
+		u.invalidator.Invalidate(ctx, id)
+	}
+
+	return nil
+}
+
+// changedUsers records the ids of the users changed
+// through a user.Repository.
+type changedUsers struct {
+	user.Repository
+	changed *[]uuid.UUID
+}
+
+// CreateUser satisfies the user.Repository interface.
+func (r changedUsers) CreateUser(ctx context.Context, u *user.User) error {
+	err := r.Repository.CreateUser(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	*r.changed = append(*r.changed, u.ID())
+
+	return nil
+}
+
+// UpdateUser satisfies the user.Repository interface.
+func (r changedUsers) UpdateUser(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, u *user.User) (*user.User, error),
+) error {
+	err := r.Repository.UpdateUser(ctx, id, updateFn)
+	if err != nil {
+		return err
+	}
+
+	*r.changed = append(*r.changed, id)
+
+	return nil
+}
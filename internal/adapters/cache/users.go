@@ -0,0 +1,449 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	_ UserRepository        = (*Users)(nil)
+	_ outbox.EventPublisher = (*UserInvalidator)(nil)
+)
+
+// usersGenerationKey stores the generation of the cached FindUsers
+// results. It is replaced on every user change so the results
+// cached before it are no longer read.
+const usersGenerationKey = "users:generation"
+
+// UserRepository is the user repository decorated by Users.
+type UserRepository interface {
+	user.Repository
+	user.Importer
+	query.UserByIDReadModel
+	query.FindUsersReadModel
+	query.UsersPageReadModel
+}
+
+// Users caches the user read models of a UserRepository.
+//
+// The cached users are invalidated when they are changed through
+// Users. Concurrent misses of the same key query the repository
+// only once, with a context detached from the callers so one of
+// them going away doesn't fail the others.
+//
+// The users changed by other instances are invalidated by the
+// UserInvalidator of the instance relaying their events, so an
+// in-process Backend, not shared, may serve them stale until
+// they expire, up to the ttl.
+//
+// A failing Backend is logged and bypassed.
+type Users struct {
+	logger      *zap.Logger
+	backend     Backend
+	repo        UserRepository
+	invalidator *UserInvalidator
+	options     usersOptions
+	group       singleflight.Group
+}
+
+// NewUsers creates a new Users caching the reads of repo in backend.
+func NewUsers(
+	logger *zap.Logger,
+	backend Backend,
+	repo UserRepository,
+	opts ...UsersOption,
+) *Users {
+	options := defaultUsersOptions()
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	logger = logger.Named("cache.users")
+
+	return &Users{
+		logger:      logger,
+		backend:     backend,
+		repo:        repo,
+		invalidator: &UserInvalidator{logger: logger, backend: backend},
+		options:     options,
+	}
+}
+
+// CreateUser satisfies the user.Repository interface.
+func (c *Users) CreateUser(ctx context.Context, u *user.User) error {
+	err := c.repo.CreateUser(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	c.invalidator.Invalidate(ctx, u.ID())
+
+	return nil
+}
+
+// UpdateUser satisfies the user.Repository interface.
+func (c *Users) UpdateUser(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, u *user.User) (*user.User, error),
+) error {
+	err := c.repo.UpdateUser(ctx, id, updateFn)
+	if err != nil {
+		return err
+	}
+
+	c.invalidator.Invalidate(ctx, id)
+
+	return nil
+}
+
+// ImportUsers satisfies the user.Importer interface.
+func (c *Users) ImportUsers(
+	ctx context.Context,
+	users []*user.User,
+	mode user.ImportMode,
+) ([]user.ImportResult, error) {
+	results, err := c.repo.ImportUsers(ctx, users, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, res := range results {
+		if res.Status == user.ImportStatusCreated ||
+			res.Status == user.ImportStatusUpdated {
+			c.invalidator.Invalidate(ctx, users[i].ID())
+		}
+	}
+
+	return results, nil
+}
+
+// GetUserByID satisfies the query.UserByIDReadModel interface.
+func (c *Users) GetUserByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (query.User, error) {
+	var u query.User
+
+	err := c.load(
+		ctx,
+		userKey(id),
+		&u,
+		func(ctx context.Context) (any, error) {
+			return c.repo.GetUserByID(ctx, id)
+		},
+	)
+	if err != nil {
+		return query.User{}, err
+	}
+
+	return u, nil
+}
+
+// FindUsers satisfies the query.FindUsersReadModel interface.
+func (c *Users) FindUsers(
+	ctx context.Context,
+	filter user.Filter,
+) ([]query.User, error) {
+	generation, err := usersGeneration(ctx, c.backend)
+	if err != nil {
+		c.logger.Warn("get users generation", zap.Error(err))
+
+		return c.repo.FindUsers(ctx, filter)
+	}
+
+	var (
+		key   = findUsersKey(generation, filter)
+		users []query.User
+	)
+
+	err = c.load(
+		ctx,
+		key,
+		&users,
+		func(ctx context.Context) (any, error) {
+			return c.repo.FindUsers(ctx, filter)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// UsersPage satisfies the query.UsersPageReadModel interface.
+//
+// The pages are not cached, they are only read by bulk exports.
+func (c *Users) UsersPage(
+	ctx context.Context,
+	after uuid.UUID,
+	limit int,
+) ([]query.User, error) {
+	return c.repo.UsersPage(ctx, after, limit)
+}
+
+// load reads the value stored under key into dst. On a miss the
+// value is loaded by the load function and stored.
+//
+// The load function gets a context carrying the values of ctx but
+// bounded by the load timeout instead of its deadline, it is shared
+// by the concurrent misses.
+func (c *Users) load(
+	ctx context.Context,
+	key string,
+	dst any,
+	load func(ctx context.Context) (any, error),
+) error {
+	cached, found, err := c.backend.Get(ctx, key)
+	if err != nil {
+		c.logger.Warn("cache get", zap.String("key", key), zap.Error(err))
+	}
+
+	if found {
+		err = json.Unmarshal(cached, dst)
+		if err == nil {
+			return nil
+		}
+
+		c.logger.Warn(
+			"unmarshal cached value",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+	}
+
+	ch := c.group.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(
+			detachedContext{parent: ctx},
+			c.options.loadTimeout,
+		)
+		defer cancel()
+
+		v, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("marshal: %w", err)
+		}
+
+		err = c.backend.Set(ctx, key, b, c.options.ttl)
+		if err != nil {
+			c.logger.Warn("cache set", zap.String("key", key), zap.Error(err))
+		}
+
+		return b, nil
+	})
+
+	var res singleflight.Result
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case res = <-ch:
+	}
+
+	if res.Err != nil {
+		return res.Err
+	}
+
+	err = json.Unmarshal(res.Val.([]byte), dst)
+	if err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return nil
+}
+
+// usersGeneration returns the generation of the cached FindUsers
+// results, starting a new one when there is none.
+func usersGeneration(ctx context.Context, backend Backend) (string, error) {
+	generation, found, err := backend.Get(ctx, usersGenerationKey)
+	if err != nil {
+		return "", err
+	}
+
+	if found {
+		return string(generation), nil
+	}
+
+	return newUsersGeneration(ctx, backend)
+}
+
+func newUsersGeneration(ctx context.Context, backend Backend) (string, error) {
+	generation := uuid.New().String()
+
+	err := backend.Set(ctx, usersGenerationKey, []byte(generation), 0)
+	if err != nil {
+		return "", err
+	}
+
+	return generation, nil
+}
+
+// UserInvalidator removes users from the cache.
+//
+// It satisfies the outbox.EventPublisher interface so the users
+// changed by other instances sharing the Backend are invalidated
+// once their events are relayed.
+type UserInvalidator struct {
+	logger  *zap.Logger
+	backend Backend
+}
+
+// NewUserInvalidator creates a new UserInvalidator removing
+// users from backend.
+func NewUserInvalidator(logger *zap.Logger, backend Backend) *UserInvalidator {
+	return &UserInvalidator{
+		logger:  logger.Named("cache.users"),
+		backend: backend,
+	}
+}
+
+// Publish satisfies the outbox.EventPublisher interface.
+//
+// It invalidates the user the event was recorded by.
+func (i *UserInvalidator) Publish(
+	ctx context.Context,
+	msg outbox.Message,
+) error {
+	if msg.AggregateType != user.AggregateType {
+		return nil
+	}
+
+	id, err := uuid.Parse(msg.AggregateID)
+	if err != nil {
+		return fmt.Errorf("parse user id: %w", err)
+	}
+
+	i.Invalidate(ctx, id)
+
+	return nil
+}
+
+// Invalidate removes the cached user and all the cached
+// FindUsers results, as any of them may contain the user.
+//
+// Failures are logged, the values expire with their ttl.
+func (i *UserInvalidator) Invalidate(ctx context.Context, id uuid.UUID) {
+	err := i.backend.Delete(ctx, userKey(id))
+	if err != nil {
+		i.logger.Warn(
+			"invalidate user",
+			zap.Stringer("id", id),
+			zap.Error(err),
+		)
+	}
+
+	_, err = newUsersGeneration(ctx, i.backend)
+	if err != nil {
+		i.logger.Warn("invalidate users", zap.Error(err))
+	}
+}
+
+func userKey(id uuid.UUID) string {
+	return "user:" + id.String()
+}
+
+func findUsersKey(generation string, filter user.Filter) string {
+	key := "users:" + generation + ":" + filter.ID.String()
+
+	if filter.Email != nil {
+		key += ":" + strconv.Quote(*filter.Email)
+	}
+
+	return key
+}
+
+// detachedContext carries the values of its parent
+// but neither its deadline nor its cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
+
+type usersOptions struct {
+	ttl,
+	loadTimeout time.Duration
+}
+
+func defaultUsersOptions() usersOptions {
+	const (
+		ttl         = time.Minute
+		loadTimeout = 5 * time.Second
+	)
+
+	return usersOptions{
+		ttl:         ttl,
+		loadTimeout: loadTimeout,
+	}
+}
+
+// UsersOption configures Users.
+type UsersOption interface {
+	apply(*usersOptions)
+}
+
+type funcUsersOption struct {
+	f func(*usersOptions)
+}
+
+func (f *funcUsersOption) apply(o *usersOptions) {
+	f.f(o)
+}
+
+func newFuncUsersOption(f func(*usersOptions)) *funcUsersOption {
+	return &funcUsersOption{
+		f: f,
+	}
+}
+
+// WithTTL configures for how long the values are cached.
+// Default 1m.
+func WithTTL(d time.Duration) UsersOption {
+	return newFuncUsersOption(func(o *usersOptions) {
+		if d > 0 {
+			o.ttl = d
+		}
+	})
+}
+
+// WithLoadTimeout configures how long the repository is queried
+// for a missing value before giving up. Default 5s.
+func WithLoadTimeout(d time.Duration) UsersOption {
+	return newFuncUsersOption(func(o *usersOptions) {
+		if d > 0 {
+			o.loadTimeout = d
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Record represents a stored idempotency key.
+type Record struct {
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+	// Response is the marshaled response of the request,
+	// nil while the request is in progress.
+	Response []byte
+}
+
+// Reservation represents the reservation of an idempotency key.
+type Reservation struct {
+	Key         string
+	Fingerprint string
+	// ExpiresAt is when the key can be used for another request.
+	ExpiresAt time.Time
+	// StaleBefore is the time before which a reservation still in
+	// progress is considered abandoned and can be taken over.
+	StaleBefore time.Time
+}
+
+// Store defines how the idempotency keys are persisted.
+type Store interface {
+	// Reserve reserves the key for the request with the given
+	// fingerprint, it replaces the expired and the abandoned
+	// reservations of the key.
+	// It returns false and the existing record when the key is
+	// already reserved.
+	Reserve(ctx context.Context, r Reservation) (Record, bool, error)
+
+	// Complete stores the response of the request the key was
+	// reserved for.
+	Complete(ctx context.Context, key string, response []byte) error
+
+	// Release removes the reservation of the key, so the
+	// request can be retried.
+	Release(ctx context.Context, key string) error
+}
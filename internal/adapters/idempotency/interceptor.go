@@ -0,0 +1,274 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Metadata keys used by the Interceptor.
+const (
+	// MetadataKey carries the idempotency key of the request.
+	MetadataKey = "idempotency-key"
+	// ReplayedMetadataKey is set to true on the replayed responses.
+	ReplayedMetadataKey = "idempotent-replayed"
+)
+
+// maxKeyLength is the maximum length of an idempotency key.
+const maxKeyLength = 255
+
+// Interceptor makes the unary RPCs idempotent for the requests
+// carrying an idempotency key.
+//
+// The first request with a key runs and its response is stored,
+// the following ones with the same key get the stored response.
+// A key reused for a different request fails with AlreadyExists
+// and one used while its request is still running fails with
+// Aborted. The key is released when the request fails, so it can
+// be retried.
+type Interceptor struct {
+	logger  *zap.Logger
+	store   Store
+	methods map[string]struct{}
+	options interceptorOptions
+}
+
+// NewInterceptor creates a new Interceptor for the RPCs with the
+// given full method names, the other ones are let through.
+func NewInterceptor(
+	logger *zap.Logger,
+	store Store,
+	methods []string,
+	opts ...InterceptorOption,
+) *Interceptor {
+	options := defaultInterceptorOptions()
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	set := make(map[string]struct{}, len(methods))
+
+	for _, m := range methods {
+		set[m] = struct{}{}
+	}
+
+	return &Interceptor{
+		logger:  logger.Named("idempotency.interceptor"),
+		store:   store,
+		methods: set,
+		options: options,
+	}
+}
+
+// Unary returns the grpc.UnaryServerInterceptor.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return i.intercept
+}
+
+func (i *Interceptor) intercept(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if _, ok := i.methods[info.FullMethod]; !ok {
+		return handler(ctx, req)
+	}
+
+	key, ok := keyFromContext(ctx)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	if len(key) > maxKeyLength {
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"idempotency key longer than %d characters",
+			maxKeyLength,
+		)
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	fingerprint, err := requestFingerprint(info.FullMethod, msg)
+	if err != nil {
+		i.logger.Error("fingerprint request", zap.Error(err))
+
+		return nil, status.Error(codes.Internal, "internal error.")
+	}
+
+	key = i.storeKey(ctx, key)
+	now := time.Now()
+
+	rec, reserved, err := i.store.Reserve(ctx, Reservation{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(i.options.ttl),
+		StaleBefore: now.Add(-i.options.lockTimeout),
+	})
+	if err != nil {
+		i.logger.Error("reserve idempotency key", zap.Error(err))
+
+		return nil, status.Error(
+			codes.Unavailable,
+			"idempotency keys unavailable",
+		)
+	}
+
+	if !reserved {
+		return i.replay(ctx, rec, fingerprint)
+	}
+
+	res, err := handler(ctx, req)
+	if err != nil {
+		i.release(key)
+
+		return nil, err
+	}
+
+	i.complete(key, res)
+
+	return res, nil
+}
+
+// replay returns the stored response of the record.
+func (i *Interceptor) replay(
+	ctx context.Context,
+	rec Record,
+	fingerprint string,
+) (any, error) {
+	if rec.Fingerprint != fingerprint {
+		return nil, status.Error(
+			codes.AlreadyExists,
+			"idempotency key used for a different request",
+		)
+	}
+
+	if rec.Response == nil {
+		return nil, status.Error(
+			codes.Aborted,
+			"request with the same idempotency key in progress",
+		)
+	}
+
+	var stored anypb.Any
+
+	err := proto.Unmarshal(rec.Response, &stored)
+	if err != nil {
+		i.logger.Error("unmarshal stored response", zap.Error(err))
+
+		return nil, status.Error(codes.Internal, "internal error.")
+	}
+
+	res, err := stored.UnmarshalNew()
+	if err != nil {
+		i.logger.Error("unmarshal stored response", zap.Error(err))
+
+		return nil, status.Error(codes.Internal, "internal error.")
+	}
+
+	// It only fails when ctx doesn't belong to a server stream.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(ReplayedMetadataKey, "true"))
+
+	return res, nil
+}
+
+// complete stores the response of the request.
+//
+// It doesn't use the context of the request, the response has to
+// be stored even if the client went away, so its retry replays it.
+func (i *Interceptor) complete(key string, res any) {
+	msg, ok := res.(proto.Message)
+	if !ok {
+		i.release(key)
+
+		return
+	}
+
+	response, err := marshalResponse(msg)
+	if err == nil {
+		err = i.store.Complete(context.Background(), key, response)
+	}
+
+	if err != nil {
+		i.logger.Error("complete idempotency key", zap.Error(err))
+
+		i.release(key)
+	}
+}
+
+func (i *Interceptor) release(key string) {
+	err := i.store.Release(context.Background(), key)
+	if err != nil {
+		i.logger.Error("release idempotency key", zap.Error(err))
+	}
+}
+
+// storeKey scopes the key to the client making the request.
+func (i *Interceptor) storeKey(ctx context.Context, key string) string {
+	var scope string
+
+	if i.options.scope != nil {
+		scope = i.options.scope(ctx)
+	}
+
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func keyFromContext(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(MetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// requestFingerprint identifies the request by its method and fields.
+func requestFingerprint(method string, req proto.Message) (string, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	h := sha256.New()
+
+	_, _ = h.Write([]byte(method + "\x00"))
+	_, _ = h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// marshalResponse marshals the response along with its type,
+// so it can be unmarshaled without knowing it.
+func marshalResponse(res proto.Message) ([]byte, error) {
+	stored, err := anypb.New(res)
+	if err != nil {
+		return nil, fmt.Errorf("new any: %w", err)
+	}
+
+	response, err := proto.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("marshal any: %w", err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,199 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const method = "/test.Service/Create"
+
+func TestInterceptor(t *testing.T) {
+	t.Parallel()
+
+	newInterceptor := func(store idempotency.Store) grpc.UnaryServerInterceptor {
+		return idempotency.NewInterceptor(
+			zap.NewNop(),
+			store,
+			[]string{method},
+		).Unary()
+	}
+
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(
+			context.Background(),
+			metadata.Pairs(idempotency.MetadataKey, key),
+		)
+	}
+
+	// counter returns a handler answering with the number of times
+	// it was called.
+	counter := func() (grpc.UnaryHandler, *int) {
+		var calls int
+
+		return func(context.Context, any) (any, error) {
+			calls++
+
+			return wrapperspb.Int64(int64(calls)), nil
+		}, &calls
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	t.Run("ReplaysResponse", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		intercept := newInterceptor(idempotency.NewMemory())
+		handler, calls := counter()
+		req := wrapperspb.String("a")
+
+		first, err := intercept(withKey("k"), req, info, handler)
+		i.NoErr(err)
+
+		second, err := intercept(withKey("k"), req, info, handler)
+		i.NoErr(err)
+
+		i.Equal(1, *calls)
+		i.True(proto.Equal(first.(proto.Message), second.(proto.Message)))
+
+		_, err = intercept(withKey("other"), req, info, handler)
+		i.NoErr(err)
+		i.Equal(2, *calls)
+	})
+
+	t.Run("ConflictsOnDifferentRequest", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		intercept := newInterceptor(idempotency.NewMemory())
+		handler, calls := counter()
+
+		_, err := intercept(withKey("k"), wrapperspb.String("a"), info, handler)
+		i.NoErr(err)
+
+		_, err = intercept(withKey("k"), wrapperspb.String("b"), info, handler)
+		i.Equal(codes.AlreadyExists, status.Code(err))
+		i.Equal(1, *calls)
+	})
+
+	t.Run("AbortsWhileInProgress", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		intercept := newInterceptor(idempotency.NewMemory())
+		req := wrapperspb.String("a")
+
+		var inner error
+
+		_, err := intercept(
+			withKey("k"),
+			req,
+			info,
+			func(ctx context.Context, req any) (any, error) {
+				_, inner = intercept(withKey("k"), req, info, nil)
+
+				return wrapperspb.Int64(1), nil
+			},
+		)
+		i.NoErr(err)
+		i.Equal(codes.Aborted, status.Code(inner))
+	})
+
+	t.Run("ReleasesOnError", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		intercept := newInterceptor(idempotency.NewMemory())
+		req := wrapperspb.String("a")
+		errHandler := errors.New("handler")
+
+		_, err := intercept(
+			withKey("k"),
+			req,
+			info,
+			func(context.Context, any) (any, error) {
+				return nil, errHandler
+			},
+		)
+		i.True(errors.Is(err, errHandler))
+
+		handler, calls := counter()
+
+		_, err = intercept(withKey("k"), req, info, handler)
+		i.NoErr(err)
+		i.Equal(1, *calls)
+	})
+
+	t.Run("PassesThrough", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		intercept := newInterceptor(idempotency.NewMemory())
+		handler, calls := counter()
+		req := wrapperspb.String("a")
+
+		_, err := intercept(context.Background(), req, info, handler)
+		i.NoErr(err)
+
+		_, err = intercept(context.Background(), req, info, handler)
+		i.NoErr(err)
+
+		other := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+
+		_, err = intercept(withKey("k"), req, other, handler)
+		i.NoErr(err)
+
+		_, err = intercept(withKey("k"), req, other, handler)
+		i.NoErr(err)
+
+		i.Equal(4, *calls)
+	})
+}
+
+func TestMemoryTakesOverAbandonedReservation(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+	ctx := context.Background()
+	store := idempotency.NewMemory()
+	now := time.Now()
+
+	reservation := idempotency.Reservation{
+		Key:         "k",
+		Fingerprint: "a",
+		ExpiresAt:   now.Add(time.Hour),
+		StaleBefore: now.Add(-time.Minute),
+	}
+
+	_, reserved, err := store.Reserve(ctx, reservation)
+	i.NoErr(err)
+	i.True(reserved)
+
+	rec, reserved, err := store.Reserve(ctx, reservation)
+	i.NoErr(err)
+	i.True(!reserved)
+	i.Equal("a", rec.Fingerprint)
+
+	reservation.StaleBefore = time.Now().Add(time.Second)
+
+	_, reserved, err = store.Reserve(ctx, reservation)
+	i.NoErr(err)
+	i.True(reserved)
+}
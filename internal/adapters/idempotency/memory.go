@@ -0,0 +1,93 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Store = (*Memory)(nil)
+
+// Memory is a Store keeping the idempotency keys in process memory.
+type Memory struct {
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+}
+
+type memoryRecord struct {
+	Record
+
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+// NewMemory creates a new Memory Store.
+func NewMemory() *Memory {
+	return &Memory{
+		records: make(map[string]*memoryRecord),
+	}
+}
+
+// Reserve satisfies the Store interface.
+func (m *Memory) Reserve(
+	_ context.Context,
+	r Reservation,
+) (Record, bool, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, rec := range m.records {
+		if !now.Before(rec.expiresAt) {
+			delete(m.records, key)
+		}
+	}
+
+	rec, ok := m.records[r.Key]
+	if ok && (rec.Response != nil || rec.createdAt.After(r.StaleBefore)) {
+		return copyRecord(rec.Record), false, nil
+	}
+
+	m.records[r.Key] = &memoryRecord{
+		Record:    Record{Fingerprint: r.Fingerprint},
+		createdAt: now,
+		expiresAt: r.ExpiresAt,
+	}
+
+	return Record{}, true, nil
+}
+
+// Complete satisfies the Store interface.
+func (m *Memory) Complete(
+	_ context.Context,
+	key string,
+	response []byte,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rec, ok := m.records[key]; ok {
+		rec.Response = append([]byte{}, response...)
+	}
+
+	return nil
+}
+
+// Release satisfies the Store interface.
+func (m *Memory) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+
+	return nil
+}
+
+func copyRecord(r Record) Record {
+	if r.Response != nil {
+		r.Response = append([]byte{}, r.Response...)
+	}
+
+	return r
+}
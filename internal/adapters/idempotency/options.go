@@ -0,0 +1,76 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+type interceptorOptions struct {
+	ttl         time.Duration
+	lockTimeout time.Duration
+	scope       func(ctx context.Context) string
+}
+
+func defaultInterceptorOptions() interceptorOptions {
+	const (
+		ttl         = 24 * time.Hour
+		lockTimeout = time.Minute
+	)
+
+	return interceptorOptions{
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+		scope:       nil,
+	}
+}
+
+// InterceptorOption configures the Interceptor.
+type InterceptorOption interface {
+	apply(*interceptorOptions)
+}
+
+type funcInterceptorOption struct {
+	f func(*interceptorOptions)
+}
+
+func (f *funcInterceptorOption) apply(o *interceptorOptions) {
+	f.f(o)
+}
+
+func newFuncInterceptorOption(
+	f func(*interceptorOptions),
+) *funcInterceptorOption {
+	return &funcInterceptorOption{
+		f: f,
+	}
+}
+
+// WithTTL configures for how long the responses are replayed.
+// Default 24h.
+func WithTTL(d time.Duration) InterceptorOption {
+	return newFuncInterceptorOption(func(o *interceptorOptions) {
+		if d > 0 {
+			o.ttl = d
+		}
+	})
+}
+
+// WithLockTimeout configures after how long a request still in
+// progress is considered abandoned, so its key can be used again.
+// Default 1m.
+func WithLockTimeout(d time.Duration) InterceptorOption {
+	return newFuncInterceptorOption(func(o *interceptorOptions) {
+		if d > 0 {
+			o.lockTimeout = d
+		}
+	})
+}
+
+// WithScope configures the function identifying the client making
+// the request, the same key used by different clients doesn't
+// collide. By default the keys are shared by every client.
+func WithScope(scope func(ctx context.Context) string) InterceptorOption {
+	return newFuncInterceptorOption(func(o *interceptorOptions) {
+		o.scope = scope
+	})
+}
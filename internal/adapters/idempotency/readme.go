@@ -0,0 +1,4 @@
+// Package idempotency implements a gRPC interceptor that replays the
+// response of a mutating RPC when a client retries it with the same
+// idempotency key, instead of running it again.
+package idempotency
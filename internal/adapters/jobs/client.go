@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// Client enqueues jobs.
+type Client struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewClient creates a new Client.
+func NewClient(store Store) *Client {
+	if store == nil {
+		panic(errors.NewInvalidError("nil job store"))
+	}
+
+	return &Client{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Enqueue adds a job with the given args to the queue.
+func (c *Client) Enqueue(
+	ctx context.Context,
+	args Args,
+	opt ...EnqueueOption,
+) error {
+	opts := defaultEnqueueOptions()
+
+	for _, o := range opt {
+		o.apply(&opts)
+	}
+
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("marshal args: %w", err)
+	}
+
+	runAt := opts.runAt
+	if runAt.IsZero() {
+		runAt = c.now().Add(opts.delay)
+	}
+
+	err = c.store.Insert(ctx, NewJob{
+		Kind:        args.Kind(),
+		Payload:     payload,
+		RunAt:       runAt,
+		MaxAttempts: opts.maxAttempts,
+		UniqueKey:   opts.uniqueKey,
+	})
+	if err != nil {
+		return fmt.Errorf("insert job: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// Args represents the arguments of a job, their type
+// identifies the handler that runs the job.
+type Args interface {
+	// Kind returns the unique name of the job type.
+	Kind() string
+}
+
+// Job represents a job stored in the queue.
+type Job struct {
+	ID      int64
+	Kind    string
+	Payload []byte
+	// Attempts is the number of times the job was claimed,
+	// including the current run.
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	CreatedAt   time.Time
+}
+
+// NewJob represents a job to be inserted into the queue.
+type NewJob struct {
+	Kind        string
+	Payload     []byte
+	RunAt       time.Time
+	MaxAttempts int
+	// UniqueKey, when not empty, prevents inserting another
+	// job with the same key.
+	UniqueKey string
+}
+
+// Store defines how jobs are persisted.
+type Store interface {
+	// Insert adds a job to the queue. Jobs with a UniqueKey already
+	// present in the queue are silently ignored.
+	Insert(ctx context.Context, job NewJob) error
+
+	// Claim returns up to limit jobs of the given kinds that are due
+	// to run, oldest first. The returned jobs are hidden from other
+	// calls until lease passes.
+	Claim(
+		ctx context.Context,
+		kinds []string,
+		limit int,
+		lease time.Duration,
+	) ([]Job, error)
+
+	// MarkSucceeded flags the job as finished.
+	MarkSucceeded(ctx context.Context, id int64) error
+
+	// MarkFailed records a failed run and schedules the job
+	// to run again at the given time.
+	MarkFailed(
+		ctx context.Context,
+		id int64,
+		retryAt time.Time,
+		cause error,
+	) error
+
+	// MarkDead records a failed run after which the job
+	// is not retried anymore.
+	MarkDead(ctx context.Context, id int64, cause error) error
+}
+
+// Lock represents an acquired lock.
+type Lock interface {
+	// Check returns an error if the lock was lost, the lock
+	// is then given up and must not be released.
+	Check(ctx context.Context) error
+
+	// Release gives up the lock.
+	Release(ctx context.Context) error
+}
+
+// Locker is used to elect the Worker that enqueues the periodic jobs.
+type Locker interface {
+	// TryLock acquires the lock identified by key without waiting.
+	// It returns false if the lock is held by someone else.
+	TryLock(ctx context.Context, key int64) (Lock, bool, error)
+}
@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"time"
+)
+
+type enqueueOptions struct {
+	runAt       time.Time
+	delay       time.Duration
+	maxAttempts int
+	uniqueKey   string
+}
+
+func defaultEnqueueOptions() enqueueOptions {
+	const maxAttempts = 10
+
+	return enqueueOptions{
+		maxAttempts: maxAttempts,
+	}
+}
+
+// EnqueueOption configures an enqueued job.
+type EnqueueOption interface {
+	apply(*enqueueOptions)
+}
+
+type funcEnqueueOption struct {
+	f func(*enqueueOptions)
+}
+
+func (f *funcEnqueueOption) apply(o *enqueueOptions) {
+	f.f(o)
+}
+
+func newFuncEnqueueOption(f func(*enqueueOptions)) *funcEnqueueOption {
+	return &funcEnqueueOption{
+		f: f,
+	}
+}
+
+// WithRunAt schedules the job to run at the given time.
+func WithRunAt(t time.Time) EnqueueOption {
+	return newFuncEnqueueOption(func(o *enqueueOptions) {
+		o.runAt = t
+	})
+}
+
+// WithDelay schedules the job to run after the given delay.
+// It is ignored when WithRunAt is used.
+func WithDelay(d time.Duration) EnqueueOption {
+	return newFuncEnqueueOption(func(o *enqueueOptions) {
+		o.delay = d
+	})
+}
+
+// WithMaxAttempts configures how many times the job runs before
+// it is given up. Default 10.
+func WithMaxAttempts(n int) EnqueueOption {
+	return newFuncEnqueueOption(func(o *enqueueOptions) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	})
+}
+
+// WithUniqueKey prevents enqueueing the job if another job with the
+// same key is already in the queue.
+func WithUniqueKey(key string) EnqueueOption {
+	return newFuncEnqueueOption(func(o *enqueueOptions) {
+		o.uniqueKey = key
+	})
+}
+
+type workerOptions struct {
+	pollInterval,
+	lease,
+	minBackoff,
+	maxBackoff time.Duration
+	concurrency int
+}
+
+func defaultWorkerOptions() workerOptions {
+	const (
+		pollInterval = time.Second
+		lease        = 5 * time.Minute
+		minBackoff   = 5 * time.Second
+		maxBackoff   = time.Hour
+		concurrency  = 10
+	)
+
+	return workerOptions{
+		pollInterval: pollInterval,
+		lease:        lease,
+		minBackoff:   minBackoff,
+		maxBackoff:   maxBackoff,
+		concurrency:  concurrency,
+	}
+}
+
+// WorkerOption configures the Worker.
+type WorkerOption interface {
+	apply(*workerOptions)
+}
+
+type funcWorkerOption struct {
+	f func(*workerOptions)
+}
+
+func (f *funcWorkerOption) apply(o *workerOptions) {
+	f.f(o)
+}
+
+func newFuncWorkerOption(f func(*workerOptions)) *funcWorkerOption {
+	return &funcWorkerOption{
+		f: f,
+	}
+}
+
+// WithPollInterval configures how often the Worker checks for due
+// jobs and periodic jobs. Default 1s.
+func WithPollInterval(d time.Duration) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	})
+}
+
+// WithLease configures for how long a claimed job is hidden from
+// other workers. A job running longer than the lease may run twice.
+// Default 5m.
+func WithLease(d time.Duration) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if d > 0 {
+			o.lease = d
+		}
+	})
+}
+
+// WithBackoff configures the exponential delay between two runs
+// of a failed job. Default 5s up to 1h.
+func WithBackoff(minBackoff, maxBackoff time.Duration) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if minBackoff > 0 {
+			o.minBackoff = minBackoff
+		}
+
+		if maxBackoff >= o.minBackoff {
+			o.maxBackoff = maxBackoff
+		}
+	})
+}
+
+// WithConcurrency configures how many jobs run at the same time.
+// Default 10.
+func WithConcurrency(n int) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	})
+}
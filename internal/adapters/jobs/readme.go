@@ -0,0 +1,12 @@
+// Package jobs implements a background job queue.
+//
+// Jobs are typed: every job kind is described by an Args type which is
+// stored as JSON and handed back to the handler registered for it.
+// Jobs can be enqueued to run immediately or at a later time, and
+// periodic jobs are enqueued by the single Worker holding the
+// scheduler lock. Failed jobs are retried with an exponential backoff
+// until they run out of attempts.
+//
+// The queue itself is persisted through a Store, see psql.JobStore
+// for the PostgreSQL implementation.
+package jobs
@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// Schedule describes when a periodic job runs.
+//
+// It is satisfied by the schedules of the common cron libraries.
+type Schedule interface {
+	// Next returns the next activation time, later than the given time.
+	Next(time.Time) time.Time
+}
+
+// Every returns a Schedule that activates every d, aligned to
+// multiples of d since the zero time.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic(errors.NewInvalidError("non positive schedule interval"))
+	}
+
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(e)).Add(time.Duration(e))
+}
+
+type handlerFunc func(ctx context.Context, payload []byte) error
+
+type periodicJob struct {
+	name     string
+	schedule Schedule
+	args     Args
+	opts     []EnqueueOption
+}
+
+// Registry holds the job handlers and the periodic jobs.
+type Registry struct {
+	handlers map[string]handlerFunc
+	periodic []periodicJob
+}
+
+// NewRegistry creates a new, empty, Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers: make(map[string]handlerFunc),
+	}
+}
+
+// Register adds the handler of the jobs with args of type A.
+// It panics if a handler is already registered for the same kind.
+func Register[A Args](
+	r *Registry,
+	handler func(ctx context.Context, args A) error,
+) {
+	var zero A
+
+	kind := zero.Kind()
+
+	if _, ok := r.handlers[kind]; ok {
+		panic(errors.NewInvalidError(
+			fmt.Sprintf("job handler %q already registered", kind),
+		))
+	}
+
+	r.handlers[kind] = func(ctx context.Context, payload []byte) error {
+		var args A
+
+		err := json.Unmarshal(payload, &args)
+		if err != nil {
+			return fmt.Errorf("unmarshal args: %w", err)
+		}
+
+		return handler(ctx, args)
+	}
+}
+
+// Periodic enqueues a job with the given args on every activation
+// of the schedule. The name must be unique among the periodic jobs.
+func (r *Registry) Periodic(
+	name string,
+	schedule Schedule,
+	args Args,
+	opt ...EnqueueOption,
+) {
+	for _, p := range r.periodic {
+		if p.name == name {
+			panic(errors.NewInvalidError(
+				fmt.Sprintf("periodic job %q already registered", name),
+			))
+		}
+	}
+
+	r.periodic = append(r.periodic, periodicJob{
+		name:     name,
+		schedule: schedule,
+		args:     args,
+		opts:     opt,
+	})
+}
+
+func (r *Registry) kinds() []string {
+	kinds := make([]string, 0, len(r.handlers))
+
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	return kinds
+}
@@ -0,0 +1,328 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"go.uber.org/zap"
+)
+
+// schedulerLockKey identifies the lock held by the Worker
+// that enqueues the periodic jobs.
+const schedulerLockKey int64 = 0x6a6f62732e736368
+
+// Worker runs the jobs registered in a Registry.
+type Worker struct {
+	logger   *zap.Logger
+	store    Store
+	locker   Locker
+	registry *Registry
+	client   *Client
+	opts     workerOptions
+}
+
+// NewWorker creates a new Worker.
+func NewWorker(
+	logger *zap.Logger,
+	store Store,
+	locker Locker,
+	registry *Registry,
+	opt ...WorkerOption,
+) *Worker {
+	if store == nil {
+		panic(errors.NewInvalidError("nil job store"))
+	}
+
+	if locker == nil {
+		panic(errors.NewInvalidError("nil job locker"))
+	}
+
+	if registry == nil {
+		panic(errors.NewInvalidError("nil job registry"))
+	}
+
+	opts := defaultWorkerOptions()
+
+	for _, o := range opt {
+		o.apply(&opts)
+	}
+
+	return &Worker{
+		logger:   logger.Named("jobs.worker"),
+		store:    store,
+		locker:   locker,
+		registry: registry,
+		client:   NewClient(store),
+		opts:     opts,
+	}
+}
+
+// Run runs the due jobs, and enqueues the periodic ones while
+// holding the scheduler lock, until the context is canceled.
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		w.runScheduler(ctx)
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+
+			return
+
+		case <-timer.C:
+		}
+
+		ran, err := w.RunBatch(ctx)
+		if err != nil {
+			w.logger.Error("run batch", zap.Error(err))
+		}
+
+		wait := w.opts.pollInterval
+		if ran == w.opts.concurrency {
+			wait = 0
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+// RunBatch claims up to concurrency due jobs and runs them
+// concurrently. It returns the number of jobs that were run.
+func (w *Worker) RunBatch(ctx context.Context) (int, error) {
+	kinds := w.registry.kinds()
+	if len(kinds) == 0 {
+		return 0, nil
+	}
+
+	claimed, err := w.store.Claim(
+		ctx,
+		kinds,
+		w.opts.concurrency,
+		w.opts.lease,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("claim: %w", err)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(claimed))
+
+	for _, job := range claimed {
+		go func(job Job) {
+			defer wg.Done()
+
+			err := w.runJob(ctx, job)
+			if err != nil {
+				w.logger.Error(
+					"run job",
+					zap.Int64("job_id", job.ID),
+					zap.String("kind", job.Kind),
+					zap.Error(err),
+				)
+			}
+		}(job)
+	}
+
+	wg.Wait()
+
+	return len(claimed), nil
+}
+
+func (w *Worker) runJob(ctx context.Context, job Job) error {
+	runErr := w.handle(ctx, job)
+	if runErr == nil {
+		err := w.store.MarkSucceeded(ctx, job.ID)
+		if err != nil {
+			return fmt.Errorf("mark succeeded: %w", err)
+		}
+
+		return nil
+	}
+
+	w.logger.Warn(
+		"job failed",
+		zap.Int64("job_id", job.ID),
+		zap.String("kind", job.Kind),
+		zap.Int("attempts", job.Attempts),
+		zap.Error(runErr),
+	)
+
+	if job.Attempts >= job.MaxAttempts {
+		err := w.store.MarkDead(ctx, job.ID, runErr)
+		if err != nil {
+			return fmt.Errorf("mark dead: %w", err)
+		}
+
+		return nil
+	}
+
+	err := w.store.MarkFailed(
+		ctx,
+		job.ID,
+		time.Now().Add(w.backoff(job.Attempts)),
+		runErr,
+	)
+	if err != nil {
+		return fmt.Errorf("mark failed: %w", err)
+	}
+
+	return nil
+}
+
+// handle runs the job handler, turning a panic into an error.
+func (w *Worker) handle(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	handler, ok := w.registry.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for %q", job.Kind)
+	}
+
+	return handler(ctx, job.Payload)
+}
+
+// backoff returns the delay before the next run of a job,
+// doubling it for every previous failed run.
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.opts.minBackoff
+
+	for i := 1; i < attempts && d < w.opts.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > w.opts.maxBackoff {
+		d = w.opts.maxBackoff
+	}
+
+	return d
+}
+
+// runScheduler competes for the scheduler lock and, while holding it,
+// enqueues the periodic jobs on their schedule.
+func (w *Worker) runScheduler(ctx context.Context) {
+	if len(w.registry.periodic) == 0 {
+		return
+	}
+
+	var (
+		lock   Lock
+		ticker = time.NewTicker(w.opts.pollInterval)
+		last   = make(map[string]time.Time)
+	)
+
+	defer ticker.Stop()
+
+	defer func() {
+		if lock == nil {
+			return
+		}
+
+		// The context is already canceled.
+		err := lock.Release(context.Background())
+		if err != nil {
+			w.logger.Error("release scheduler lock", zap.Error(err))
+		}
+	}()
+
+	for ctx.Err() == nil {
+		if lock != nil {
+			err := lock.Check(ctx)
+			if err != nil {
+				w.logger.Warn("lost scheduler lock", zap.Error(err))
+
+				lock = nil
+			}
+		}
+
+		if lock == nil {
+			l, acquired, err := w.locker.TryLock(ctx, schedulerLockKey)
+			if err != nil {
+				w.logger.Error("acquire scheduler lock", zap.Error(err))
+			}
+
+			if acquired {
+				w.logger.Info("acquired scheduler lock")
+
+				lock = l
+
+				now := time.Now()
+
+				for _, p := range w.registry.periodic {
+					last[p.name] = now
+				}
+			}
+		}
+
+		if lock != nil {
+			w.enqueuePeriodic(ctx, last, time.Now())
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueuePeriodic enqueues the periodic jobs activated since the
+// last call. Missed activations are collapsed into the latest one.
+func (w *Worker) enqueuePeriodic(
+	ctx context.Context,
+	last map[string]time.Time,
+	now time.Time,
+) {
+	for _, p := range w.registry.periodic {
+		next := p.schedule.Next(last[p.name])
+		if next.After(now) {
+			continue
+		}
+
+		for n := p.schedule.Next(next); !n.After(now); n = p.schedule.Next(n) {
+			next = n
+		}
+
+		// The unique key keeps two workers that briefly believe they
+		// hold the lock from enqueueing the same activation twice.
+		opts := append(
+			append([]EnqueueOption(nil), p.opts...),
+			WithRunAt(next),
+			WithUniqueKey(fmt.Sprintf(
+				"periodic:%s:%s",
+				p.name,
+				next.UTC().Format(time.RFC3339Nano),
+			)),
+		)
+
+		err := w.client.Enqueue(ctx, p.args, opts...)
+		if err != nil {
+			w.logger.Error(
+				"enqueue periodic job",
+				zap.String("name", p.name),
+				zap.Error(err),
+			)
+
+			continue
+		}
+
+		last[p.name] = next
+	}
+}
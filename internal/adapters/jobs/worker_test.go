@@ -0,0 +1,450 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/jobs"
+	"go.uber.org/zap"
+)
+
+var errSend = errors.New("send")
+
+type sendEmail struct {
+	To string `json:"to"`
+}
+
+func (sendEmail) Kind() string {
+	return "email.send"
+}
+
+func TestWorker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("RunsTypedHandlerAndRetries", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		var (
+			store    = newStore()
+			registry = jobs.NewRegistry()
+			sent     []string
+			failures = 1
+		)
+
+		jobs.Register(registry, func(_ context.Context, args sendEmail) error {
+			if failures > 0 {
+				failures--
+
+				return errSend
+			}
+
+			sent = append(sent, args.To)
+
+			return nil
+		})
+
+		worker := jobs.NewWorker(
+			zap.NewNop(),
+			store,
+			newLocker(),
+			registry,
+			jobs.WithBackoff(time.Hour, time.Hour),
+		)
+
+		err := jobs.NewClient(store).Enqueue(ctx, sendEmail{To: "user@email.com"})
+		i.NoErr(err)
+
+		ran, err := worker.RunBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, ran)
+		i.Equal(0, len(sent))
+
+		job := store.get(1)
+		i.Equal("pending", job.status)
+		i.Equal(errSend.Error(), job.lastError)
+		i.True(job.RunAt.After(time.Now().Add(time.Minute)))
+
+		// The failed job waits for its retry.
+		ran, err = worker.RunBatch(ctx)
+		i.NoErr(err)
+		i.Equal(0, ran)
+
+		store.makeDue(1)
+
+		ran, err = worker.RunBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, ran)
+
+		i.Equal([]string{"user@email.com"}, sent)
+		i.Equal("succeeded", store.get(1).status)
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		var (
+			store    = newStore()
+			registry = jobs.NewRegistry()
+		)
+
+		jobs.Register(registry, func(context.Context, sendEmail) error {
+			panic("boom")
+		})
+
+		worker := jobs.NewWorker(zap.NewNop(), store, newLocker(), registry)
+
+		err := jobs.NewClient(store).Enqueue(
+			ctx,
+			sendEmail{},
+			jobs.WithMaxAttempts(2),
+		)
+		i.NoErr(err)
+
+		for attempt := 0; attempt < 2; attempt++ {
+			store.makeDue(1)
+
+			_, err := worker.RunBatch(ctx)
+			i.NoErr(err)
+		}
+
+		job := store.get(1)
+		i.Equal("dead", job.status)
+		i.Equal(2, job.Attempts)
+		i.Equal("panic: boom", job.lastError)
+	})
+
+	t.Run("DelaysJobs", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		var (
+			store    = newStore()
+			registry = jobs.NewRegistry()
+		)
+
+		jobs.Register(registry, func(context.Context, sendEmail) error {
+			return nil
+		})
+
+		worker := jobs.NewWorker(zap.NewNop(), store, newLocker(), registry)
+
+		err := jobs.NewClient(store).Enqueue(
+			ctx,
+			sendEmail{},
+			jobs.WithDelay(time.Hour),
+		)
+		i.NoErr(err)
+
+		ran, err := worker.RunBatch(ctx)
+		i.NoErr(err)
+		i.Equal(0, ran)
+	})
+
+	t.Run("OnlyLeaderEnqueuesPeriodicJobs", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		var (
+			store  = newStore()
+			locker = newLocker()
+		)
+
+		newWorker := func() *jobs.Worker {
+			registry := jobs.NewRegistry()
+
+			jobs.Register(registry, func(context.Context, sendEmail) error {
+				return nil
+			})
+
+			registry.Periodic(
+				"digest",
+				jobs.Every(20*time.Millisecond),
+				sendEmail{To: "digest@email.com"},
+			)
+
+			return jobs.NewWorker(
+				zap.NewNop(),
+				store,
+				locker,
+				registry,
+				jobs.WithPollInterval(5*time.Millisecond),
+			)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		var wg sync.WaitGroup
+
+		for w := 0; w < 2; w++ {
+			worker := newWorker()
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				worker.Run(ctx)
+			}()
+		}
+
+		wg.Wait()
+
+		i.Equal(1, locker.acquired())
+
+		keys := store.uniqueKeys()
+		i.True(len(keys) > 2)
+
+		seen := make(map[string]bool)
+
+		for _, k := range keys {
+			i.True(!seen[k])
+			seen[k] = true
+		}
+
+		// The lock is released when the worker stops.
+		i.Equal(0, locker.held())
+	})
+}
+
+type storedJob struct {
+	jobs.Job
+
+	uniqueKey string
+	status    string
+	lastError string
+}
+
+// store is an in memory jobs.Store with the same claim
+// semantics as the PostgreSQL implementation.
+type store struct {
+	mu     sync.Mutex
+	lastID int64
+	jobs   []*storedJob
+}
+
+func newStore() *store {
+	return &store{}
+}
+
+func (s *store) Insert(_ context.Context, job jobs.NewJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if job.UniqueKey != "" && j.uniqueKey == job.UniqueKey {
+			return nil
+		}
+	}
+
+	s.lastID++
+
+	s.jobs = append(s.jobs, &storedJob{
+		Job: jobs.Job{
+			ID:          s.lastID,
+			Kind:        job.Kind,
+			Payload:     job.Payload,
+			MaxAttempts: job.MaxAttempts,
+			RunAt:       job.RunAt,
+			CreatedAt:   time.Now(),
+		},
+		uniqueKey: job.UniqueKey,
+		status:    "pending",
+	})
+
+	return nil
+}
+
+func (s *store) Claim(
+	_ context.Context,
+	kinds []string,
+	limit int,
+	lease time.Duration,
+) ([]jobs.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		now     = time.Now()
+		claimed []jobs.Job
+	)
+
+	for _, j := range s.jobs {
+		if j.status != "pending" ||
+			j.RunAt.After(now) ||
+			!contains(kinds, j.Kind) ||
+			len(claimed) == limit {
+			continue
+		}
+
+		j.RunAt = now.Add(lease)
+		j.Attempts++
+
+		claimed = append(claimed, j.Job)
+	}
+
+	return claimed, nil
+}
+
+func (s *store) MarkSucceeded(_ context.Context, id int64) error {
+	s.update(id, func(j *storedJob) {
+		j.status = "succeeded"
+	})
+
+	return nil
+}
+
+func (s *store) MarkFailed(
+	_ context.Context,
+	id int64,
+	retryAt time.Time,
+	cause error,
+) error {
+	s.update(id, func(j *storedJob) {
+		j.RunAt = retryAt
+		j.lastError = cause.Error()
+	})
+
+	return nil
+}
+
+func (s *store) MarkDead(_ context.Context, id int64, cause error) error {
+	s.update(id, func(j *storedJob) {
+		j.status = "dead"
+		j.lastError = cause.Error()
+	})
+
+	return nil
+}
+
+func (s *store) update(id int64, fn func(j *storedJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.ID == id {
+			fn(j)
+		}
+	}
+}
+
+func (s *store) get(id int64) storedJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.ID == id {
+			return *j
+		}
+	}
+
+	return storedJob{}
+}
+
+func (s *store) makeDue(id int64) {
+	s.update(id, func(j *storedJob) {
+		j.RunAt = time.Time{}
+	})
+}
+
+func (s *store) uniqueKeys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.jobs))
+
+	for _, j := range s.jobs {
+		keys = append(keys, j.uniqueKey)
+	}
+
+	return keys
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// locker is an in memory jobs.Locker.
+type locker struct {
+	mu          sync.Mutex
+	locks       map[int64]bool
+	acquiredCnt int
+}
+
+func newLocker() *locker {
+	return &locker{
+		locks: make(map[int64]bool),
+	}
+}
+
+func (l *locker) TryLock(_ context.Context, key int64) (jobs.Lock, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.locks[key] {
+		return nil, false, nil
+	}
+
+	l.locks[key] = true
+	l.acquiredCnt++
+
+	return &lock{locker: l, key: key}, true, nil
+}
+
+func (l *locker) acquired() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.acquiredCnt
+}
+
+func (l *locker) held() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	held := 0
+
+	for _, locked := range l.locks {
+		if locked {
+			held++
+		}
+	}
+
+	return held
+}
+
+type lock struct {
+	locker *locker
+	key    int64
+}
+
+func (*lock) Check(context.Context) error {
+	return nil
+}
+
+func (l *lock) Release(context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+
+	l.locker.locks[l.key] = false
+
+	return nil
+}
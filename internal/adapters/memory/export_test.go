@@ -0,0 +1,4 @@
+package memory
+
+// MaxEvents exports maxEvents for the tests.
+const MaxEvents = maxEvents
@@ -0,0 +1,6 @@
+// Package memory implements the repositories in memory.
+//
+// They follow the semantics of the PostgreSQL repositories and are
+// meant to be used in tests and local demos, the data is lost when
+// the process exits.
+package memory
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+)
+
+var _ command.UnitOfWork = (*UnitOfWork)(nil)
+
+// UnitOfWork runs functions against the in memory repositories.
+//
+// There are no transactions in memory, the changes are applied as
+// they are made and are not rolled back when the function fails.
+// There are no webhooks either, the Webhooks repository passed to
+// the functions is nil.
+type UnitOfWork struct {
+	users *UserRepository
+}
+
+// NewUnitOfWork creates a new in memory UnitOfWork.
+func NewUnitOfWork(users *UserRepository) *UnitOfWork {
+	return &UnitOfWork{users: users}
+}
+
+// Do satisfies the command.UnitOfWork interface.
+func (u *UnitOfWork) Do(
+	ctx context.Context,
+	fn func(ctx context.Context, repos command.Repositories) error,
+) error {
+	return fn(ctx, command.Repositories{
+		Users: u.users,
+	})
+}
@@ -0,0 +1,285 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+var (
+	_ user.Repository          = (*UserRepository)(nil)
+	_ user.Importer            = (*UserRepository)(nil)
+	_ query.UserByIDReadModel  = (*UserRepository)(nil)
+	_ query.FindUsersReadModel = (*UserRepository)(nil)
+	_ query.UsersPageReadModel = (*UserRepository)(nil)
+)
+
+// storedUser represents a user as kept in memory.
+type storedUser struct {
+	id      uuid.UUID
+	email   string
+	deleted bool
+	version int64
+}
+
+// maxEvents is the number of events kept by the repository,
+// the older ones are dropped so they don't grow unbounded.
+const maxEvents = 1000
+
+// UserRepository represents an in memory User Repository.
+type UserRepository struct {
+	mu     sync.RWMutex
+	users  []*storedUser
+	events []user.Event
+}
+
+// NewUserRepository creates a new, empty, in memory User Repository.
+func NewUserRepository() *UserRepository {
+	return &UserRepository{}
+}
+
+// CreateUser stores a new user.
+//
+// Like the users table, ids and emails are unique across all the
+// users, including the deleted ones.
+func (r *UserRepository) CreateUser(_ context.Context, u *user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.users {
+		if s.id == u.ID() || s.email == u.Email() {
+			return errors.NewAlreadyExistsError("user")
+		}
+	}
+
+	r.users = append(r.users, &storedUser{
+		id:      u.ID(),
+		email:   u.Email(),
+		version: u.Version(),
+	})
+
+	r.recordEvents(u.PopEvents())
+
+	return nil
+}
+
+// UpdateUser applies the updateFn to the user with the given id
+// and stores the result.
+func (r *UserRepository) UpdateUser(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, u *user.User) (*user.User, error),
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.find(id)
+	if stored == nil {
+		return errors.NewNotFoundError("user")
+	}
+
+	updatedUser, err := updateFn(
+		ctx,
+		user.UnmarshalFromDatabase(stored.id, stored.email, stored.version),
+	)
+	if err != nil {
+		return fmt.Errorf("update fn: %w", err)
+	}
+
+	if updatedUser.Version() != stored.version {
+		return errors.NewFailedPreconditionError("user version mismatch")
+	}
+
+	for _, s := range r.users {
+		if s != stored && s.email == updatedUser.Email() {
+			return errors.NewAlreadyExistsError("user")
+		}
+	}
+
+	stored.email = updatedUser.Email()
+	stored.deleted = updatedUser.IsDeleted()
+	stored.version++
+
+	r.recordEvents(updatedUser.PopEvents())
+
+	return nil
+}
+
+// ImportUsers stores the users planned by user.PlanImport.
+func (r *UserRepository) ImportUsers(
+	_ context.Context,
+	users []*user.User,
+	mode user.ImportMode,
+) ([]user.ImportResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := make([]user.StoredUser, 0, len(r.users))
+
+	for _, s := range r.users {
+		stored = append(stored, user.StoredUser{
+			ID:      s.id,
+			Email:   s.email,
+			Version: s.version,
+			Deleted: s.deleted,
+		})
+	}
+
+	plan := user.PlanImport(users, stored, mode)
+
+	for _, u := range plan.Create {
+		r.users = append(r.users, &storedUser{
+			id:      u.ID(),
+			email:   u.Email(),
+			version: u.Version(),
+		})
+
+		r.recordEvents(u.PopEvents())
+	}
+
+	for _, u := range plan.Update {
+		s := r.find(u.ID())
+
+		s.email = u.Email()
+		s.version++
+
+		r.recordEvents(u.PopEvents())
+	}
+
+	return plan.Results, nil
+}
+
+// FindUsers returns the users matching the filter,
+// in the order they were created.
+func (r *UserRepository) FindUsers(
+	_ context.Context,
+	filter user.Filter,
+) ([]query.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]query.User, 0)
+
+	for _, s := range r.users {
+		if s.deleted ||
+			(!filter.ID.IsZero() && s.id != filter.ID) ||
+			(filter.Email != nil && s.email != *filter.Email) {
+			continue
+		}
+
+		users = append(users, query.User{
+			ID:      s.id,
+			Email:   s.email,
+			Version: s.version,
+		})
+	}
+
+	return users, nil
+}
+
+// UsersPage returns a page of users ordered by ID.
+func (r *UserRepository) UsersPage(
+	_ context.Context,
+	after uuid.UUID,
+	limit int,
+) ([]query.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]query.User, 0)
+
+	for _, s := range r.users {
+		if s.deleted || s.id.String() <= after.String() {
+			continue
+		}
+
+		users = append(users, query.User{
+			ID:      s.id,
+			Email:   s.email,
+			Version: s.version,
+		})
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID.String() < users[j].ID.String()
+	})
+
+	if len(users) > limit {
+		users = users[:limit]
+	}
+
+	return users, nil
+}
+
+// GetUserByID returns the user with the given ID.
+func (r *UserRepository) GetUserByID(
+	_ context.Context,
+	id uuid.UUID,
+) (query.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s := r.find(id)
+	if s == nil {
+		return query.User{}, errors.NewNotFoundError("user")
+	}
+
+	return query.User{
+		ID:      s.id,
+		Email:   s.email,
+		Version: s.version,
+	}, nil
+}
+
+// Events returns the latest events recorded by the stored users,
+// in the order they were persisted.
+func (r *UserRepository) Events() []user.Event {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	latest := r.latestEvents()
+
+	events := make([]user.Event, len(latest))
+
+	copy(events, latest)
+
+	return events
+}
+
+// find returns the user with the given id unless it was deleted.
+func (r *UserRepository) find(id uuid.UUID) *storedUser {
+	for _, s := range r.users {
+		if s.id == id && !s.deleted {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// recordEvents keeps the events, the ones older than the latest
+// maxEvents are dropped once they double the size of the slice.
+// It must be called with the lock held.
+func (r *UserRepository) recordEvents(events []user.Event) {
+	r.events = append(r.events, events...)
+
+	if len(r.events) > 2*maxEvents {
+		r.events = append(r.events[:0:0], r.latestEvents()...)
+	}
+}
+
+// latestEvents returns the latest maxEvents events.
+// It must be called with the lock held.
+func (r *UserRepository) latestEvents() []user.Event {
+	if len(r.events) <= maxEvents {
+		return r.events
+	}
+
+	return r.events[len(r.events)-maxEvents:]
+}
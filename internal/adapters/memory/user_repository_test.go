@@ -0,0 +1,72 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/usertest"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+func TestUserRepository(t *testing.T) {
+	t.Parallel()
+
+	usertest.TestRepository(t, func(*testing.T) usertest.Repository {
+		return memory.NewUserRepository()
+	})
+}
+
+func TestUserRepository_Events(t *testing.T) {
+	t.Parallel()
+
+	var (
+		i   = is.New(t)
+		ctx = context.Background()
+		r   = memory.NewUserRepository()
+		u   = user.MustNew(uuid.New(), "user@email.com")
+	)
+
+	i.NoErr(r.CreateUser(ctx, u))
+
+	err := r.UpdateUser(
+		ctx,
+		u.ID(),
+		func(_ context.Context, u *user.User) (*user.User, error) {
+			return u, u.Delete()
+		},
+	)
+	i.NoErr(err)
+
+	i.Equal([]user.Event{
+		user.UserCreated{UserID: u.ID(), Email: u.Email()},
+		user.UserDeleted{UserID: u.ID()},
+	}, r.Events())
+}
+
+func TestUserRepository_EventsAreCapped(t *testing.T) {
+	t.Parallel()
+
+	var (
+		i   = is.New(t)
+		ctx = context.Background()
+		r   = memory.NewUserRepository()
+	)
+
+	var last *user.User
+
+	for n := 0; n < 2*memory.MaxEvents+1; n++ {
+		last = user.MustNew(uuid.New(), uuid.New().String()+"@email.com")
+
+		i.NoErr(r.CreateUser(ctx, last))
+	}
+
+	events := r.Events()
+	i.Equal(memory.MaxEvents, len(events))
+	i.Equal(
+		user.UserCreated{UserID: last.ID(), Email: last.Email()},
+		events[len(events)-1],
+	)
+}
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Broker represents the minimal API of a message broker
+// such as NATS JetStream or Kafka.
+type Broker interface {
+	// Publish sends data to the given topic.
+	// Messages published with the same key must be delivered in order.
+	Publish(ctx context.Context, msg BrokerMessage) error
+}
+
+// BrokerMessage represents a message sent to a Broker.
+type BrokerMessage struct {
+	Topic   string
+	Key     []byte
+	Data    []byte
+	Headers map[string]string
+}
+
+var _ EventPublisher = (*BrokerPublisher)(nil)
+
+// BrokerPublisher publishes outbox messages to a Broker.
+// Every event type is published to its own topic and the
+// aggregate id is used as the message key, preserving
+// the order of the events of an aggregate.
+type BrokerPublisher struct {
+	broker      Broker
+	topicPrefix string
+}
+
+// NewBrokerPublisher creates a new BrokerPublisher.
+// The topic of a message is composed of the prefix followed by the
+// event type.
+func NewBrokerPublisher(broker Broker, topicPrefix string) *BrokerPublisher {
+	return &BrokerPublisher{
+		broker:      broker,
+		topicPrefix: topicPrefix,
+	}
+}
+
+// Publish sends the message to the broker.
+func (p *BrokerPublisher) Publish(ctx context.Context, msg Message) error {
+	data, err := marshalEnvelope(msg)
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+
+	err = p.broker.Publish(ctx, BrokerMessage{
+		Topic: p.topicPrefix + msg.EventType,
+		Key:   []byte(msg.AggregateID),
+		Data:  data,
+		Headers: map[string]string{
+			"event-id":   strconv.FormatInt(msg.ID, 10),
+			"event-type": msg.EventType,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("broker publish: %w", err)
+	}
+
+	return nil
+}
+
+var _ Broker = (*LocalBroker)(nil)
+
+// LocalBroker is an in-process Broker that delivers the messages
+// synchronously to its subscribers.
+//
+// It stands in for a real broker in tests and local development.
+type LocalBroker struct {
+	mu          sync.RWMutex
+	subscribers map[string][]func(context.Context, BrokerMessage) error
+}
+
+// NewLocalBroker creates a new LocalBroker.
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{
+		subscribers: make(
+			map[string][]func(context.Context, BrokerMessage) error,
+		),
+	}
+}
+
+// Subscribe registers a handler for the messages published on the topic.
+func (b *LocalBroker) Subscribe(
+	topic string,
+	handler func(context.Context, BrokerMessage) error,
+) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[topic] = append(b.subscribers[topic], handler)
+}
+
+// Publish delivers the message to all the subscribers of its topic.
+// The first error returned by a subscriber is returned.
+func (b *LocalBroker) Publish(ctx context.Context, msg BrokerMessage) error {
+	b.mu.RLock()
+	handlers := b.subscribers[msg.Topic]
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		err := h(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("subscriber %q: %w", msg.Topic, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// envelope is the JSON representation of a Message sent over the wire.
+type envelope struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func marshalEnvelope(msg Message) ([]byte, error) {
+	return json.Marshal(envelope{
+		ID:            msg.ID,
+		AggregateType: msg.AggregateType,
+		AggregateID:   msg.AggregateID,
+		EventType:     msg.EventType,
+		Payload:       msg.Payload,
+		CreatedAt:     msg.CreatedAt,
+	})
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+var _ EventPublisher = (*MemoryPublisher)(nil)
+
+// MemoryPublisher keeps the published messages in memory.
+//
+// It is meant to be used in tests and local development.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryPublisher creates a new MemoryPublisher.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish stores the message in memory.
+func (p *MemoryPublisher) Publish(_ context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = append(p.messages, msg)
+
+	return nil
+}
+
+// Messages returns a copy of the published messages.
+func (p *MemoryPublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	msgs := make([]Message, len(p.messages))
+
+	copy(msgs, p.messages)
+
+	return msgs
+}
@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+)
+
+var _ EventPublisher = MultiPublisher(nil)
+
+// MultiPublisher publishes every message to all its publishers, in order.
+//
+// A message is published again to all the publishers when one of them
+// fails, so every publisher must tolerate duplicates.
+type MultiPublisher []EventPublisher
+
+// Publish satisfies the EventPublisher interface.
+func (p MultiPublisher) Publish(ctx context.Context, msg Message) error {
+	for i, publisher := range p {
+		err := publisher.Publish(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("publisher %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package outbox
+
+import (
+	"time"
+)
+
+type relayOptions struct {
+	pollInterval,
+	lease,
+	minBackoff,
+	maxBackoff time.Duration
+	batchSize int
+}
+
+func defaultRelayOptions() relayOptions {
+	const (
+		pollInterval = time.Second
+		lease        = 30 * time.Second
+		minBackoff   = time.Second
+		maxBackoff   = 5 * time.Minute
+		batchSize    = 100
+	)
+
+	return relayOptions{
+		pollInterval: pollInterval,
+		lease:        lease,
+		minBackoff:   minBackoff,
+		maxBackoff:   maxBackoff,
+		batchSize:    batchSize,
+	}
+}
+
+// RelayOption configures the Relay.
+type RelayOption interface {
+	apply(*relayOptions)
+}
+
+type funcRelayOption struct {
+	f func(*relayOptions)
+}
+
+func (f *funcRelayOption) apply(o *relayOptions) {
+	f.f(o)
+}
+
+func newFuncRelayOption(f func(*relayOptions)) *funcRelayOption {
+	return &funcRelayOption{
+		f: f,
+	}
+}
+
+// WithPollInterval configures how often the Relay checks the outbox
+// when there are no messages left to publish. Default 1s.
+func WithPollInterval(d time.Duration) RelayOption {
+	return newFuncRelayOption(func(o *relayOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	})
+}
+
+// WithBatchSize configures how many messages are claimed at once.
+// Default 100.
+func WithBatchSize(n int) RelayOption {
+	return newFuncRelayOption(func(o *relayOptions) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	})
+}
+
+// WithLease configures for how long claimed messages are hidden
+// from other relays. It should be greater than the time needed
+// to publish a batch. Default 30s.
+func WithLease(d time.Duration) RelayOption {
+	return newFuncRelayOption(func(o *relayOptions) {
+		if d > 0 {
+			o.lease = d
+		}
+	})
+}
+
+// WithBackoff configures the exponential delay between two publish
+// attempts of the same message. Default 1s up to 5m.
+func WithBackoff(minBackoff, maxBackoff time.Duration) RelayOption {
+	return newFuncRelayOption(func(o *relayOptions) {
+		if minBackoff > 0 {
+			o.minBackoff = minBackoff
+		}
+
+		if maxBackoff >= o.minBackoff {
+			o.maxBackoff = maxBackoff
+		}
+	})
+}
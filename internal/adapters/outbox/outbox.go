@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Message represents a domain event stored in the outbox.
+type Message struct {
+	ID            int64
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       []byte
+	Attempts      int
+	CreatedAt     time.Time
+}
+
+// Store defines how the Relay reads and acknowledges
+// messages from the outbox.
+type Store interface {
+	// Claim returns up to limit messages that are due for publishing,
+	// ordered from the oldest to the newest.
+	// At most one message is returned for every aggregate and the
+	// returned messages are hidden from other calls until lease passes.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Message, error)
+
+	// MarkPublished flags the message as published.
+	MarkPublished(ctx context.Context, id int64) error
+
+	// MarkFailed records a failed publish attempt and schedules
+	// the message to be retried at the given time.
+	MarkFailed(
+		ctx context.Context,
+		id int64,
+		retryAt time.Time,
+		cause error,
+	) error
+}
+
+// EventPublisher publishes outbox messages to the outside world.
+//
+// Publish may be called more than once for the same message,
+// consumers should use Message.ID to de-duplicate them.
+type EventPublisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
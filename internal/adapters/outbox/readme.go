@@ -0,0 +1,8 @@
+// Package outbox implements the transactional outbox relay.
+//
+// Domain events are stored by the repositories in an outbox table
+// in the same transaction as the aggregate that recorded them.
+// The Relay polls that table and publishes the messages at-least-once
+// to an EventPublisher, preserving the order of the messages that
+// belong to the same aggregate.
+package outbox
@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"go.uber.org/zap"
+)
+
+// Relay moves messages from the outbox to an EventPublisher.
+type Relay struct {
+	logger    *zap.Logger
+	store     Store
+	publisher EventPublisher
+	opts      relayOptions
+}
+
+// NewRelay creates a new Relay.
+func NewRelay(
+	logger *zap.Logger,
+	store Store,
+	publisher EventPublisher,
+	opt ...RelayOption,
+) *Relay {
+	if store == nil {
+		panic(errors.NewInvalidError("nil outbox store"))
+	}
+
+	if publisher == nil {
+		panic(errors.NewInvalidError("nil event publisher"))
+	}
+
+	opts := defaultRelayOptions()
+
+	for _, o := range opt {
+		o.apply(&opts)
+	}
+
+	return &Relay{
+		logger:    logger.Named("outbox.relay"),
+		store:     store,
+		publisher: publisher,
+		opts:      opts,
+	}
+}
+
+// Run publishes the outbox messages until the context is canceled.
+func (r *Relay) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-timer.C:
+		}
+
+		published, err := r.RelayBatch(ctx)
+		if err != nil {
+			r.logger.Error("relay batch", zap.Error(err))
+		}
+
+		// Keep draining while there is progress, at most one message
+		// per aggregate is claimed in a batch.
+		wait := r.opts.pollInterval
+		if published > 0 {
+			wait = 0
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+// RelayBatch claims a batch of messages and publishes them.
+// It returns the number of messages successfully published.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	msgs, err := r.store.Claim(ctx, r.opts.batchSize, r.opts.lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim: %w", err)
+	}
+
+	var published int
+
+	for _, msg := range msgs {
+		ok, err := r.publish(ctx, msg)
+		if err != nil {
+			return published, err
+		}
+
+		if ok {
+			published++
+		}
+	}
+
+	return published, nil
+}
+
+// publish sends the message to the publisher and acknowledges it.
+// It returns false if the message was scheduled for a retry.
+func (r *Relay) publish(ctx context.Context, msg Message) (bool, error) {
+	publishErr := r.publisher.Publish(ctx, msg)
+	if publishErr == nil {
+		err := r.store.MarkPublished(ctx, msg.ID)
+		if err != nil {
+			return false, fmt.Errorf("mark published %d: %w", msg.ID, err)
+		}
+
+		return true, nil
+	}
+
+	retryAt := time.Now().Add(r.backoff(msg.Attempts))
+
+	r.logger.Warn(
+		"publish message",
+		zap.Int64("id", msg.ID),
+		zap.String("event_type", msg.EventType),
+		zap.Int("attempts", msg.Attempts+1),
+		zap.Time("retry_at", retryAt),
+		zap.Error(publishErr),
+	)
+
+	err := r.store.MarkFailed(ctx, msg.ID, retryAt, publishErr)
+	if err != nil {
+		return false, fmt.Errorf("mark failed %d: %w", msg.ID, err)
+	}
+
+	return false, nil
+}
+
+// backoff returns the delay before the next attempt, doubling
+// it for every previous failed attempt.
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.opts.minBackoff
+
+	for i := 0; i < attempts && d < r.opts.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > r.opts.maxBackoff {
+		d = r.opts.maxBackoff
+	}
+
+	return d
+}
@@ -0,0 +1,295 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"go.uber.org/zap"
+)
+
+var errPublish = errors.New("publish")
+
+func TestRelay(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("PublishesInOrder", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		store := newStore(
+			outbox.Message{ID: 1, AggregateID: "a", EventType: "user.created"},
+			outbox.Message{ID: 2, AggregateID: "b", EventType: "user.created"},
+			outbox.Message{ID: 3, AggregateID: "a", EventType: "user.deleted"},
+		)
+
+		publisher := outbox.NewMemoryPublisher()
+
+		relay := outbox.NewRelay(zap.NewNop(), store, publisher)
+
+		published, err := relay.RelayBatch(ctx)
+		i.NoErr(err)
+		i.Equal(2, published)
+
+		published, err = relay.RelayBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, published)
+
+		var ids []int64
+
+		for _, m := range publisher.Messages() {
+			ids = append(ids, m.ID)
+		}
+
+		i.Equal([]int64{1, 2, 3}, ids)
+		i.Equal(0, len(store.pending()))
+	})
+
+	t.Run("RetriesFailedAndKeepsAggregateOrder", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		store := newStore(
+			outbox.Message{ID: 1, AggregateID: "a", EventType: "user.created"},
+			outbox.Message{ID: 2, AggregateID: "a", EventType: "user.deleted"},
+			outbox.Message{ID: 3, AggregateID: "b", EventType: "user.created"},
+		)
+
+		publisher := &failingPublisher{
+			failures:         1,
+			MemoryPublisher:  outbox.NewMemoryPublisher(),
+			failAggregateIDs: map[string]bool{"a": true},
+		}
+
+		relay := outbox.NewRelay(
+			zap.NewNop(),
+			store,
+			publisher,
+			outbox.WithBackoff(time.Hour, time.Hour),
+		)
+
+		published, err := relay.RelayBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, published)
+
+		failed := store.get(1)
+		i.Equal(1, failed.Attempts)
+		i.True(store.retryAt[1].After(time.Now().Add(time.Minute)))
+
+		// The message waits for its retry, the newer message of the
+		// same aggregate must wait for it.
+		published, err = relay.RelayBatch(ctx)
+		i.NoErr(err)
+		i.Equal(0, published)
+
+		store.makeDue(1)
+
+		published, err = relay.RelayBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, published)
+
+		published, err = relay.RelayBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, published)
+
+		var ids []int64
+
+		for _, m := range publisher.Messages() {
+			ids = append(ids, m.ID)
+		}
+
+		i.Equal([]int64{3, 1, 2}, ids)
+	})
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	var received map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+
+			_ = json.Unmarshal(body, &received)
+
+			if r.Header.Get("X-Event-Type") != "user.created" {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	t.Cleanup(srv.Close)
+
+	p := outbox.NewWebhookPublisher(srv.URL, time.Second)
+
+	err := p.Publish(context.Background(), outbox.Message{
+		ID:          1,
+		AggregateID: "a",
+		EventType:   "user.created",
+		Payload:     []byte(`{"email":"user@email.com"}`),
+	})
+	i.NoErr(err)
+
+	i.Equal("user.created", received["event_type"])
+	i.Equal(
+		map[string]any{"email": "user@email.com"},
+		received["payload"],
+	)
+
+	err = p.Publish(context.Background(), outbox.Message{
+		ID:        2,
+		EventType: "user.deleted",
+		Payload:   []byte(`{}`),
+	})
+	i.True(errors.Is(err, outbox.ErrUnexpectedStatusCode))
+}
+
+type failingPublisher struct {
+	*outbox.MemoryPublisher
+
+	failures         int
+	failAggregateIDs map[string]bool
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, msg outbox.Message) error {
+	if p.failAggregateIDs[msg.AggregateID] && p.failures > 0 {
+		p.failures--
+
+		return errPublish
+	}
+
+	return p.MemoryPublisher.Publish(ctx, msg)
+}
+
+// store is an in memory outbox.Store with the same claim
+// semantics as the PostgreSQL implementation.
+type store struct {
+	mu        sync.Mutex
+	msgs      []outbox.Message
+	published map[int64]bool
+	retryAt   map[int64]time.Time
+}
+
+func newStore(msgs ...outbox.Message) *store {
+	return &store{
+		msgs:      msgs,
+		published: make(map[int64]bool),
+		retryAt:   make(map[int64]time.Time),
+	}
+}
+
+func (s *store) Claim(
+	_ context.Context,
+	limit int,
+	lease time.Duration,
+) ([]outbox.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		now     = time.Now()
+		claimed []outbox.Message
+		seen    = make(map[string]bool)
+	)
+
+	for _, m := range s.msgs {
+		if s.published[m.ID] {
+			continue
+		}
+
+		head := !seen[m.AggregateID]
+		seen[m.AggregateID] = true
+
+		if !head || s.retryAt[m.ID].After(now) || len(claimed) == limit {
+			continue
+		}
+
+		s.retryAt[m.ID] = now.Add(lease)
+
+		claimed = append(claimed, m)
+	}
+
+	return claimed, nil
+}
+
+func (s *store) MarkPublished(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.published[id] = true
+
+	return nil
+}
+
+func (s *store) MarkFailed(
+	_ context.Context,
+	id int64,
+	retryAt time.Time,
+	_ error,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx := range s.msgs {
+		if s.msgs[idx].ID == id {
+			s.msgs[idx].Attempts++
+		}
+	}
+
+	s.retryAt[id] = retryAt
+
+	return nil
+}
+
+func (s *store) get(id int64) outbox.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.msgs {
+		if m.ID == id {
+			return m
+		}
+	}
+
+	return outbox.Message{}
+}
+
+func (s *store) makeDue(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retryAt[id] = time.Time{}
+}
+
+func (s *store) pending() []outbox.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []outbox.Message
+
+	for _, m := range s.msgs {
+		if !s.published[m.ID] {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending
+}
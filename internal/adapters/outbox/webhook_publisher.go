@@ -0,0 +1,73 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var _ EventPublisher = (*WebhookPublisher)(nil)
+
+// ErrUnexpectedStatusCode is returned when the webhook endpoint
+// does not acknowledge the message with a 2xx status code.
+var ErrUnexpectedStatusCode = errors.New("unexpected status code")
+
+// WebhookPublisher publishes messages by POST-ing them as JSON
+// to an HTTP endpoint.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher creates a new WebhookPublisher that sends the
+// messages to the given url.
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{
+		url: url,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Publish sends the message to the webhook endpoint.
+func (p *WebhookPublisher) Publish(ctx context.Context, msg Message) error {
+	body, err := marshalEnvelope(msg)
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		p.url,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", strconv.FormatInt(msg.ID, 10))
+	req.Header.Set("X-Event-Type", msg.EventType)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < http.StatusOK ||
+		res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, res.StatusCode)
+	}
+
+	return nil
+}
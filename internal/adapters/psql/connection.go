@@ -13,21 +13,54 @@ import (
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
 	_ "gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// DriverName is the value of DB.Driver selecting PostgreSQL.
+const DriverName = "postgres"
+
 // Connect will connect to that repository engine.
 func Connect(cfg *config.Config) (*gorm.DB, error) {
+	if cfg.DB.Driver != DriverName && cfg.DB.Driver != "" {
+		return nil, errors.Errorf(
+			"unsupported database driver %q",
+			cfg.DB.Driver,
+		)
+	}
+
+	return open(dsn(cfg, cfg.DB.HOST))
+}
+
+// ConnectReplicas connects to the read replicas configured in
+// DB.REPLICAS, they share the credentials of the primary.
+func ConnectReplicas(cfg *config.Config) ([]*gorm.DB, error) {
+	replicas := make([]*gorm.DB, 0, len(cfg.DB.REPLICAS))
+
+	for _, host := range cfg.DB.REPLICAS {
+		db, err := open(dsn(cfg, host))
+		if err != nil {
+			return nil, errors.Wrapf(err, "connect replica %s", host)
+		}
+
+		replicas = append(replicas, db)
+	}
+
+	return replicas, nil
+}
+
+func dsn(cfg *config.Config, host string) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s sslmode=disable",
+		host,
+		cfg.DB.USER,
+		cfg.DB.PASSWORD,
+		cfg.DB.NAME,
+	)
+}
+
+func open(url string) (*gorm.DB, error) {
 	var db *gorm.DB
 
 	operation := func() error {
-		url := fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s sslmode=disable",
-			cfg.DB.HOST,
-			cfg.DB.USER,
-			cfg.DB.PASSWORD,
-			cfg.DB.NAME,
-		)
 		conn, err := gorm.Open(postgres.Open(url), &gorm.Config{})
 		db = conn
 
@@ -48,8 +81,6 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	db.Logger = db.Logger.LogMode(logger.Info)
-
 	sqlDB, err := db.DB()
 
 	if err == nil {
@@ -0,0 +1,34 @@
+package psql
+
+import (
+	"github.com/jackc/pgconn"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// uniqueViolationCode is the PostgreSQL error code
+// returned when a unique constraint is violated.
+const uniqueViolationCode = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// Error codes returned by PostgreSQL when a transaction
+// conflicts with a concurrent one and can be retried.
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == serializationFailureCode ||
+		pgErr.Code == deadlockDetectedCode
+}
@@ -0,0 +1,169 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"gorm.io/gorm"
+)
+
+var _ idempotency.Store = (*IdempotencyStore)(nil)
+
+// IdempotencyKey represents the idempotency key model
+// in the PostgreSQL database.
+type IdempotencyKey struct {
+	Key         string `gorm:"primaryKey;column:idempotency_key"`
+	Fingerprint string
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// TableName satisfies the gorm.Tabler interface.
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyStore represents a PostgreSQL idempotency.Store.
+type IdempotencyStore struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyStore creates a new PostgreSQL idempotency.Store.
+func NewIdempotencyStore(db *gorm.DB) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+// reserveQuery inserts the key, replacing it when it expired or its
+// request was abandoned. It returns no row when the key is reserved.
+const reserveQuery = `
+INSERT INTO idempotency_keys
+	(idempotency_key, fingerprint, created_at, expires_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (idempotency_key) DO UPDATE SET
+	fingerprint = EXCLUDED.fingerprint,
+	response = NULL,
+	created_at = EXCLUDED.created_at,
+	expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	OR (
+		idempotency_keys.response IS NULL
+		AND idempotency_keys.created_at < ?
+	)
+RETURNING idempotency_key`
+
+// Reserve satisfies the idempotency.Store interface.
+func (s IdempotencyStore) Reserve(
+	ctx context.Context,
+	r idempotency.Reservation,
+) (idempotency.Record, bool, error) {
+	// The existing key may expire and be pruned between the
+	// insert and the select, in which case the insert is retried.
+	const maxAttempts = 2
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		var keys []string
+
+		err := s.db.WithContext(ctx).
+			Raw(
+				reserveQuery,
+				r.Key,
+				r.Fingerprint,
+				time.Now(),
+				r.ExpiresAt,
+				r.StaleBefore,
+			).
+			Scan(&keys).
+			Error
+		if err != nil {
+			return idempotency.Record{}, false, fmt.Errorf(
+				"execute reserve idempotency key query: %w",
+				err,
+			)
+		}
+
+		if len(keys) > 0 {
+			return idempotency.Record{}, true, nil
+		}
+
+		var rows []*IdempotencyKey
+
+		err = s.db.WithContext(ctx).
+			Where("idempotency_key = ?", r.Key).
+			Limit(1).
+			Find(&rows).
+			Error
+		if err != nil {
+			return idempotency.Record{}, false, fmt.Errorf(
+				"execute select idempotency key query: %w",
+				err,
+			)
+		}
+
+		if len(rows) == 0 {
+			continue
+		}
+
+		return idempotency.Record{
+			Fingerprint: rows[0].Fingerprint,
+			Response:    rows[0].Response,
+		}, false, nil
+	}
+
+	return idempotency.Record{}, false, fmt.Errorf(
+		"reserve idempotency key: gave up after %d attempts",
+		maxAttempts,
+	)
+}
+
+// Complete satisfies the idempotency.Store interface.
+func (s IdempotencyStore) Complete(
+	ctx context.Context,
+	key string,
+	response []byte,
+) error {
+	err := s.db.WithContext(ctx).
+		Model(&IdempotencyKey{}).
+		Where("idempotency_key = ?", key).
+		Update("response", response).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute complete idempotency key query: %w", err)
+	}
+
+	return nil
+}
+
+// Release satisfies the idempotency.Store interface.
+func (s IdempotencyStore) Release(ctx context.Context, key string) error {
+	err := s.db.WithContext(ctx).
+		Where("idempotency_key = ?", key).
+		Delete(&IdempotencyKey{}).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute release idempotency key query: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes the keys expired before the given time.
+// It returns the number of deleted keys.
+func (s IdempotencyStore) Prune(
+	ctx context.Context,
+	before time.Time,
+) (int64, error) {
+	res := s.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Delete(&IdempotencyKey{})
+	if res.Error != nil {
+		return 0, fmt.Errorf(
+			"execute prune idempotency keys query: %w",
+			res.Error,
+		)
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,116 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/psqltest"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	var (
+		ctx = context.Background()
+		i   = is.New(t)
+	)
+
+	newStore := func(t *testing.T) *psql.IdempotencyStore {
+		t.Helper()
+
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: psqltest.NewTransactionTestingDB(t),
+		}), &gorm.Config{})
+		i.NoErr(err)
+
+		return psql.NewIdempotencyStore(db)
+	}
+
+	newReservation := func(fingerprint string) idempotency.Reservation {
+		now := time.Now()
+
+		return idempotency.Reservation{
+			Key:         "key",
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(time.Hour),
+			StaleBefore: now.Add(-time.Minute),
+		}
+	}
+
+	t.Run("ReserveAndComplete", func(t *testing.T) {
+		i := i.New(t)
+
+		s := newStore(t)
+
+		_, reserved, err := s.Reserve(ctx, newReservation("a"))
+		i.NoErr(err)
+		i.True(reserved)
+
+		rec, reserved, err := s.Reserve(ctx, newReservation("b"))
+		i.NoErr(err)
+		i.True(!reserved)
+		i.Equal("a", rec.Fingerprint)
+		i.Equal(0, len(rec.Response))
+
+		i.NoErr(s.Complete(ctx, "key", []byte("response")))
+
+		rec, reserved, err = s.Reserve(ctx, newReservation("a"))
+		i.NoErr(err)
+		i.True(!reserved)
+		i.Equal("response", string(rec.Response))
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		i := i.New(t)
+
+		s := newStore(t)
+
+		_, reserved, err := s.Reserve(ctx, newReservation("a"))
+		i.NoErr(err)
+		i.True(reserved)
+
+		i.NoErr(s.Release(ctx, "key"))
+
+		_, reserved, err = s.Reserve(ctx, newReservation("b"))
+		i.NoErr(err)
+		i.True(reserved)
+	})
+
+	t.Run("TakesOverAbandoned", func(t *testing.T) {
+		i := i.New(t)
+
+		s := newStore(t)
+
+		_, reserved, err := s.Reserve(ctx, newReservation("a"))
+		i.NoErr(err)
+		i.True(reserved)
+
+		r := newReservation("a")
+		r.StaleBefore = time.Now().Add(time.Second)
+
+		_, reserved, err = s.Reserve(ctx, r)
+		i.NoErr(err)
+		i.True(reserved)
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		i := i.New(t)
+
+		s := newStore(t)
+
+		_, _, err := s.Reserve(ctx, newReservation("a"))
+		i.NoErr(err)
+
+		n, err := s.Prune(ctx, time.Now())
+		i.NoErr(err)
+		i.Equal(int64(0), n)
+
+		n, err = s.Prune(ctx, time.Now().Add(2*time.Hour))
+		i.NoErr(err)
+		i.Equal(int64(1), n)
+	})
+}
@@ -0,0 +1,300 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/jobs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	_ jobs.Store  = (*JobStore)(nil)
+	_ jobs.Locker = (*AdvisoryLocker)(nil)
+)
+
+// Statuses of the jobs stored in the PostgreSQL database.
+const (
+	jobStatusPending   = "pending"
+	jobStatusSucceeded = "succeeded"
+	jobStatusDead      = "dead"
+)
+
+// Job represents the job model in the PostgreSQL database.
+type Job struct {
+	ID          int64 `gorm:"primaryKey;column:job_id"`
+	Kind        string
+	Payload     []byte `gorm:"type:jsonb"`
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	UniqueKey   *string
+	LastError   *string
+	RunAt       time.Time
+	CreatedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+// TableName satisfies the gorm.Tabler interface.
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// JobStore represents a PostgreSQL jobs.Store.
+type JobStore struct {
+	db *gorm.DB
+}
+
+// NewJobStore creates a new PostgreSQL jobs.Store.
+func NewJobStore(db *gorm.DB) *JobStore {
+	return &JobStore{db: db}
+}
+
+// Insert satisfies the jobs.Store interface.
+func (s JobStore) Insert(ctx context.Context, job jobs.NewJob) error {
+	row := &Job{
+		Kind:        job.Kind,
+		Payload:     job.Payload,
+		Status:      jobStatusPending,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+	}
+
+	if job.UniqueKey != "" {
+		row.UniqueKey = &job.UniqueKey
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "unique_key"}},
+			DoNothing: true,
+		}).
+		Create(row).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute insert job query: %w", err)
+	}
+
+	return nil
+}
+
+// claimJobsQuery leases the oldest due jobs of the given kinds,
+// skipping the ones already locked by other workers.
+const claimJobsQuery = `
+UPDATE jobs SET run_at = ?, attempts = attempts + 1
+WHERE job_id IN (
+	SELECT job_id FROM jobs
+	WHERE status = ?
+		AND run_at <= ?
+		AND kind = ANY(?)
+	ORDER BY run_at
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// Claim satisfies the jobs.Store interface.
+func (s JobStore) Claim(
+	ctx context.Context,
+	kinds []string,
+	limit int,
+	lease time.Duration,
+) ([]jobs.Job, error) {
+	var (
+		now  = time.Now()
+		rows []*Job
+	)
+
+	err := s.db.WithContext(ctx).
+		Raw(
+			claimJobsQuery,
+			now.Add(lease),
+			jobStatusPending,
+			now,
+			pq.StringArray(kinds),
+			limit,
+		).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute claim jobs query: %w", err)
+	}
+
+	// RETURNING does not preserve the order of the sub query.
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+
+	claimed := make([]jobs.Job, 0, len(rows))
+
+	for _, r := range rows {
+		claimed = append(claimed, jobs.Job{
+			ID:          r.ID,
+			Kind:        r.Kind,
+			Payload:     r.Payload,
+			Attempts:    r.Attempts,
+			MaxAttempts: r.MaxAttempts,
+			RunAt:       r.RunAt,
+			CreatedAt:   r.CreatedAt,
+		})
+	}
+
+	return claimed, nil
+}
+
+// MarkSucceeded satisfies the jobs.Store interface.
+func (s JobStore) MarkSucceeded(ctx context.Context, id int64) error {
+	err := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("job_id = ?", id).
+		Updates(map[string]any{
+			"status":      jobStatusSucceeded,
+			"finished_at": time.Now(),
+		}).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute mark succeeded query: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed satisfies the jobs.Store interface.
+func (s JobStore) MarkFailed(
+	ctx context.Context,
+	id int64,
+	retryAt time.Time,
+	cause error,
+) error {
+	err := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("job_id = ?", id).
+		Updates(map[string]any{
+			"run_at":     retryAt,
+			"last_error": cause.Error(),
+		}).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute mark failed query: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDead satisfies the jobs.Store interface.
+func (s JobStore) MarkDead(ctx context.Context, id int64, cause error) error {
+	err := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("job_id = ?", id).
+		Updates(map[string]any{
+			"status":      jobStatusDead,
+			"last_error":  cause.Error(),
+			"finished_at": time.Now(),
+		}).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute mark dead query: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes the jobs finished before the given time.
+// It returns the number of deleted jobs.
+func (s JobStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res := s.db.WithContext(ctx).
+		Where("finished_at < ?", before).
+		Delete(&Job{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("execute prune jobs query: %w", res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// AdvisoryLocker represents a jobs.Locker backed by PostgreSQL
+// session level advisory locks.
+type AdvisoryLocker struct {
+	db *gorm.DB
+}
+
+// NewAdvisoryLocker creates a new AdvisoryLocker.
+func NewAdvisoryLocker(db *gorm.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+// TryLock satisfies the jobs.Locker interface.
+//
+// The lock is bound to a dedicated connection, it is released
+// by PostgreSQL as soon as that connection is lost.
+func (l AdvisoryLocker) TryLock(
+	ctx context.Context,
+	key int64,
+) (jobs.Lock, bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, false, fmt.Errorf("retrieve underlying db: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("acquire connection: %w", err)
+	}
+
+	var acquired bool
+
+	err = conn.
+		QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).
+		Scan(&acquired)
+	if err != nil || !acquired {
+		_ = conn.Close()
+
+		if err != nil {
+			return nil, false, fmt.Errorf("execute try lock query: %w", err)
+		}
+
+		return nil, false, nil
+	}
+
+	return &advisoryLock{
+		conn: conn,
+		key:  key,
+	}, true, nil
+}
+
+type advisoryLock struct {
+	conn *sql.Conn
+	key  int64
+}
+
+// Check closes the connection of the lock when it can't be
+// pinged, so it isn't leaked and the lock, if still held,
+// is released by PostgreSQL.
+func (l *advisoryLock) Check(ctx context.Context) error {
+	err := l.conn.PingContext(ctx)
+	if err != nil {
+		_ = l.conn.Close()
+
+		return fmt.Errorf("ping lock connection: %w", err)
+	}
+
+	return nil
+}
+
+func (l *advisoryLock) Release(ctx context.Context) error {
+	defer func() {
+		_ = l.conn.Close()
+	}()
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	if err != nil {
+		return fmt.Errorf("execute unlock query: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// redactedParam replaces the string parameters of the logged
+// queries, they may hold personal data like emails.
+const redactedParam = "'[REDACTED]'"
+
+// stringLiteral matches the SQL string literals, gorm writes the
+// parameters of the logged queries as literals.
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+var _ gormlogger.Interface = (*Logger)(nil)
+
+// Logger writes the gorm logs, the queries included, to zap with
+// the id of the request they are made for.
+//
+// The queries not finding a record aren't logged as errors,
+// the repositories expect them.
+type Logger struct {
+	logger  *zap.Logger
+	level   gormlogger.LogLevel
+	options loggerOptions
+}
+
+// NewLogger returns a Logger logging the failed and
+// the slow queries.
+func NewLogger(logger *zap.Logger, opts ...LoggerOption) *Logger {
+	options := defaultLoggerOptions()
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	return &Logger{
+		logger:  logger.Named("psql"),
+		level:   options.level,
+		options: options,
+	}
+}
+
+// ParseLogLevel returns the gorm log level named
+// silent, error, warn or info.
+func ParseLogLevel(name string) (gormlogger.LogLevel, error) {
+	switch strings.ToLower(name) {
+	case "silent":
+		return gormlogger.Silent, nil
+	case "error":
+		return gormlogger.Error, nil
+	case "warn":
+		return gormlogger.Warn, nil
+	case "info":
+		return gormlogger.Info, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// LogMode returns a copy of the Logger logging at the given level.
+func (l *Logger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	withLevel := *l
+	withLevel.level = level
+
+	return &withLevel
+}
+
+// Info logs a message at the info level.
+func (l *Logger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Info {
+		l.logger.Info(fmt.Sprintf(msg, data...), requestid.Field(ctx))
+	}
+}
+
+// Warn logs a message at the warn level.
+func (l *Logger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Warn {
+		l.logger.Warn(fmt.Sprintf(msg, data...), requestid.Field(ctx))
+	}
+}
+
+// Error logs a message at the error level.
+func (l *Logger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormlogger.Error {
+		l.logger.Error(fmt.Sprintf(msg, data...), requestid.Field(ctx))
+	}
+}
+
+// Trace logs a query, at the error level when it failed and
+// at the warn level when it was slow. The string parameters of
+// the query are redacted unless configured otherwise.
+func (l *Logger) Trace(
+	ctx context.Context,
+	begin time.Time,
+	fc func() (sql string, rowsAffected int64),
+	err error,
+) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+
+	fields := func() []zap.Field {
+		sql, rows := fc()
+
+		if !l.options.params {
+			sql = stringLiteral.ReplaceAllLiteralString(sql, redactedParam)
+		}
+
+		return []zap.Field{
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+			requestid.Field(ctx),
+		}
+	}
+
+	switch {
+	case err != nil &&
+		l.level >= gormlogger.Error &&
+		!errors.Is(err, gorm.ErrRecordNotFound):
+		l.logger.Error("query", append(fields(), zap.Error(err))...)
+
+	case l.options.slowThreshold > 0 &&
+		elapsed > l.options.slowThreshold &&
+		l.level >= gormlogger.Warn:
+		l.logger.Warn("slow query", fields()...)
+
+	case l.level >= gormlogger.Info:
+		l.logger.Info("query", fields()...)
+	}
+}
+
+type loggerOptions struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+	params        bool
+}
+
+func defaultLoggerOptions() loggerOptions {
+	const slowThreshold = 200 * time.Millisecond
+
+	return loggerOptions{
+		level:         gormlogger.Warn,
+		slowThreshold: slowThreshold,
+		params:        false,
+	}
+}
+
+// LoggerOption configures the Logger.
+type LoggerOption interface {
+	apply(*loggerOptions)
+}
+
+type funcLoggerOption struct {
+	f func(*loggerOptions)
+}
+
+func (f *funcLoggerOption) apply(o *loggerOptions) {
+	f.f(o)
+}
+
+func newFuncLoggerOption(f func(*loggerOptions)) *funcLoggerOption {
+	return &funcLoggerOption{
+		f: f,
+	}
+}
+
+// WithLogLevel configures which queries are logged: none for
+// gormlogger.Silent, the failed ones for gormlogger.Error, the
+// slow ones too for gormlogger.Warn and every one of them for
+// gormlogger.Info. Default gormlogger.Warn.
+func WithLogLevel(level gormlogger.LogLevel) LoggerOption {
+	return newFuncLoggerOption(func(o *loggerOptions) {
+		o.level = level
+	})
+}
+
+// WithSlowThreshold configures the duration past which the
+// queries are logged as slow, 0 disables it. Default 200ms.
+func WithSlowThreshold(d time.Duration) LoggerOption {
+	return newFuncLoggerOption(func(o *loggerOptions) {
+		if d >= 0 {
+			o.slowThreshold = d
+		}
+	})
+}
+
+// WithParams configures the Logger to log the parameters of the
+// queries as they are, only meant for development.
+func WithParams() LoggerOption {
+	return newFuncLoggerOption(func(o *loggerOptions) {
+		o.params = true
+	})
+}
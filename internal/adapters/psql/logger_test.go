@@ -0,0 +1,112 @@
+package psql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestLogger_Trace(t *testing.T) {
+	t.Parallel()
+
+	const query = `SELECT * FROM "users" WHERE email = 'a''b@c.d' LIMIT 1`
+
+	tests := map[string]struct {
+		opts    []psql.LoggerOption
+		elapsed time.Duration
+		err     error
+		msg     string
+		sql     string
+	}{
+		"Fast": {
+			opts:    nil,
+			elapsed: 0,
+			msg:     "",
+		},
+		"Slow": {
+			opts:    nil,
+			elapsed: time.Second,
+			msg:     "slow query",
+			sql: `SELECT * FROM "users" WHERE email = '[REDACTED]' ` +
+				`LIMIT 1`,
+		},
+		"Failed": {
+			opts: []psql.LoggerOption{
+				psql.WithParams(),
+			},
+			err: errors.New("failed"),
+			msg: "query",
+			sql: query,
+		},
+		"NotFound": {
+			opts: nil,
+			err:  gorm.ErrRecordNotFound,
+			msg:  "",
+		},
+		"Info": {
+			opts: []psql.LoggerOption{
+				psql.WithLogLevel(gormlogger.Info),
+				psql.WithSlowThreshold(0),
+			},
+			elapsed: time.Second,
+			msg:     "query",
+			sql: `SELECT * FROM "users" WHERE email = '[REDACTED]' ` +
+				`LIMIT 1`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			var buf bytes.Buffer
+
+			logger := psql.NewLogger(
+				zap.New(zapcore.NewCore(
+					zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+					zapcore.AddSync(&buf),
+					zap.DebugLevel,
+				)),
+				test.opts...,
+			)
+
+			logger.Trace(
+				context.Background(),
+				time.Now().Add(-test.elapsed),
+				func() (string, int64) {
+					return query, 1
+				},
+				test.err,
+			)
+
+			if test.msg == "" {
+				i.Equal(buf.Len(), 0) // not logged
+
+				return
+			}
+
+			var line struct {
+				Msg string `json:"msg"`
+				SQL string `json:"sql"`
+			}
+
+			i.NoErr(json.Unmarshal(buf.Bytes(), &line))
+			i.Equal(line.Msg, test.msg)
+			i.Equal(line.SQL, test.sql)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"gorm.io/gorm"
+)
+
+var _ outbox.Store = (*OutboxStore)(nil)
+
+// OutboxMessage represents the outbox model in the PostgreSQL database.
+type OutboxMessage struct {
+	ID            int64 `gorm:"primaryKey;column:outbox_id"`
+	AggregateType string
+	AggregateID   uuid.UUID
+	EventType     string
+	Payload       []byte `gorm:"type:jsonb"`
+	Attempts      int
+	LastError     *string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	PublishedAt   *time.Time
+}
+
+// TableName satisfies the gorm.Tabler interface.
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
+
+// OutboxStore represents a PostgreSQL outbox.Store.
+type OutboxStore struct {
+	db *gorm.DB
+}
+
+// NewOutboxStore creates a new PostgreSQL outbox.Store.
+func NewOutboxStore(db *gorm.DB) *OutboxStore {
+	return &OutboxStore{db: db}
+}
+
+// claimQuery locks and leases the oldest unpublished message of every
+// aggregate, skipping the ones already locked by other relays.
+const claimQuery = `
+UPDATE outbox SET next_attempt_at = ?
+WHERE outbox_id IN (
+	SELECT o.outbox_id FROM outbox o
+	WHERE o.published_at IS NULL
+		AND o.next_attempt_at <= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM outbox prev
+			WHERE prev.aggregate_id = o.aggregate_id
+				AND prev.published_at IS NULL
+				AND prev.outbox_id < o.outbox_id
+		)
+	ORDER BY o.outbox_id
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// Claim satisfies the outbox.Store interface.
+func (s OutboxStore) Claim(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]outbox.Message, error) {
+	var (
+		now  = time.Now()
+		rows []*OutboxMessage
+	)
+
+	err := s.db.WithContext(ctx).
+		Raw(claimQuery, now.Add(lease), now, limit).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute claim query: %w", err)
+	}
+
+	// RETURNING does not preserve the order of the sub query.
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+
+	msgs := make([]outbox.Message, 0, len(rows))
+
+	for _, r := range rows {
+		msgs = append(msgs, outbox.Message{
+			ID:            r.ID,
+			AggregateType: r.AggregateType,
+			AggregateID:   r.AggregateID.String(),
+			EventType:     r.EventType,
+			Payload:       r.Payload,
+			Attempts:      r.Attempts,
+			CreatedAt:     r.CreatedAt,
+		})
+	}
+
+	return msgs, nil
+}
+
+// MarkPublished satisfies the outbox.Store interface.
+func (s OutboxStore) MarkPublished(ctx context.Context, id int64) error {
+	err := s.db.WithContext(ctx).
+		Model(&OutboxMessage{}).
+		Where("outbox_id = ?", id).
+		Update("published_at", time.Now()).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute mark published query: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed satisfies the outbox.Store interface.
+func (s OutboxStore) MarkFailed(
+	ctx context.Context,
+	id int64,
+	retryAt time.Time,
+	cause error,
+) error {
+	err := s.db.WithContext(ctx).
+		Model(&OutboxMessage{}).
+		Where("outbox_id = ?", id).
+		Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": retryAt,
+			"last_error":      cause.Error(),
+		}).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute mark failed query: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes the messages published before the given time.
+// It returns the number of deleted messages.
+func (s OutboxStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res := s.db.WithContext(ctx).
+		Where("published_at < ?", before).
+		Delete(&OutboxMessage{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("execute prune outbox query: %w", res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// saveUserEvents stores the events recorded by the users in the
+// outbox, they are cleared with popUserEvents once committed.
+func saveUserEvents(
+	ctx context.Context,
+	db *gorm.DB,
+	users ...*user.User,
+) error {
+	now := time.Now()
+
+	var msgs []*OutboxMessage
+
+	for _, u := range users {
+		for _, e := range u.Events() {
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return fmt.Errorf("marshal %s event: %w", e.EventName(), err)
+			}
+
+			msgs = append(msgs, &OutboxMessage{
+				AggregateType: user.AggregateType,
+				AggregateID:   u.ID(),
+				EventType:     e.EventName(),
+				Payload:       payload,
+				NextAttemptAt: now,
+			})
+		}
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	err := db.WithContext(ctx).
+		CreateInBatches(msgs, insertBatchSize).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute create outbox messages query: %w", err)
+	}
+
+	return nil
+}
+
+// popUserEvents clears the events of the users
+// saved by a committed transaction.
+func popUserEvents(users ...*user.User) {
+	for _, u := range users {
+		u.PopEvents()
+	}
+}
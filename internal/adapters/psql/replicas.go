@@ -0,0 +1,383 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/session"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// replicaStatusQuery returns whether a replica is connected to the
+// primary, through a running WAL receiver, and its replication lag
+// in seconds. The primary is always connected.
+//
+// A replica that replayed everything it received is not lagging,
+// even if the primary had no recent writes, but one without a WAL
+// receiver may not receive anything while still replaying it all.
+const replicaStatusQuery = `
+SELECT
+	NOT pg_is_in_recovery()
+		OR EXISTS (SELECT 1 FROM pg_stat_wal_receiver) AS connected,
+	CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(
+			EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()),
+			0
+		)
+	END AS lag`
+
+// Replicas routes the reads either to a healthy read replica
+// or to the primary.
+//
+// The replicas are considered unhealthy until they are checked,
+// when none is healthy the reads go to the primary.
+type Replicas struct {
+	logger   *zap.Logger
+	primary  *gorm.DB
+	replicas []*replica
+	next     uint64
+	options  replicasOptions
+
+	mu     sync.Mutex
+	writes map[string]time.Time
+}
+
+type replica struct {
+	db      *gorm.DB
+	healthy int32
+}
+
+// NewReplicas creates a new Replicas routing the reads to the
+// given read replicas.
+func NewReplicas(
+	logger *zap.Logger,
+	primary *gorm.DB,
+	replicaDBs []*gorm.DB,
+	opts ...ReplicasOption,
+) *Replicas {
+	options := defaultReplicasOptions()
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	replicas := make([]*replica, 0, len(replicaDBs))
+
+	for _, db := range replicaDBs {
+		replicas = append(replicas, &replica{db: db})
+	}
+
+	return &Replicas{
+		logger:   logger.Named("psql.replicas"),
+		primary:  primary,
+		replicas: replicas,
+		options:  options,
+		writes:   make(map[string]time.Time),
+	}
+}
+
+// Reader returns the database the reads should be sent to.
+//
+// The primary is returned when no replica is healthy or when the
+// session carried by ctx wrote in the read your writes window.
+func (r *Replicas) Reader(ctx context.Context) *gorm.DB {
+	if r.wroteRecently(ctx) {
+		return r.primary
+	}
+
+	n := uint64(len(r.replicas))
+
+	for i := uint64(0); i < n; i++ {
+		rep := r.replicas[(atomic.AddUint64(&r.next, 1)-1)%n]
+
+		if atomic.LoadInt32(&rep.healthy) == 1 {
+			return rep.db
+		}
+	}
+
+	return r.primary
+}
+
+// RecordWrite records that the session carried by ctx wrote to the
+// primary so its next reads are sent to the primary as well.
+//
+// It does nothing when there are no replicas, the read your writes
+// window is disabled or ctx carries no session.
+func (r *Replicas) RecordWrite(ctx context.Context) {
+	if r == nil ||
+		len(r.replicas) == 0 ||
+		r.options.readYourWritesWindow <= 0 {
+		return
+	}
+
+	id, ok := session.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writes[id] = time.Now()
+}
+
+// wroteRecently reports whether the session carried by ctx wrote
+// in the read your writes window, forgetting its write otherwise.
+func (r *Replicas) wroteRecently(ctx context.Context) bool {
+	id, ok := session.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	at, ok := r.writes[id]
+	if !ok {
+		return false
+	}
+
+	if time.Since(at) > r.options.readYourWritesWindow {
+		delete(r.writes, id)
+
+		return false
+	}
+
+	return true
+}
+
+// pruneWrites forgets the writes older than the read your writes
+// window of the sessions which didn't read since.
+func (r *Replicas) pruneWrites() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	for id, at := range r.writes {
+		if now.Sub(at) > r.options.readYourWritesWindow {
+			delete(r.writes, id)
+		}
+	}
+}
+
+// Run checks the health of the replicas and prunes the expired
+// writes periodically until ctx is canceled.
+func (r *Replicas) Run(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.options.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		r.CheckHealth(ctx)
+		r.pruneWrites()
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckHealth marks as unhealthy the replicas that can't be
+// queried, have no WAL receiver connected to the primary or lag
+// behind the primary more than the allowed maximum, and the other
+// ones as healthy.
+func (r *Replicas) CheckHealth(ctx context.Context) {
+	for idx, rep := range r.replicas {
+		var healthy int32
+
+		status, err := checkReplica(ctx, rep.db, r.options.checkInterval)
+
+		switch lag := status.lag(); {
+		case err != nil:
+			r.logger.Warn(
+				"replica check failed",
+				zap.Int("replica", idx),
+				zap.Error(err),
+			)
+
+		case !status.Connected:
+			r.logger.Warn(
+				"replica disconnected from the primary",
+				zap.Int("replica", idx),
+			)
+
+		case lag > r.options.maxLag:
+			r.logger.Warn(
+				"replica lagging",
+				zap.Int("replica", idx),
+				zap.Duration("lag", lag),
+			)
+
+		default:
+			healthy = 1
+		}
+
+		atomic.StoreInt32(&rep.healthy, healthy)
+	}
+}
+
+// replicaStatus is the result of the replicaStatusQuery.
+type replicaStatus struct {
+	Connected bool
+	Lag       float64
+}
+
+func (s replicaStatus) lag() time.Duration {
+	return time.Duration(s.Lag * float64(time.Second))
+}
+
+func checkReplica(
+	ctx context.Context,
+	db *gorm.DB,
+	timeout time.Duration,
+) (replicaStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var status replicaStatus
+
+	err := db.WithContext(ctx).Raw(replicaStatusQuery).Scan(&status).Error
+	if err != nil {
+		return replicaStatus{}, fmt.Errorf(
+			"execute replica status query: %w",
+			err,
+		)
+	}
+
+	return status, nil
+}
+
+type replicasOptions struct {
+	maxLag,
+	checkInterval,
+	readYourWritesWindow time.Duration
+}
+
+func defaultReplicasOptions() replicasOptions {
+	const (
+		maxLag        = 5 * time.Second
+		checkInterval = 5 * time.Second
+	)
+
+	return replicasOptions{
+		maxLag:               maxLag,
+		checkInterval:        checkInterval,
+		readYourWritesWindow: 0,
+	}
+}
+
+// ReplicasOption configures the Replicas.
+type ReplicasOption interface {
+	apply(*replicasOptions)
+}
+
+type funcReplicasOption struct {
+	f func(*replicasOptions)
+}
+
+func (f *funcReplicasOption) apply(o *replicasOptions) {
+	f.f(o)
+}
+
+func newFuncReplicasOption(f func(*replicasOptions)) *funcReplicasOption {
+	return &funcReplicasOption{
+		f: f,
+	}
+}
+
+// WithMaxLag configures the replication lag above which a replica
+// is no longer used. Default 5s.
+func WithMaxLag(d time.Duration) ReplicasOption {
+	return newFuncReplicasOption(func(o *replicasOptions) {
+		if d > 0 {
+			o.maxLag = d
+		}
+	})
+}
+
+// WithCheckInterval configures how often the health of the replicas
+// is checked. Default 5s.
+func WithCheckInterval(d time.Duration) ReplicasOption {
+	return newFuncReplicasOption(func(o *replicasOptions) {
+		if d > 0 {
+			o.checkInterval = d
+		}
+	})
+}
+
+// WithReadYourWritesWindow configures for how long the reads of a
+// session are sent to the primary after it wrote, so it reads its
+// own writes. Default 0, disabled.
+func WithReadYourWritesWindow(d time.Duration) ReplicasOption {
+	return newFuncReplicasOption(func(o *replicasOptions) {
+		if d >= 0 {
+			o.readYourWritesWindow = d
+		}
+	})
+}
+
+type repositoryOptions struct {
+	replicas *Replicas
+}
+
+// RepositoryOption configures the repositories.
+type RepositoryOption interface {
+	apply(*repositoryOptions)
+}
+
+type funcRepositoryOption struct {
+	f func(*repositoryOptions)
+}
+
+func (f *funcRepositoryOption) apply(o *repositoryOptions) {
+	f.f(o)
+}
+
+func newFuncRepositoryOption(
+	f func(*repositoryOptions),
+) *funcRepositoryOption {
+	return &funcRepositoryOption{
+		f: f,
+	}
+}
+
+// WithReplicas configures the repository to send the queries of its
+// read models through the given Replicas.
+// By default everything is sent to the primary.
+func WithReplicas(replicas *Replicas) RepositoryOption {
+	return newFuncRepositoryOption(func(o *repositoryOptions) {
+		o.replicas = replicas
+	})
+}
+
+func newRepositoryOptions(opts []RepositoryOption) repositoryOptions {
+	var options repositoryOptions
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	return options
+}
+
+// reader returns the database the read models should query.
+func (o repositoryOptions) reader(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if o.replicas == nil {
+		return db
+	}
+
+	return o.replicas.Reader(ctx)
+}
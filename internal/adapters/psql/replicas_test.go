@@ -0,0 +1,110 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/psqltest"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/session"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestReplicas(t *testing.T) {
+	var (
+		ctx = context.Background()
+		i   = is.New(t)
+	)
+
+	newDB := func(t *testing.T) *gorm.DB {
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: psqltest.NewTransactionTestingDB(t),
+		}), &gorm.Config{})
+		is.New(t).NoErr(err)
+
+		return db
+	}
+
+	t.Run("PrimaryUntilChecked", func(t *testing.T) {
+		i := i.New(t)
+
+		primary, replica := newDB(t), newDB(t)
+
+		r := psql.NewReplicas(zap.NewNop(), primary, []*gorm.DB{replica})
+		i.Equal(primary, r.Reader(ctx))
+
+		r.CheckHealth(ctx)
+		i.Equal(replica, r.Reader(ctx))
+	})
+
+	t.Run("FailedReplicaFallsBackToPrimary", func(t *testing.T) {
+		i := i.New(t)
+
+		primary, replica := newDB(t), newDB(t)
+
+		r := psql.NewReplicas(zap.NewNop(), primary, []*gorm.DB{replica})
+
+		r.CheckHealth(ctx)
+		i.Equal(replica, r.Reader(ctx))
+
+		sqlDB, err := replica.DB()
+		i.NoErr(err)
+		i.NoErr(sqlDB.Close())
+
+		r.CheckHealth(ctx)
+		i.Equal(primary, r.Reader(ctx))
+	})
+
+	t.Run("ReadYourWrites", func(t *testing.T) {
+		i := i.New(t)
+
+		primary, replica := newDB(t), newDB(t)
+
+		r := psql.NewReplicas(
+			zap.NewNop(),
+			primary,
+			[]*gorm.DB{replica},
+			psql.WithReadYourWritesWindow(time.Hour),
+		)
+
+		r.CheckHealth(ctx)
+
+		var (
+			writer = session.NewContext(ctx, "writer")
+			reader = session.NewContext(ctx, "reader")
+		)
+
+		r.RecordWrite(writer)
+
+		i.Equal(primary, r.Reader(writer))
+		i.Equal(replica, r.Reader(reader))
+		i.Equal(replica, r.Reader(ctx))
+	})
+
+	t.Run("ReadYourWritesExpire", func(t *testing.T) {
+		i := i.New(t)
+
+		primary, replica := newDB(t), newDB(t)
+
+		r := psql.NewReplicas(
+			zap.NewNop(),
+			primary,
+			[]*gorm.DB{replica},
+			psql.WithReadYourWritesWindow(time.Millisecond),
+		)
+
+		r.CheckHealth(ctx)
+
+		writer := session.NewContext(ctx, "writer")
+
+		r.RecordWrite(writer)
+
+		time.Sleep(10 * time.Millisecond)
+
+		i.Equal(replica, r.Reader(writer))
+	})
+}
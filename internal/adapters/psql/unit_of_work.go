@@ -0,0 +1,216 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"gorm.io/gorm"
+)
+
+var _ command.UnitOfWork = (*UnitOfWork)(nil)
+
+// txContextKey is the key under which the running unitOfWorkTx
+// is stored in the context passed to the unit of work function.
+type txContextKey struct{}
+
+// unitOfWorkTx holds the running transaction and the functions
+// the repositories want to run once it is committed.
+type unitOfWorkTx struct {
+	tx          *gorm.DB
+	afterCommit []func()
+}
+
+// UnitOfWork runs functions in a PostgreSQL transaction.
+//
+// Nested units of work run in a savepoint of the outer transaction.
+// The outermost transaction is retried when it fails because of a
+// serialization failure or a deadlock.
+//
+// The repositories clear the events of the users they save only
+// once the outermost transaction is committed, so a retried
+// transaction saves them again.
+type UnitOfWork struct {
+	db      *gorm.DB
+	options unitOfWorkOptions
+}
+
+// NewUnitOfWork creates a new PostgreSQL UnitOfWork.
+func NewUnitOfWork(db *gorm.DB, opts ...UnitOfWorkOption) *UnitOfWork {
+	options := defaultUnitOfWorkOptions()
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	return &UnitOfWork{
+		db:      db,
+		options: options,
+	}
+}
+
+// Do satisfies the command.UnitOfWork interface.
+func (u *UnitOfWork) Do(
+	ctx context.Context,
+	fn func(ctx context.Context, repos command.Repositories) error,
+) error {
+	if parent, ok := ctx.Value(txContextKey{}).(*unitOfWorkTx); ok {
+		nested := &unitOfWorkTx{}
+
+		err := parent.tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			nested.tx = tx
+
+			return u.run(ctx, nested, fn)
+		})
+		if err != nil {
+			return fmt.Errorf("savepoint: %w", err)
+		}
+
+		parent.afterCommit = append(parent.afterCommit, nested.afterCommit...)
+
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		uowTx := &unitOfWorkTx{}
+
+		err := u.db.WithContext(ctx).Transaction(
+			func(tx *gorm.DB) error {
+				uowTx.tx = tx
+
+				return u.run(ctx, uowTx, fn)
+			},
+			&sql.TxOptions{Isolation: u.options.isolation},
+		)
+		if err == nil {
+			for _, f := range uowTx.afterCommit {
+				f()
+			}
+
+			return nil
+		}
+
+		if attempt >= u.options.maxAttempts || !isRetryableTxError(err) {
+			return fmt.Errorf("tx sql: %w", err)
+		}
+
+		timer := time.NewTimer(time.Duration(attempt) * u.options.retryDelay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("tx sql: %w", err)
+
+		case <-timer.C:
+		}
+	}
+}
+
+func (u *UnitOfWork) run(
+	ctx context.Context,
+	uowTx *unitOfWorkTx,
+	fn func(ctx context.Context, repos command.Repositories) error,
+) error {
+	return fn(
+		context.WithValue(ctx, txContextKey{}, uowTx),
+		command.Repositories{
+			Users: NewUserRepository(
+				uowTx.tx,
+				u.options.repositoryOptions...,
+			),
+			Webhooks: NewWebhookRepository(
+				uowTx.tx,
+				u.options.repositoryOptions...,
+			),
+		},
+	)
+}
+
+// afterCommit runs f once the unit of work carried by ctx is
+// committed, or right away when ctx carries none.
+func afterCommit(ctx context.Context, f func()) {
+	uowTx, ok := ctx.Value(txContextKey{}).(*unitOfWorkTx)
+	if !ok {
+		f()
+
+		return
+	}
+
+	uowTx.afterCommit = append(uowTx.afterCommit, f)
+}
+
+type unitOfWorkOptions struct {
+	isolation         sql.IsolationLevel
+	maxAttempts       int
+	retryDelay        time.Duration
+	repositoryOptions []RepositoryOption
+}
+
+func defaultUnitOfWorkOptions() unitOfWorkOptions {
+	const (
+		maxAttempts = 3
+		retryDelay  = 10 * time.Millisecond
+	)
+
+	return unitOfWorkOptions{
+		isolation:   sql.LevelDefault,
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+	}
+}
+
+// UnitOfWorkOption configures the UnitOfWork.
+type UnitOfWorkOption interface {
+	apply(*unitOfWorkOptions)
+}
+
+type funcUnitOfWorkOption struct {
+	f func(*unitOfWorkOptions)
+}
+
+func (f *funcUnitOfWorkOption) apply(o *unitOfWorkOptions) {
+	f.f(o)
+}
+
+func newFuncUnitOfWorkOption(
+	f func(*unitOfWorkOptions),
+) *funcUnitOfWorkOption {
+	return &funcUnitOfWorkOption{
+		f: f,
+	}
+}
+
+// WithIsolationLevel configures the isolation level of the
+// transactions. Default the database default, read committed.
+func WithIsolationLevel(level sql.IsolationLevel) UnitOfWorkOption {
+	return newFuncUnitOfWorkOption(func(o *unitOfWorkOptions) {
+		o.isolation = level
+	})
+}
+
+// WithRetry configures how many times a transaction is attempted
+// when it fails because of a serialization failure or a deadlock
+// and the delay between attempts, growing linearly.
+// Default 3 attempts, 10ms.
+func WithRetry(maxAttempts int, delay time.Duration) UnitOfWorkOption {
+	return newFuncUnitOfWorkOption(func(o *unitOfWorkOptions) {
+		if maxAttempts > 0 {
+			o.maxAttempts = maxAttempts
+		}
+
+		if delay >= 0 {
+			o.retryDelay = delay
+		}
+	})
+}
+
+// WithRepositoryOptions configures the repositories passed
+// to the unit of work functions.
+func WithRepositoryOptions(opts ...RepositoryOption) UnitOfWorkOption {
+	return newFuncUnitOfWorkOption(func(o *unitOfWorkOptions) {
+		o.repositoryOptions = append(o.repositoryOptions, opts...)
+	})
+}
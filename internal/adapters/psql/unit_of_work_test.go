@@ -0,0 +1,190 @@
+package psql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/psqltest"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var errAbort = errors.New("abort")
+
+func TestUnitOfWork(t *testing.T) {
+	var (
+		ctx = context.Background()
+		i   = is.New(t)
+	)
+
+	newUnitOfWork := func(t *testing.T) (*psql.UnitOfWork, *psql.Repository) {
+		i := i.New(t)
+
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: psqltest.NewTransactionTestingDB(t),
+		}), &gorm.Config{})
+		i.NoErr(err)
+
+		return psql.NewUnitOfWork(db), psql.NewUserRepository(db)
+	}
+
+	newUser := func(t *testing.T) *user.User {
+		u, err := user.New(uuid.New(), uuid.New().String()+"@email.com")
+		is.New(t).NoErr(err)
+
+		return u
+	}
+
+	t.Run("CommitsAllRepositories", func(t *testing.T) {
+		i := i.New(t)
+
+		uow, users := newUnitOfWork(t)
+
+		u := newUser(t)
+
+		err := uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+			err := repos.Users.CreateUser(ctx, u)
+			if err != nil {
+				return err
+			}
+
+			sub, err := webhook.NewSubscription(
+				uuid.New(),
+				"https://example.com/webhooks",
+				[]string{"user.created"},
+				"0123456789abcdef",
+			)
+			if err != nil {
+				return err
+			}
+
+			return repos.Webhooks.CreateSubscription(ctx, sub)
+		})
+		i.NoErr(err)
+
+		_, err = users.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+	})
+
+	t.Run("NestedRollsBackToSavepoint", func(t *testing.T) {
+		i := i.New(t)
+
+		uow, users := newUnitOfWork(t)
+
+		outer, inner := newUser(t), newUser(t)
+
+		err := uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+			err := repos.Users.CreateUser(ctx, outer)
+			if err != nil {
+				return err
+			}
+
+			err = uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+				err := repos.Users.CreateUser(ctx, inner)
+				if err != nil {
+					return err
+				}
+
+				return errAbort
+			})
+			if !errors.Is(err, errAbort) {
+				return err
+			}
+
+			return nil
+		})
+		i.NoErr(err)
+
+		_, err = users.GetUserByID(ctx, outer.ID())
+		i.NoErr(err)
+
+		_, err = users.GetUserByID(ctx, inner.ID())
+		i.True(err != nil)
+	})
+
+	t.Run("RetriesSerializationFailure", func(t *testing.T) {
+		i := i.New(t)
+
+		uow, _ := newUnitOfWork(t)
+
+		var attempts int
+
+		err := uow.Do(ctx, func(context.Context, command.Repositories) error {
+			attempts++
+
+			if attempts == 1 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+
+			return nil
+		})
+		i.NoErr(err)
+		i.Equal(2, attempts)
+	})
+
+	t.Run("KeepsEventsUntilCommitted", func(t *testing.T) {
+		i := i.New(t)
+
+		uow, _ := newUnitOfWork(t)
+
+		u := newUser(t)
+
+		var attempts int
+
+		err := uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+			attempts++
+
+			err := repos.Users.CreateUser(ctx, u)
+			if err != nil {
+				return err
+			}
+
+			if len(u.Events()) != 1 {
+				return errAbort
+			}
+
+			if attempts == 1 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+
+			return nil
+		})
+		i.NoErr(err)
+		i.Equal(2, attempts)
+		i.Equal(0, len(u.Events()))
+	})
+
+	t.Run("RollsBackOnError", func(t *testing.T) {
+		i := i.New(t)
+
+		uow, users := newUnitOfWork(t)
+
+		u := newUser(t)
+
+		var attempts int
+
+		err := uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+			attempts++
+
+			err := repos.Users.CreateUser(ctx, u)
+			if err != nil {
+				return err
+			}
+
+			return errAbort
+		})
+		i.True(errors.Is(err, errAbort))
+		i.Equal(1, attempts)
+
+		_, err = users.GetUserByID(ctx, u.ID())
+		i.True(err != nil)
+	})
+}
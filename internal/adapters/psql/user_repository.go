@@ -3,24 +3,35 @@ package psql
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
 	_ user.Repository          = (*Repository)(nil)
+	_ user.Importer            = (*Repository)(nil)
 	_ query.UserByIDReadModel  = (*Repository)(nil)
 	_ query.FindUsersReadModel = (*Repository)(nil)
+	_ query.UsersPageReadModel = (*Repository)(nil)
 )
 
+// insertBatchSize is the number of rows written by a single
+// multi row insert or update, kept well under the limit of
+// query parameters.
+const insertBatchSize = 1000
+
 // User represents the user model in the PostgreSQL database.
 type User struct {
-	ID    uuid.UUID `validate:"required" gorm:"primaryKey;column:user_id"`
-	Email string    `validate:"required,email"`
+	ID        uuid.UUID `validate:"required" gorm:"primaryKey;column:user_id"`
+	Email     string    `validate:"required,email"`
+	Version   int64     `validate:"required"`
+	DeletedAt gorm.DeletedAt
 }
 
 // TableName satisfies the gorm.Tabler interface.
@@ -30,12 +41,16 @@ func (User) TableName() string {
 
 // Repository represents a PostgreSQL User Repository.
 type Repository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	options repositoryOptions
 }
 
 // NewUserRepository creates a new PostgreSQL User Repository.
-func NewUserRepository(db *gorm.DB) *Repository {
-	return &Repository{db: db}
+func NewUserRepository(db *gorm.DB, opts ...RepositoryOption) *Repository {
+	return &Repository{
+		db:      db,
+		options: newRepositoryOptions(opts),
+	}
 }
 
 // CreateUser inserts a new user into the PostgreSQL database.
@@ -51,15 +66,164 @@ func (r Repository) CreateUser(ctx context.Context, u *user.User) error {
 			return fmt.Errorf("create user query: %w", err)
 		}
 
+		err = saveUserEvents(ctx, tx, u)
+		if err != nil {
+			return fmt.Errorf("save user events: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("tx sql: %w", err)
 	}
 
+	afterCommit(ctx, func() { popUserEvents(u) })
+
+	r.options.replicas.RecordWrite(ctx)
+
 	return nil
 }
 
+// UpdateUser locks the user row, applies the updateFn and persists
+// the updated user into the PostgreSQL database.
+func (r Repository) UpdateUser(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, u *user.User) (*user.User, error),
+) error {
+	var updatedUser *user.User
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sqlUser, err := getUserForUpdate(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("get user for update query: %w", err)
+		}
+
+		updatedUser, err = updateFn(
+			ctx,
+			user.UnmarshalFromDatabase(
+				sqlUser.ID,
+				sqlUser.Email,
+				sqlUser.Version,
+			),
+		)
+		if err != nil {
+			return fmt.Errorf("update fn: %w", err)
+		}
+
+		psqlUser, err := r.marshalUser(updatedUser)
+		if err != nil {
+			return fmt.Errorf("marshal user: %w", err)
+		}
+
+		if updatedUser.IsDeleted() {
+			err = deleteUser(ctx, tx, psqlUser)
+		} else {
+			err = updateUser(ctx, tx, psqlUser)
+		}
+
+		if err != nil {
+			return fmt.Errorf("persist user query: %w", err)
+		}
+
+		err = saveUserEvents(ctx, tx, updatedUser)
+		if err != nil {
+			return fmt.Errorf("save user events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("tx sql: %w", err)
+	}
+
+	afterCommit(ctx, func() { popUserEvents(updatedUser) })
+
+	r.options.replicas.RecordWrite(ctx)
+
+	return nil
+}
+
+// ImportUsers persists the users planned by user.PlanImport into
+// the PostgreSQL database, inserting the new users and their events
+// with multi row inserts and updating the existing ones with multi
+// row updates.
+//
+// The users failing validation are reported as failed and left out
+// of the import.
+func (r Repository) ImportUsers(
+	ctx context.Context,
+	users []*user.User,
+	mode user.ImportMode,
+) ([]user.ImportResult, error) {
+	var (
+		results = make([]user.ImportResult, len(users))
+		valid   = make([]*user.User, 0, len(users))
+		indexes = make([]int, 0, len(users))
+	)
+
+	for i, u := range users {
+		_, err := r.marshalUser(u)
+		if err != nil {
+			results[i] = user.ImportResult{
+				Status: user.ImportStatusFailed,
+				Err:    errors.NewInvalidError("user"),
+			}
+
+			continue
+		}
+
+		valid = append(valid, u)
+		indexes = append(indexes, i)
+	}
+
+	var plan user.ImportPlan
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stored, err := findStoredUsers(ctx, tx, valid)
+		if err != nil {
+			return fmt.Errorf("find stored users query: %w", err)
+		}
+
+		plan = user.PlanImport(valid, stored, mode)
+
+		err = insertUsers(ctx, tx, plan.Create)
+		if err != nil {
+			return fmt.Errorf("insert users query: %w", err)
+		}
+
+		err = updateUsers(ctx, tx, plan.Update)
+		if err != nil {
+			return fmt.Errorf("update users query: %w", err)
+		}
+
+		for _, batch := range [][]*user.User{plan.Create, plan.Update} {
+			err = saveUserEvents(ctx, tx, batch...)
+			if err != nil {
+				return fmt.Errorf("save user events: %w", err)
+			}
+		}
+
+		for i, res := range plan.Results {
+			results[indexes[i]] = res
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tx sql: %w", err)
+	}
+
+	afterCommit(ctx, func() {
+		popUserEvents(plan.Create...)
+		popUserEvents(plan.Update...)
+	})
+
+	r.options.replicas.RecordWrite(ctx)
+
+	return results, nil
+}
+
 // FindUsers queries the PostgreSQL database for users.
 func (r Repository) FindUsers(
 	ctx context.Context,
@@ -67,7 +231,9 @@ func (r Repository) FindUsers(
 ) ([]query.User, error) {
 	var users []query.User
 
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	reader := r.options.reader(ctx, r.db)
+
+	err := reader.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		sqlUsers, err := findUsers(ctx, tx, filter)
 		if err != nil {
 			return fmt.Errorf("find users query: %w", err)
@@ -77,8 +243,9 @@ func (r Repository) FindUsers(
 
 		for _, u := range sqlUsers {
 			users = append(users, query.User{
-				ID:    u.ID,
-				Email: u.Email,
+				ID:      u.ID,
+				Email:   u.Email,
+				Version: u.Version,
 			})
 		}
 
@@ -91,10 +258,44 @@ func (r Repository) FindUsers(
 	return users, nil
 }
 
+// UsersPage queries the PostgreSQL database for a page
+// of users ordered by ID.
+func (r Repository) UsersPage(
+	ctx context.Context,
+	after uuid.UUID,
+	limit int,
+) ([]query.User, error) {
+	var sqlUsers []*User
+
+	err := r.options.reader(ctx, r.db).
+		WithContext(ctx).
+		Where("user_id > ?", after.String()).
+		Order("user_id").
+		Limit(limit).
+		Find(&sqlUsers).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute users page query: %w", err)
+	}
+
+	users := make([]query.User, 0, len(sqlUsers))
+
+	for _, u := range sqlUsers {
+		users = append(users, query.User{
+			ID:      u.ID,
+			Email:   u.Email,
+			Version: u.Version,
+		})
+	}
+
+	return users, nil
+}
+
 func (Repository) marshalUser(u *user.User) (*User, error) {
 	psqlUser := &User{
-		ID:    u.ID(),
-		Email: u.Email(),
+		ID:      u.ID(),
+		Email:   u.Email(),
+		Version: u.Version(),
 	}
 
 	err := validate.Struct(psqlUser)
@@ -113,7 +314,9 @@ func (r Repository) GetUserByID(
 ) (query.User, error) {
 	var u query.User
 
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	reader := r.options.reader(ctx, r.db)
+
+	err := reader.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		sqlUsers, err := findUsers(ctx, tx, user.Filter{ID: id})
 		if err != nil {
 			return fmt.Errorf("find users query: %w", err)
@@ -124,8 +327,9 @@ func (r Repository) GetUserByID(
 		}
 
 		u = query.User{
-			ID:    sqlUsers[0].ID,
-			Email: sqlUsers[0].Email,
+			ID:      sqlUsers[0].ID,
+			Email:   sqlUsers[0].Email,
+			Version: sqlUsers[0].Version,
 		}
 
 		return nil
@@ -143,6 +347,10 @@ func createUser(
 	u *User,
 ) error {
 	err := db.WithContext(ctx).Create(u).Error
+	if isUniqueViolation(err) {
+		return errors.NewAlreadyExistsError("user")
+	}
+
 	if err != nil {
 		return fmt.Errorf("execute create user query: %w", err)
 	}
@@ -150,6 +358,215 @@ func createUser(
 	return nil
 }
 
+// findStoredUsers returns the users, deleted or not, sharing
+// an id or an email with the given ones.
+func findStoredUsers(
+	ctx context.Context,
+	db *gorm.DB,
+	users []*user.User,
+) ([]user.StoredUser, error) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	var (
+		ids    = make([]string, 0, len(users))
+		emails = make([]string, 0, len(users))
+	)
+
+	for _, u := range users {
+		ids = append(ids, u.ID().String())
+		emails = append(emails, u.Email())
+	}
+
+	var sqlUsers []*User
+
+	err := db.WithContext(ctx).
+		Unscoped().
+		Where("user_id IN ? OR email IN ?", ids, emails).
+		Find(&sqlUsers).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute find stored users query: %w", err)
+	}
+
+	stored := make([]user.StoredUser, 0, len(sqlUsers))
+
+	for _, u := range sqlUsers {
+		stored = append(stored, user.StoredUser{
+			ID:      u.ID,
+			Email:   u.Email,
+			Version: u.Version,
+			Deleted: u.DeletedAt.Valid,
+		})
+	}
+
+	return stored, nil
+}
+
+// insertUsers inserts the users with multi row inserts.
+func insertUsers(
+	ctx context.Context,
+	db *gorm.DB,
+	users []*user.User,
+) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	psqlUsers := make([]*User, 0, len(users))
+
+	for _, u := range users {
+		psqlUsers = append(psqlUsers, &User{
+			ID:      u.ID(),
+			Email:   u.Email(),
+			Version: u.Version(),
+		})
+	}
+
+	err := db.WithContext(ctx).
+		CreateInBatches(psqlUsers, insertBatchSize).
+		Error
+	if isUniqueViolation(err) {
+		return errors.NewAlreadyExistsError("user")
+	}
+
+	if err != nil {
+		return fmt.Errorf("execute insert users query: %w", err)
+	}
+
+	return nil
+}
+
+func getUserForUpdate(
+	ctx context.Context,
+	db *gorm.DB,
+	id uuid.UUID,
+) (*User, error) {
+	var users []*User
+
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", id.String()).
+		Limit(1).
+		Find(&users).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute select user query: %w", err)
+	}
+
+	if len(users) == 0 {
+		return nil, errors.NewNotFoundError("user")
+	}
+
+	return users[0], nil
+}
+
+// updateUser persists the user if its version is still the stored
+// one and increments the stored version.
+func updateUser(
+	ctx context.Context,
+	db *gorm.DB,
+	u *User,
+) error {
+	res := db.WithContext(ctx).
+		Model(&User{}).
+		Where("user_id = ? AND version = ?", u.ID.String(), u.Version).
+		Updates(map[string]any{
+			"email":   u.Email,
+			"version": gorm.Expr("version + 1"),
+		})
+	if isUniqueViolation(res.Error) {
+		return errors.NewAlreadyExistsError("user")
+	}
+
+	if res.Error != nil {
+		return fmt.Errorf("execute update user query: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.NewFailedPreconditionError("user version mismatch")
+	}
+
+	return nil
+}
+
+// updateUsers persists the users whose version is still the stored
+// one with multi row updates and increments their stored versions.
+func updateUsers(
+	ctx context.Context,
+	db *gorm.DB,
+	users []*user.User,
+) error {
+	for start := 0; start < len(users); start += insertBatchSize {
+		end := start + insertBatchSize
+		if end > len(users) {
+			end = len(users)
+		}
+
+		batch := users[start:end]
+
+		var (
+			values = make([]string, 0, len(batch))
+			args   = make([]any, 0, 3*len(batch))
+		)
+
+		for _, u := range batch {
+			values = append(values, "(?::uuid, ?, ?::bigint)")
+			args = append(args, u.ID().String(), u.Email(), u.Version())
+		}
+
+		res := db.WithContext(ctx).Exec(
+			"UPDATE users AS u "+
+				"SET email = v.email, version = u.version + 1 "+
+				"FROM (VALUES "+strings.Join(values, ", ")+") "+
+				"AS v (user_id, email, version) "+
+				"WHERE u.user_id = v.user_id "+
+				"AND u.version = v.version "+
+				"AND u.deleted_at IS NULL",
+			args...,
+		)
+		if isUniqueViolation(res.Error) {
+			return errors.NewAlreadyExistsError("user")
+		}
+
+		if res.Error != nil {
+			return fmt.Errorf("execute update users query: %w", res.Error)
+		}
+
+		if res.RowsAffected != int64(len(batch)) {
+			return errors.NewFailedPreconditionError("user version mismatch")
+		}
+	}
+
+	return nil
+}
+
+// deleteUser soft deletes the user if its version is still the
+// stored one and increments the stored version.
+func deleteUser(
+	ctx context.Context,
+	db *gorm.DB,
+	u *User,
+) error {
+	res := db.WithContext(ctx).
+		Model(&User{}).
+		Where("user_id = ? AND version = ?", u.ID.String(), u.Version).
+		Updates(map[string]any{
+			"deleted_at": gorm.Expr("now()"),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("execute delete user query: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.NewFailedPreconditionError("user version mismatch")
+	}
+
+	return nil
+}
+
 func findUsers(
 	ctx context.Context,
 	db *gorm.DB,
@@ -6,6 +6,7 @@ import (
 
 	"github.com/matryer/is"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/usertest"
 
 	"github.com/purposeinplay/go-commons/psqltest"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
@@ -21,8 +22,9 @@ func TestUserRepository(t *testing.T) {
 		ctx      = context.Background()
 		i        = is.New(t)
 		mockUser = psql.User{
-			ID:    uuid.New(),
-			Email: "test@test.com",
+			ID:      uuid.New(),
+			Email:   "test@test.com",
+			Version: 1,
 		}
 	)
 
@@ -67,8 +69,9 @@ func TestUserRepository(t *testing.T) {
 		i.NoErr(err)
 
 		i.Equal(query.User{
-			ID:    mockUser.ID,
-			Email: mockUser.Email,
+			ID:      mockUser.ID,
+			Email:   mockUser.Email,
+			Version: mockUser.Version,
 		}, us)
 	})
 
@@ -93,6 +96,83 @@ func TestUserRepository(t *testing.T) {
 		i.NoErr(err)
 
 		assertUserInDB(t, db, newUser)
+		assertOutboxEvents(t, db, newUser.ID(), user.EventNameUserCreated)
+	})
+
+	t.Run("SuccessChangeEmail", func(t *testing.T) {
+		i := i.New(t)
+
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: psqltest.NewTransactionTestingDB(t),
+		}), &gorm.Config{})
+		i.NoErr(err)
+
+		r := psql.NewUserRepository(
+			db,
+		)
+
+		newEmail := t.Name() + "@test.com"
+
+		err = r.UpdateUser(
+			ctx,
+			mockUser.ID,
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.ChangeEmail(newEmail)
+			},
+		)
+		i.NoErr(err)
+
+		assertUserInDB(t, db, user.UnmarshalFromDatabase(
+			mockUser.ID,
+			newEmail,
+			mockUser.Version+1,
+		))
+		assertOutboxEvents(
+			t,
+			db,
+			mockUser.ID,
+			user.EventNameUserEmailChanged,
+		)
+	})
+
+	t.Run("UpdateUserNotFound", func(t *testing.T) {
+		i := i.New(t)
+
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: psqltest.NewTransactionTestingDB(t),
+		}), &gorm.Config{})
+		i.NoErr(err)
+
+		r := psql.NewUserRepository(
+			db,
+		)
+
+		err = r.UpdateUser(
+			ctx,
+			uuid.New(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, nil
+			},
+		)
+
+		var appErr *errors.Error
+
+		i.True(errors.As(err, &appErr))
+
+		i.Equal(errors.ErrorTypeNotFound, appErr.Type())
+	})
+}
+
+func TestUserRepositoryContract(t *testing.T) {
+	usertest.TestRepository(t, func(t *testing.T) usertest.Repository {
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: psqltest.NewTransactionTestingDB(t),
+		}), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("open db: %s", err)
+		}
+
+		return psql.NewUserRepository(db)
 	})
 }
 
@@ -132,7 +212,36 @@ func assertUserInDB(t *testing.T, db *gorm.DB, acc *user.User) {
 	i.Equal(user.UnmarshalFromDatabase(
 		dbAcc.ID,
 		dbAcc.Email,
+		dbAcc.Version,
 	),
 		acc,
 	)
 }
+
+func assertOutboxEvents(
+	t *testing.T,
+	db *gorm.DB,
+	aggregateID uuid.UUID,
+	eventTypes ...string,
+) {
+	t.Helper()
+
+	i := is.New(t)
+
+	var msgs []*psql.OutboxMessage
+
+	err := db.
+		Where("aggregate_id = ?", aggregateID.String()).
+		Order("outbox_id").
+		Find(&msgs).
+		Error
+	i.NoErr(err)
+
+	types := make([]string, 0, len(msgs))
+
+	for _, m := range msgs {
+		types = append(types, m.EventType)
+	}
+
+	i.Equal(eventTypes, types)
+}
@@ -0,0 +1,449 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/webhooks"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	_ webhooks.Store                      = (*WebhookRepository)(nil)
+	_ webhook.Repository                  = (*WebhookRepository)(nil)
+	_ query.WebhookSubscriptionsReadModel = (*WebhookRepository)(nil)
+	_ query.WebhookDeliveriesReadModel    = (*WebhookRepository)(nil)
+)
+
+// WebhookSubscription represents the webhook subscription model
+// in the PostgreSQL database.
+type WebhookSubscription struct {
+	ID         uuid.UUID      `validate:"required" gorm:"primaryKey;column:webhook_subscription_id"`
+	URL        string         `validate:"required,url"`
+	EventTypes pq.StringArray `validate:"required,min=1" gorm:"type:text[]"`
+	Secret     string         `validate:"required"`
+	CreatedAt  time.Time
+	DeletedAt  gorm.DeletedAt
+}
+
+// TableName satisfies the gorm.Tabler interface.
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDelivery represents the webhook delivery model
+// in the PostgreSQL database.
+type WebhookDelivery struct {
+	ID             uuid.UUID `validate:"required" gorm:"primaryKey;column:webhook_delivery_id"`
+	SubscriptionID uuid.UUID `validate:"required" gorm:"column:webhook_subscription_id"`
+	EventID        int64
+	EventType      string `validate:"required"`
+	Payload        []byte `gorm:"type:jsonb"`
+	OccurredAt     time.Time
+	Status         string `validate:"required"`
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// TableName satisfies the gorm.Tabler interface.
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookRepository represents a PostgreSQL Webhook Repository.
+type WebhookRepository struct {
+	db      *gorm.DB
+	options repositoryOptions
+}
+
+// NewWebhookRepository creates a new PostgreSQL Webhook Repository.
+func NewWebhookRepository(
+	db *gorm.DB,
+	opts ...RepositoryOption,
+) *WebhookRepository {
+	return &WebhookRepository{
+		db:      db,
+		options: newRepositoryOptions(opts),
+	}
+}
+
+// CreateSubscription inserts a new webhook subscription into
+// the PostgreSQL database.
+func (r WebhookRepository) CreateSubscription(
+	ctx context.Context,
+	s *webhook.Subscription,
+) error {
+	psqlSub := &WebhookSubscription{
+		ID:         s.ID(),
+		URL:        s.URL(),
+		EventTypes: s.EventTypes(),
+		Secret:     s.Secret(),
+	}
+
+	err := validate.Struct(psqlSub)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	err = r.db.WithContext(ctx).Create(psqlSub).Error
+	if err != nil {
+		return fmt.Errorf("execute create subscription query: %w", err)
+	}
+
+	r.options.replicas.RecordWrite(ctx)
+
+	return nil
+}
+
+// DeleteSubscription removes a webhook subscription from the
+// PostgreSQL database. Its deliveries are kept in the log.
+func (r WebhookRepository) DeleteSubscription(
+	ctx context.Context,
+	id uuid.UUID,
+) error {
+	res := r.db.WithContext(ctx).
+		Where("webhook_subscription_id = ?", id.String()).
+		Delete(&WebhookSubscription{})
+	if res.Error != nil {
+		return fmt.Errorf("execute delete subscription query: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.NewNotFoundError("webhook subscription")
+	}
+
+	r.options.replicas.RecordWrite(ctx)
+
+	return nil
+}
+
+// MatchingSubscriptions queries the PostgreSQL database for the
+// subscriptions interested in the given event type.
+func (r WebhookRepository) MatchingSubscriptions(
+	ctx context.Context,
+	eventType string,
+) ([]*webhook.Subscription, error) {
+	var sqlSubs []*WebhookSubscription
+
+	err := r.db.WithContext(ctx).
+		Where(
+			"? = ANY(event_types) OR ? = ANY(event_types)",
+			eventType,
+			webhook.AllEvents,
+		).
+		Find(&sqlSubs).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute find subscriptions query: %w", err)
+	}
+
+	subs := make([]*webhook.Subscription, 0, len(sqlSubs))
+
+	for _, s := range sqlSubs {
+		subs = append(subs, webhook.UnmarshalSubscriptionFromDatabase(
+			s.ID,
+			s.URL,
+			s.EventTypes,
+			s.Secret,
+		))
+	}
+
+	return subs, nil
+}
+
+// CreateDeliveries inserts the deliveries into the PostgreSQL database,
+// ignoring the events already delivered to a subscription.
+func (r WebhookRepository) CreateDeliveries(
+	ctx context.Context,
+	deliveries []*webhook.Delivery,
+) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	rows := make([]*WebhookDelivery, 0, len(deliveries))
+
+	for _, d := range deliveries {
+		row, err := marshalWebhookDelivery(d)
+		if err != nil {
+			return fmt.Errorf("marshal delivery: %w", err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "webhook_subscription_id"},
+				{Name: "event_id"},
+			},
+			DoNothing: true,
+		}).
+		Create(rows).
+		Error
+	if err != nil {
+		return fmt.Errorf("execute create deliveries query: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDelivery locks the delivery row, applies the updateFn and
+// persists the updated delivery into the PostgreSQL database.
+func (r WebhookRepository) UpdateDelivery(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, d *webhook.Delivery) (*webhook.Delivery, error),
+) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []*WebhookDelivery
+
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("webhook_delivery_id = ?", id.String()).
+			Limit(1).
+			Find(&rows).
+			Error
+		if err != nil {
+			return fmt.Errorf("execute select delivery query: %w", err)
+		}
+
+		if len(rows) == 0 {
+			return errors.NewNotFoundError("webhook delivery")
+		}
+
+		d, err := unmarshalWebhookDelivery(rows[0])
+		if err != nil {
+			return fmt.Errorf("unmarshal delivery: %w", err)
+		}
+
+		updated, err := updateFn(ctx, d)
+		if err != nil {
+			return fmt.Errorf("update fn: %w", err)
+		}
+
+		row, err := marshalWebhookDelivery(updated)
+		if err != nil {
+			return fmt.Errorf("marshal delivery: %w", err)
+		}
+
+		row.CreatedAt = rows[0].CreatedAt
+
+		err = tx.Save(row).Error
+		if err != nil {
+			return fmt.Errorf("execute save delivery query: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("tx sql: %w", err)
+	}
+
+	r.options.replicas.RecordWrite(ctx)
+
+	return nil
+}
+
+// claimDeliveriesQuery leases the due pending deliveries of the active
+// subscriptions, skipping the ones already locked by other workers.
+const claimDeliveriesQuery = `
+UPDATE webhook_deliveries d SET next_attempt_at = ?
+FROM webhook_subscriptions s
+WHERE d.webhook_delivery_id IN (
+	SELECT webhook_delivery_id FROM webhook_deliveries
+	WHERE status = ? AND next_attempt_at <= ?
+	ORDER BY next_attempt_at
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+	AND s.webhook_subscription_id = d.webhook_subscription_id
+	AND s.deleted_at IS NULL
+RETURNING d.*, s.url, s.secret`
+
+// ClaimDeliveries satisfies the webhooks.Store interface.
+func (r WebhookRepository) ClaimDeliveries(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]webhooks.ClaimedDelivery, error) {
+	var (
+		now  = time.Now()
+		rows []*struct {
+			WebhookDelivery `gorm:"embedded"`
+			URL             string
+			Secret          string
+		}
+	)
+
+	err := r.db.WithContext(ctx).
+		Raw(
+			claimDeliveriesQuery,
+			now.Add(lease),
+			webhook.DeliveryStatusPending.String(),
+			now,
+			limit,
+		).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute claim deliveries query: %w", err)
+	}
+
+	claimed := make([]webhooks.ClaimedDelivery, 0, len(rows))
+
+	for _, row := range rows {
+		d, err := unmarshalWebhookDelivery(&row.WebhookDelivery)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal delivery: %w", err)
+		}
+
+		claimed = append(claimed, webhooks.ClaimedDelivery{
+			Delivery: d,
+			URL:      row.URL,
+			Secret:   row.Secret,
+		})
+	}
+
+	return claimed, nil
+}
+
+// FindWebhookSubscriptions queries the PostgreSQL database for
+// all the webhook subscriptions.
+func (r WebhookRepository) FindWebhookSubscriptions(
+	ctx context.Context,
+) ([]query.WebhookSubscription, error) {
+	var sqlSubs []*WebhookSubscription
+
+	err := r.options.reader(ctx, r.db).WithContext(ctx).
+		Order("created_at").
+		Find(&sqlSubs).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute find subscriptions query: %w", err)
+	}
+
+	subs := make([]query.WebhookSubscription, 0, len(sqlSubs))
+
+	for _, s := range sqlSubs {
+		subs = append(subs, query.WebhookSubscription{
+			ID:         s.ID,
+			URL:        s.URL,
+			EventTypes: s.EventTypes,
+			CreatedAt:  s.CreatedAt,
+		})
+	}
+
+	return subs, nil
+}
+
+// FindWebhookDeliveries queries the PostgreSQL database for
+// the newest webhook deliveries matching the filter.
+func (r WebhookRepository) FindWebhookDeliveries(
+	ctx context.Context,
+	filter webhook.DeliveryFilter,
+) ([]query.WebhookDelivery, error) {
+	const maxDeliveries = 100
+
+	session := r.options.reader(ctx, r.db).WithContext(ctx)
+
+	if !filter.SubscriptionID.IsZero() {
+		session = session.Where(
+			"webhook_subscription_id = ?",
+			filter.SubscriptionID.String(),
+		)
+	}
+
+	if filter.Status != nil {
+		session = session.Where("status = ?", filter.Status.String())
+	}
+
+	var rows []*WebhookDelivery
+
+	err := session.
+		Order("created_at DESC").
+		Limit(maxDeliveries).
+		Find(&rows).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("execute find deliveries query: %w", err)
+	}
+
+	deliveries := make([]query.WebhookDelivery, 0, len(rows))
+
+	for _, d := range rows {
+		deliveries = append(deliveries, query.WebhookDelivery{
+			ID:             d.ID,
+			SubscriptionID: d.SubscriptionID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+		})
+	}
+
+	return deliveries, nil
+}
+
+func marshalWebhookDelivery(d *webhook.Delivery) (*WebhookDelivery, error) {
+	row := &WebhookDelivery{
+		ID:             d.ID(),
+		SubscriptionID: d.SubscriptionID(),
+		EventID:        d.EventID(),
+		EventType:      d.EventType(),
+		Payload:        d.Payload(),
+		OccurredAt:     d.OccurredAt(),
+		Status:         d.Status().String(),
+		Attempts:       d.Attempts(),
+		NextAttemptAt:  d.NextAttemptAt(),
+		LastStatusCode: d.LastStatusCode(),
+		LastError:      d.LastError(),
+		DeliveredAt:    d.DeliveredAt(),
+	}
+
+	err := validate.Struct(row)
+	if err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+
+	return row, nil
+}
+
+func unmarshalWebhookDelivery(row *WebhookDelivery) (*webhook.Delivery, error) {
+	status, err := webhook.ParseDeliveryStatus(row.Status)
+	if err != nil {
+		return nil, fmt.Errorf("parse status: %w", err)
+	}
+
+	return webhook.UnmarshalDeliveryFromDatabase(
+		row.ID,
+		row.SubscriptionID,
+		row.EventID,
+		row.EventType,
+		row.Payload,
+		row.OccurredAt,
+		status,
+		row.Attempts,
+		row.NextAttemptAt,
+		row.LastStatusCode,
+		row.LastError,
+		row.DeliveredAt,
+	), nil
+}
@@ -0,0 +1,98 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/psqltest"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	var (
+		ctx = context.Background()
+		i   = is.New(t)
+	)
+
+	t.Run("DispatchAndClaim", func(t *testing.T) {
+		i := i.New(t)
+
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: psqltest.NewTransactionTestingDB(t),
+		}), &gorm.Config{})
+		i.NoErr(err)
+
+		r := psql.NewWebhookRepository(db)
+
+		sub, err := webhook.NewSubscription(
+			uuid.New(),
+			"https://example.com/webhooks",
+			[]string{"user.created"},
+			"0123456789abcdef",
+		)
+		i.NoErr(err)
+
+		i.NoErr(r.CreateSubscription(ctx, sub))
+
+		subs, err := r.MatchingSubscriptions(ctx, "user.created")
+		i.NoErr(err)
+		i.Equal(1, len(subs))
+
+		subs, err = r.MatchingSubscriptions(ctx, "user.deleted")
+		i.NoErr(err)
+		i.Equal(0, len(subs))
+
+		d, err := webhook.NewDelivery(
+			uuid.New(),
+			sub.ID(),
+			1,
+			"user.created",
+			[]byte(`{}`),
+			time.Now().Add(-time.Second),
+		)
+		i.NoErr(err)
+
+		// Dispatching the same event twice is ignored.
+		i.NoErr(r.CreateDeliveries(ctx, []*webhook.Delivery{d}))
+		i.NoErr(r.CreateDeliveries(ctx, []*webhook.Delivery{d}))
+
+		claimed, err := r.ClaimDeliveries(ctx, 10, time.Minute)
+		i.NoErr(err)
+		i.Equal(1, len(claimed))
+		i.Equal(d.ID(), claimed[0].Delivery.ID())
+		i.Equal(sub.URL(), claimed[0].URL)
+		i.Equal(sub.Secret(), claimed[0].Secret)
+
+		// The claimed delivery is leased.
+		claimed, err = r.ClaimDeliveries(ctx, 10, time.Minute)
+		i.NoErr(err)
+		i.Equal(0, len(claimed))
+
+		err = r.UpdateDelivery(
+			ctx,
+			d.ID(),
+			func(_ context.Context, d *webhook.Delivery) (*webhook.Delivery, error) {
+				d.RecordSuccess(200, time.Now())
+
+				return d, nil
+			},
+		)
+		i.NoErr(err)
+
+		status := webhook.DeliveryStatusSucceeded
+
+		deliveries, err := r.FindWebhookDeliveries(ctx, webhook.DeliveryFilter{
+			SubscriptionID: sub.ID(),
+			Status:         &status,
+		})
+		i.NoErr(err)
+		i.Equal(1, len(deliveries))
+		i.Equal(1, deliveries[0].Attempts)
+	})
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+var _ Store = (*Memory)(nil)
+
+// Memory is a Store keeping the buckets in process memory,
+// so every instance of the application limits on its own.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// fullAt is when the bucket is full again
+	// and it can be forgotten.
+	fullAt time.Time
+}
+
+// NewMemory creates a new Memory Store.
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Take satisfies the Store interface.
+func (m *Memory) Take(
+	_ context.Context,
+	key string,
+	limit Limit,
+) (Result, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	b.tokens = math.Min(
+		float64(limit.Burst),
+		b.tokens+elapsed*limit.Rate,
+	)
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	res := newResult(allowed, b.tokens, limit)
+
+	b.fullAt = now.Add(res.Reset)
+
+	return res, nil
+}
+
+// sweep forgets, at most once a minute, the buckets that are full.
+func (m *Memory) sweep(now time.Time) {
+	const sweepInterval = time.Minute
+
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.fullAt) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit configures a token bucket.
+type Limit struct {
+	// Rate is the number of tokens added to the bucket per second.
+	Rate float64
+	// Burst is the capacity of the bucket.
+	Burst int
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result describes the state of a bucket after a token was taken.
+type Result struct {
+	Allowed bool
+	// Limit is the capacity of the bucket.
+	Limit int
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryAfter is how long to wait before a token is available,
+	// zero when the request was allowed.
+	RetryAfter time.Duration
+	// Reset is how long it takes for the bucket to be full again.
+	Reset time.Duration
+}
+
+// Store keeps the token buckets.
+type Store interface {
+	// Take takes a token from the bucket identified by key,
+	// creating a full one when it doesn't exist.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// newResult builds the Result for a bucket left with the given tokens.
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     refillDuration(float64(limit.Burst)-tokens, limit.Rate),
+	}
+
+	if !allowed {
+		res.RetryAfter = refillDuration(1-tokens, limit.Rate)
+	}
+
+	return res
+}
+
+// refillDuration returns how long it takes to add the tokens
+// to a bucket.
+func refillDuration(tokens, rate float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(tokens / rate * float64(time.Second)))
+}
@@ -0,0 +1,95 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/ratelimit"
+)
+
+func TestStores(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	stores := map[string]func(t *testing.T) ratelimit.Store{
+		"Memory": func(t *testing.T) ratelimit.Store {
+			return ratelimit.NewMemory()
+		},
+		"Redis": func(t *testing.T) ratelimit.Store {
+			srv := miniredis.RunT(t)
+
+			client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+			t.Cleanup(func() {
+				_ = client.Close()
+			})
+
+			return ratelimit.NewRedis(client)
+		},
+	}
+
+	for name, newStore := range stores {
+		newStore := newStore
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("ExhaustsBurst", func(t *testing.T) {
+				i := is.New(t)
+
+				store := newStore(t)
+				limit := ratelimit.Limit{Rate: 0.01, Burst: 2}
+
+				res, err := store.Take(ctx, "a", limit)
+				i.NoErr(err)
+				i.True(res.Allowed)
+				i.Equal(2, res.Limit)
+				i.Equal(1, res.Remaining)
+				i.Equal(time.Duration(0), res.RetryAfter)
+
+				res, err = store.Take(ctx, "a", limit)
+				i.NoErr(err)
+				i.True(res.Allowed)
+				i.Equal(0, res.Remaining)
+
+				res, err = store.Take(ctx, "a", limit)
+				i.NoErr(err)
+				i.True(!res.Allowed)
+				i.Equal(0, res.Remaining)
+				i.True(res.RetryAfter > 90*time.Second)
+				i.True(res.RetryAfter <= 100*time.Second)
+				i.True(res.Reset > res.RetryAfter)
+
+				res, err = store.Take(ctx, "b", limit)
+				i.NoErr(err)
+				i.True(res.Allowed)
+			})
+
+			t.Run("Refills", func(t *testing.T) {
+				i := is.New(t)
+
+				store := newStore(t)
+				limit := ratelimit.Limit{Rate: 100, Burst: 1}
+
+				res, err := store.Take(ctx, "a", limit)
+				i.NoErr(err)
+				i.True(res.Allowed)
+
+				res, err = store.Take(ctx, "a", limit)
+				i.NoErr(err)
+				i.True(!res.Allowed)
+				i.True(res.RetryAfter <= 10*time.Millisecond)
+
+				time.Sleep(20 * time.Millisecond)
+
+				res, err = store.Take(ctx, "a", limit)
+				i.NoErr(err)
+				i.True(res.Allowed)
+			})
+		})
+	}
+}
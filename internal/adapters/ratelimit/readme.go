@@ -0,0 +1,3 @@
+// Package ratelimit implements token bucket rate limiting with the
+// buckets kept either in process memory or in Redis.
+package ratelimit
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var _ Store = (*Redis)(nil)
+
+// takeScript refills the bucket stored in the KEYS[1] hash for the
+// time elapsed since its last update and takes a token from it.
+//
+// ARGV holds the rate per second, the burst and the current time in
+// milliseconds. It returns whether a token was taken and the tokens
+// left, as a string since Lua numbers are truncated to integers.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(state[1])
+local updated_at = tonumber(state[2])
+
+if tokens == nil or updated_at == nil then
+	tokens = burst
+	updated_at = now
+end
+
+local elapsed = math.max(0, now - updated_at)
+tokens = math.min(burst, tokens + elapsed * rate / 1000)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tokens, "updated_at", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate))
+
+return {allowed, tostring(tokens)}
+`)
+
+// Redis is a Store keeping the buckets in a server speaking
+// the Redis protocol, so they are shared by every instance
+// of the application.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis creates a new Redis Store using the given client,
+// the keys of the buckets are prefixed with "ratelimit:".
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{
+		client: client,
+		prefix: "ratelimit:",
+	}
+}
+
+// Take satisfies the Store interface.
+func (r *Redis) Take(
+	ctx context.Context,
+	key string,
+	limit Limit,
+) (Result, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	reply, err := takeScript.Run(
+		r.client.WithContext(ctx),
+		[]string{r.prefix + key},
+		limit.Rate,
+		limit.Burst,
+		now,
+	).Result()
+	if err != nil {
+		return Result{}, fmt.Errorf("run take script: %w", err)
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected take script reply %v", reply)
+	}
+
+	allowed, _ := values[0].(int64)
+	rawTokens, _ := values[1].(string)
+
+	tokens, err := strconv.ParseFloat(rawTokens, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("parse tokens: %w", err)
+	}
+
+	return newResult(allowed == 1, math.Max(0, tokens), limit), nil
+}
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	sqlfiles "github.com/purposeinplay/go-starter-grpc-gateway/sql"
+
+	// Registers the pure Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// DriverName is the value of DB.Driver selecting SQLite.
+const DriverName = "sqlite"
+
+// timeLayout is used to store the times as text which,
+// unlike the driver default, sorts chronologically.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Connect opens the SQLite database stored in the DB.NAME file,
+// applying the migrations when DB.AUTOMIGRATE is set.
+func Connect(cfg *config.Config) (*sql.DB, error) {
+	if cfg.DB.NAME == "" {
+		return nil, errors.New("empty database file name")
+	}
+
+	db, err := Open(cfg.DB.NAME)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.DB.Automigrate {
+		err = Migrate(db)
+		if err != nil {
+			_ = db.Close()
+
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+	}
+
+	return db, nil
+}
+
+// Open opens the SQLite database stored in the given file.
+func Open(name string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", name)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	// SQLite allows a single writer, using a single connection
+	// serializes the transactions instead of failing them with
+	// "database is locked" and keeps the pragmas below in effect.
+	db.SetMaxOpenConns(1)
+
+	for _, pragma := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA busy_timeout = 5000",
+	} {
+		_, err = db.Exec(pragma)
+		if err != nil {
+			_ = db.Close()
+
+			return nil, fmt.Errorf("execute %q: %w", pragma, err)
+		}
+	}
+
+	return db, nil
+}
+
+// Migrate applies the embedded SQLite migrations.
+func Migrate(db *sql.DB) error {
+	source, err := iofs.New(sqlfiles.SQLiteMigrations, "sqlite")
+	if err != nil {
+		return fmt.Errorf("new migrations source: %w", err)
+	}
+
+	driver, err := migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("new migrate sqlite driver: %w", err)
+	}
+
+	migrator, err := migrate.NewWithInstance("iofs", source, DriverName, driver)
+	if err != nil {
+		return fmt.Errorf("new database migrate instance: %w", err)
+	}
+
+	err = migrator.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(timeLayout, s)
+}
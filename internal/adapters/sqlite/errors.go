@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"modernc.org/sqlite"
+)
+
+// Extended SQLite result codes returned when a
+// unique constraint is violated.
+const (
+	constraintPrimaryKeyCode = 1555
+	constraintUniqueCode     = 2067
+)
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code() == constraintUniqueCode ||
+		sqliteErr.Code() == constraintPrimaryKeyCode
+}
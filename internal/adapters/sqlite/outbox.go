@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+)
+
+var _ outbox.Store = (*OutboxStore)(nil)
+
+// OutboxStore represents a SQLite outbox.Store.
+type OutboxStore struct {
+	db *sql.DB
+}
+
+// NewOutboxStore creates a new SQLite outbox.Store.
+func NewOutboxStore(db *sql.DB) *OutboxStore {
+	return &OutboxStore{db: db}
+}
+
+// claimQuery leases the oldest unpublished message of every aggregate.
+// SQLite serializes the writers so no row locking is needed.
+const claimQuery = `
+UPDATE outbox SET next_attempt_at = ?
+WHERE outbox_id IN (
+	SELECT o.outbox_id FROM outbox o
+	WHERE o.published_at IS NULL
+		AND o.next_attempt_at <= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM outbox prev
+			WHERE prev.aggregate_id = o.aggregate_id
+				AND prev.published_at IS NULL
+				AND prev.outbox_id < o.outbox_id
+		)
+	ORDER BY o.outbox_id
+	LIMIT ?
+)
+RETURNING outbox_id, aggregate_type, aggregate_id, event_type,
+	payload, attempts, created_at`
+
+// Claim satisfies the outbox.Store interface.
+func (s OutboxStore) Claim(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]outbox.Message, error) {
+	now := time.Now()
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		claimQuery,
+		formatTime(now.Add(lease)),
+		formatTime(now),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("execute claim query: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var msgs []outbox.Message
+
+	for rows.Next() {
+		var (
+			msg       outbox.Message
+			payload   string
+			createdAt string
+		)
+
+		err := rows.Scan(
+			&msg.ID,
+			&msg.AggregateType,
+			&msg.AggregateID,
+			&msg.EventType,
+			&payload,
+			&msg.Attempts,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+
+		msg.Payload = []byte(payload)
+
+		msg.CreatedAt, err = parseTime(createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse created at: %w", err)
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate messages: %w", err)
+	}
+
+	// RETURNING does not preserve the order of the sub query.
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].ID < msgs[j].ID
+	})
+
+	return msgs, nil
+}
+
+// MarkPublished satisfies the outbox.Store interface.
+func (s OutboxStore) MarkPublished(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"UPDATE outbox SET published_at = ? WHERE outbox_id = ?",
+		formatTime(time.Now()),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("execute mark published query: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed satisfies the outbox.Store interface.
+func (s OutboxStore) MarkFailed(
+	ctx context.Context,
+	id int64,
+	retryAt time.Time,
+	cause error,
+) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"UPDATE outbox SET attempts = attempts + 1, "+
+			"next_attempt_at = ?, last_error = ? WHERE outbox_id = ?",
+		formatTime(retryAt),
+		cause.Error(),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("execute mark failed query: %w", err)
+	}
+
+	return nil
+}
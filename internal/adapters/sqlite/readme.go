@@ -0,0 +1,6 @@
+// Package sqlite implements the SQLite database features.
+//
+// It uses a pure Go driver, so the binary can be built without cgo,
+// and is meant for edge deployments and local development where
+// running PostgreSQL is not worth it.
+package sqlite
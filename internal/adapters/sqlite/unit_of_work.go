@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+)
+
+var _ command.UnitOfWork = (*UnitOfWork)(nil)
+
+// txContextKey is the key under which the running unitOfWorkTx
+// is stored in the context passed to the unit of work function.
+type txContextKey struct{}
+
+// unitOfWorkTx holds the running transaction and the functions
+// the repositories want to run once it is committed.
+type unitOfWorkTx struct {
+	tx          *sql.Tx
+	savepoints  int
+	afterCommit []func()
+}
+
+// UnitOfWork runs functions in a SQLite transaction.
+//
+// Nested units of work, and the repository calls made in one,
+// run in a savepoint of the outer transaction.
+//
+// There are no webhooks in SQLite, the Webhooks repository
+// passed to the functions is nil.
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork creates a new SQLite UnitOfWork.
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do satisfies the command.UnitOfWork interface.
+func (u *UnitOfWork) Do(
+	ctx context.Context,
+	fn func(ctx context.Context, repos command.Repositories) error,
+) error {
+	if _, ok := ctx.Value(txContextKey{}).(*unitOfWorkTx); ok {
+		err := transaction(ctx, u.db, func(*sql.Tx) error {
+			return u.run(ctx, fn)
+		})
+		if err != nil {
+			return fmt.Errorf("savepoint: %w", err)
+		}
+
+		return nil
+	}
+
+	uowTx := &unitOfWorkTx{}
+
+	err := transaction(ctx, u.db, func(tx *sql.Tx) error {
+		uowTx.tx = tx
+
+		return u.run(context.WithValue(ctx, txContextKey{}, uowTx), fn)
+	})
+	if err != nil {
+		return fmt.Errorf("tx sql: %w", err)
+	}
+
+	for _, f := range uowTx.afterCommit {
+		f()
+	}
+
+	return nil
+}
+
+func (u *UnitOfWork) run(
+	ctx context.Context,
+	fn func(ctx context.Context, repos command.Repositories) error,
+) error {
+	return fn(ctx, command.Repositories{
+		Users: NewUserRepository(u.db),
+	})
+}
+
+// transaction runs fn in a new transaction, or in a savepoint of
+// the unit of work carried by ctx.
+func transaction(
+	ctx context.Context,
+	db *sql.DB,
+	fn func(tx *sql.Tx) error,
+) error {
+	if uowTx, ok := ctx.Value(txContextKey{}).(*unitOfWorkTx); ok {
+		return savepoint(ctx, uowTx, fn)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// savepoint runs fn in a savepoint of the unit of work transaction,
+// forgetting the functions fn added to run after commit when
+// it fails.
+func savepoint(
+	ctx context.Context,
+	uowTx *unitOfWorkTx,
+	fn func(tx *sql.Tx) error,
+) error {
+	uowTx.savepoints++
+	defer func() { uowTx.savepoints-- }()
+
+	var (
+		name        = fmt.Sprintf("sp%d", uowTx.savepoints)
+		afterCommit = len(uowTx.afterCommit)
+	)
+
+	_, err := uowTx.tx.ExecContext(ctx, "SAVEPOINT "+name)
+	if err != nil {
+		return fmt.Errorf("savepoint: %w", err)
+	}
+
+	err = fn(uowTx.tx)
+	if err != nil {
+		uowTx.afterCommit = uowTx.afterCommit[:afterCommit]
+
+		_, _ = uowTx.tx.ExecContext(ctx, "ROLLBACK TO "+name)
+		_, _ = uowTx.tx.ExecContext(ctx, "RELEASE "+name)
+
+		return err
+	}
+
+	_, err = uowTx.tx.ExecContext(ctx, "RELEASE "+name)
+	if err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// afterCommit runs f once the unit of work carried by ctx is
+// committed, or right away when ctx carries none.
+func afterCommit(ctx context.Context, f func()) {
+	uowTx, ok := ctx.Value(txContextKey{}).(*unitOfWorkTx)
+	if !ok {
+		f()
+
+		return
+	}
+
+	uowTx.afterCommit = append(uowTx.afterCommit, f)
+}
@@ -0,0 +1,97 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/sqlite"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+var errAbort = errors.New("abort")
+
+func TestUnitOfWork(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newUser := func() *user.User {
+		return user.MustNew(uuid.New(), uuid.New().String()+"@email.com")
+	}
+
+	t.Run("NestedRollsBackToSavepoint", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			i            = is.New(t)
+			db           = newDB(t)
+			uow          = sqlite.NewUnitOfWork(db)
+			users        = sqlite.NewUserRepository(db)
+			outer, inner = newUser(), newUser()
+		)
+
+		err := uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+			err := repos.Users.CreateUser(ctx, outer)
+			if err != nil {
+				return err
+			}
+
+			err = uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+				err := repos.Users.CreateUser(ctx, inner)
+				if err != nil {
+					return err
+				}
+
+				return errAbort
+			})
+			if !errors.Is(err, errAbort) {
+				return err
+			}
+
+			return nil
+		})
+		i.NoErr(err)
+
+		_, err = users.GetUserByID(ctx, outer.ID())
+		i.NoErr(err)
+		i.Equal(0, len(outer.Events()))
+
+		_, err = users.GetUserByID(ctx, inner.ID())
+		i.True(err != nil)
+		i.Equal(1, len(inner.Events()))
+	})
+
+	t.Run("RollsBackOnError", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			i     = is.New(t)
+			db    = newDB(t)
+			uow   = sqlite.NewUnitOfWork(db)
+			users = sqlite.NewUserRepository(db)
+			u     = newUser()
+		)
+
+		err := uow.Do(ctx, func(ctx context.Context, repos command.Repositories) error {
+			err := repos.Users.CreateUser(ctx, u)
+			if err != nil {
+				return err
+			}
+
+			if len(u.Events()) != 1 {
+				return errors.New("events popped before commit")
+			}
+
+			return errAbort
+		})
+		i.True(errors.Is(err, errAbort))
+
+		_, err = users.GetUserByID(ctx, u.ID())
+		i.True(err != nil)
+		i.Equal(1, len(u.Events()))
+	})
+}
@@ -0,0 +1,483 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+var (
+	_ user.Repository          = (*UserRepository)(nil)
+	_ user.Importer            = (*UserRepository)(nil)
+	_ query.UserByIDReadModel  = (*UserRepository)(nil)
+	_ query.FindUsersReadModel = (*UserRepository)(nil)
+	_ query.UsersPageReadModel = (*UserRepository)(nil)
+)
+
+// UserRepository represents a SQLite User Repository.
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new SQLite User Repository.
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// CreateUser inserts a new user into the SQLite database.
+func (r UserRepository) CreateUser(ctx context.Context, u *user.User) error {
+	err := transaction(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO users (user_id, email, version) VALUES (?, ?, ?)",
+			u.ID().String(),
+			u.Email(),
+			u.Version(),
+		)
+		if isUniqueViolation(err) {
+			return errors.NewAlreadyExistsError("user")
+		}
+
+		if err != nil {
+			return fmt.Errorf("execute create user query: %w", err)
+		}
+
+		err = saveUserEvents(ctx, tx, u)
+		if err != nil {
+			return fmt.Errorf("save user events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("tx sql: %w", err)
+	}
+
+	afterCommit(ctx, func() { popUserEvents(u) })
+
+	return nil
+}
+
+// UpdateUser applies the updateFn to the user with the given id
+// and persists the result into the SQLite database.
+func (r UserRepository) UpdateUser(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, u *user.User) (*user.User, error),
+) error {
+	var updatedUser *user.User
+
+	err := transaction(ctx, r.db, func(tx *sql.Tx) error {
+		users, err := findUsers(ctx, tx, user.Filter{ID: id})
+		if err != nil {
+			return fmt.Errorf("find users query: %w", err)
+		}
+
+		if len(users) == 0 {
+			return errors.NewNotFoundError("user")
+		}
+
+		updatedUser, err = updateFn(
+			ctx,
+			user.UnmarshalFromDatabase(
+				users[0].ID,
+				users[0].Email,
+				users[0].Version,
+			),
+		)
+		if err != nil {
+			return fmt.Errorf("update fn: %w", err)
+		}
+
+		err = updateUser(ctx, tx, updatedUser)
+		if err != nil {
+			return fmt.Errorf("persist user query: %w", err)
+		}
+
+		err = saveUserEvents(ctx, tx, updatedUser)
+		if err != nil {
+			return fmt.Errorf("save user events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("tx sql: %w", err)
+	}
+
+	afterCommit(ctx, func() { popUserEvents(updatedUser) })
+
+	return nil
+}
+
+// ImportUsers persists the users planned by user.PlanImport into
+// the SQLite database, inserting the new ones with a single query.
+func (r UserRepository) ImportUsers(
+	ctx context.Context,
+	users []*user.User,
+	mode user.ImportMode,
+) ([]user.ImportResult, error) {
+	var plan user.ImportPlan
+
+	err := transaction(ctx, r.db, func(tx *sql.Tx) error {
+		stored, err := findStoredUsers(ctx, tx, users)
+		if err != nil {
+			return fmt.Errorf("find stored users query: %w", err)
+		}
+
+		plan = user.PlanImport(users, stored, mode)
+
+		err = insertUsers(ctx, tx, plan.Create)
+		if err != nil {
+			return fmt.Errorf("insert users query: %w", err)
+		}
+
+		for _, u := range plan.Update {
+			err = updateUser(ctx, tx, u)
+			if err != nil {
+				return fmt.Errorf("update user query: %w", err)
+			}
+		}
+
+		for _, batch := range [][]*user.User{plan.Create, plan.Update} {
+			for _, u := range batch {
+				err = saveUserEvents(ctx, tx, u)
+				if err != nil {
+					return fmt.Errorf("save user events: %w", err)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tx sql: %w", err)
+	}
+
+	afterCommit(ctx, func() {
+		popUserEvents(plan.Create...)
+		popUserEvents(plan.Update...)
+	})
+
+	return plan.Results, nil
+}
+
+// FindUsers queries the SQLite database for users.
+func (r UserRepository) FindUsers(
+	ctx context.Context,
+	filter user.Filter,
+) ([]query.User, error) {
+	users, err := findUsers(ctx, r.db, filter)
+	if err != nil {
+		return nil, fmt.Errorf("find users query: %w", err)
+	}
+
+	return users, nil
+}
+
+// UsersPage queries the SQLite database for a page
+// of users ordered by ID.
+func (r UserRepository) UsersPage(
+	ctx context.Context,
+	after uuid.UUID,
+	limit int,
+) ([]query.User, error) {
+	users, err := usersPage(ctx, r.db, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("users page query: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUserByID queries the SQLite database for a
+// user with the given ID.
+func (r UserRepository) GetUserByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (query.User, error) {
+	users, err := findUsers(ctx, r.db, user.Filter{ID: id})
+	if err != nil {
+		return query.User{}, fmt.Errorf("find users query: %w", err)
+	}
+
+	if len(users) == 0 {
+		return query.User{}, errors.NewNotFoundError("user")
+	}
+
+	return users[0], nil
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, q string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, q string, args ...any) (*sql.Rows, error)
+}
+
+// updateUser persists the user if its version is still the stored
+// one and increments the stored version.
+func updateUser(ctx context.Context, tx *sql.Tx, u *user.User) error {
+	var (
+		now       = formatTime(time.Now())
+		deletedAt *string
+	)
+
+	if u.IsDeleted() {
+		deletedAt = &now
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		"UPDATE users SET email = ?, updated_at = ?, deleted_at = ?, "+
+			"version = version + 1 "+
+			"WHERE user_id = ? AND version = ?",
+		u.Email(),
+		now,
+		deletedAt,
+		u.ID().String(),
+		u.Version(),
+	)
+	if isUniqueViolation(err) {
+		return errors.NewAlreadyExistsError("user")
+	}
+
+	if err != nil {
+		return fmt.Errorf("execute update user query: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if n == 0 {
+		return errors.NewFailedPreconditionError("user version mismatch")
+	}
+
+	return nil
+}
+
+// findStoredUsers returns the users, deleted or not, sharing
+// an id or an email with the given ones.
+func findStoredUsers(
+	ctx context.Context,
+	q querier,
+	users []*user.User,
+) ([]user.StoredUser, error) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	var (
+		ids    = make([]any, 0, len(users))
+		emails = make([]any, 0, len(users))
+	)
+
+	for _, u := range users {
+		ids = append(ids, u.ID().String())
+		emails = append(emails, u.Email())
+	}
+
+	rows, err := q.QueryContext(
+		ctx,
+		"SELECT user_id, email, version, deleted_at IS NOT NULL "+
+			"FROM users WHERE user_id IN ("+placeholders(len(ids))+") "+
+			"OR email IN ("+placeholders(len(emails))+")",
+		append(ids, emails...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("execute find stored users query: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var stored []user.StoredUser
+
+	for rows.Next() {
+		var s user.StoredUser
+
+		err = rows.Scan(&s.ID, &s.Email, &s.Version, &s.Deleted)
+		if err != nil {
+			return nil, fmt.Errorf("scan stored user: %w", err)
+		}
+
+		stored = append(stored, s)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate stored users: %w", err)
+	}
+
+	return stored, nil
+}
+
+// insertUsers inserts the users with a single query.
+func insertUsers(ctx context.Context, tx *sql.Tx, users []*user.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	var (
+		values = make([]string, 0, len(users))
+		args   = make([]any, 0, 3*len(users))
+	)
+
+	for _, u := range users {
+		values = append(values, "(?, ?, ?)")
+		args = append(args, u.ID().String(), u.Email(), u.Version())
+	}
+
+	_, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO users (user_id, email, version) VALUES "+
+			strings.Join(values, ", "),
+		args...,
+	)
+	if isUniqueViolation(err) {
+		return errors.NewAlreadyExistsError("user")
+	}
+
+	if err != nil {
+		return fmt.Errorf("execute insert users query: %w", err)
+	}
+
+	return nil
+}
+
+// placeholders returns n comma separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func findUsers(
+	ctx context.Context,
+	q querier,
+	filter user.Filter,
+) ([]query.User, error) {
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		args       []any
+	)
+
+	if !filter.ID.IsZero() {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.ID.String())
+	}
+
+	if filter.Email != nil {
+		conditions = append(conditions, "email = ?")
+		args = append(args, *filter.Email)
+	}
+
+	rows, err := q.QueryContext(
+		ctx,
+		"SELECT user_id, email, version FROM users WHERE "+
+			strings.Join(conditions, " AND ")+
+			" ORDER BY rowid",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("execute find users query: %w", err)
+	}
+
+	return scanUsers(rows)
+}
+
+func usersPage(
+	ctx context.Context,
+	q querier,
+	after uuid.UUID,
+	limit int,
+) ([]query.User, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		"SELECT user_id, email, version FROM users "+
+			"WHERE deleted_at IS NULL AND user_id > ? "+
+			"ORDER BY user_id LIMIT ?",
+		after.String(),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("execute users page query: %w", err)
+	}
+
+	return scanUsers(rows)
+}
+
+// scanUsers reads the users from the rows and closes them.
+func scanUsers(rows *sql.Rows) ([]query.User, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	users := make([]query.User, 0)
+
+	for rows.Next() {
+		var u query.User
+
+		err := rows.Scan(&u.ID, &u.Email, &u.Version)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+
+		users = append(users, u)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+// saveUserEvents stores the events recorded by the user in the
+// outbox, they are cleared with popUserEvents once committed.
+func saveUserEvents(ctx context.Context, tx *sql.Tx, u *user.User) error {
+	now := formatTime(time.Now())
+
+	for _, e := range u.Events() {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshal %s event: %w", e.EventName(), err)
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO outbox "+
+				"(aggregate_type, aggregate_id, event_type, payload, "+
+				"created_at, next_attempt_at) "+
+				"VALUES (?, ?, ?, ?, ?, ?)",
+			user.AggregateType,
+			u.ID().String(),
+			e.EventName(),
+			string(payload),
+			now,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("execute create outbox message query: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// popUserEvents clears the events of the users
+// saved by a committed transaction.
+func popUserEvents(users ...*user.User) {
+	for _, u := range users {
+		u.PopEvents()
+	}
+}
@@ -0,0 +1,91 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/sqlite"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/usertest"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"go.uber.org/zap"
+)
+
+func TestUserRepository(t *testing.T) {
+	t.Parallel()
+
+	usertest.TestRepository(t, func(t *testing.T) usertest.Repository {
+		return sqlite.NewUserRepository(newDB(t))
+	})
+}
+
+func TestOutboxStore(t *testing.T) {
+	t.Parallel()
+
+	var (
+		i         = is.New(t)
+		ctx       = context.Background()
+		db        = newDB(t)
+		r         = sqlite.NewUserRepository(db)
+		publisher = outbox.NewMemoryPublisher()
+		u         = user.MustNew(uuid.New(), "user@email.com")
+	)
+
+	i.NoErr(r.CreateUser(ctx, u))
+
+	err := r.UpdateUser(
+		ctx,
+		u.ID(),
+		func(_ context.Context, u *user.User) (*user.User, error) {
+			return u, u.Delete()
+		},
+	)
+	i.NoErr(err)
+
+	relay := outbox.NewRelay(
+		zap.NewNop(),
+		sqlite.NewOutboxStore(db),
+		publisher,
+		outbox.WithLease(time.Minute),
+	)
+
+	for {
+		published, err := relay.RelayBatch(ctx)
+		i.NoErr(err)
+
+		if published == 0 {
+			break
+		}
+	}
+
+	msgs := publisher.Messages()
+	i.Equal(2, len(msgs))
+	i.Equal(user.EventNameUserCreated, msgs[0].EventType)
+	i.Equal(user.EventNameUserDeleted, msgs[1].EventType)
+	i.Equal(u.ID().String(), msgs[1].AggregateID)
+}
+
+func newDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	err = sqlite.Migrate(db)
+	if err != nil {
+		t.Fatalf("migrate: %s", err)
+	}
+
+	return db
+}
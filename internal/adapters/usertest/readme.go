@@ -0,0 +1,7 @@
+// Package usertest implements a contract test suite for the
+// user repositories.
+//
+// Every implementation of user.Repository and of the user read models
+// should pass TestRepository, so they can be swapped without changing
+// the behaviour of the application.
+package usertest
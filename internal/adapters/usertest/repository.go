@@ -0,0 +1,437 @@
+package usertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+// Repository represents the user repository under test.
+type Repository interface {
+	user.Repository
+	user.Importer
+	query.UserByIDReadModel
+	query.FindUsersReadModel
+	query.UsersPageReadModel
+}
+
+// TestRepository runs the contract test suite against the
+// repositories returned by newRepository.
+//
+// newRepository is called once for every test, the repositories
+// it returns may share data, so the tests only rely on the users
+// they create.
+//
+// Operations expected to fail are always the last ones of a test
+// as some databases abort the surrounding transaction.
+func TestRepository(
+	t *testing.T,
+	newRepository func(t *testing.T) Repository,
+) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetUser", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		u := createUser(t, r)
+
+		// The events are cleared once committed.
+		i.Equal(0, len(u.Events()))
+
+		found, err := r.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+		i.Equal(query.User{ID: u.ID(), Email: u.Email(), Version: 1}, found)
+	})
+
+	t.Run("GetUserNotFound", func(t *testing.T) {
+		r := newRepository(t)
+
+		_, err := r.GetUserByID(ctx, uuid.New())
+		assertErrorType(t, err, errors.ErrorTypeNotFound)
+	})
+
+	t.Run("CreateUserDuplicateEmail", func(t *testing.T) {
+		r := newRepository(t)
+
+		u := createUser(t, r)
+
+		duplicate := user.MustNew(uuid.New(), u.Email())
+
+		err := r.CreateUser(ctx, duplicate)
+		assertErrorType(t, err, errors.ErrorTypeAlreadyExists)
+
+		// The events are kept when the user couldn't be saved.
+		is.New(t).Equal(1, len(duplicate.Events()))
+	})
+
+	t.Run("CreateUserDuplicateID", func(t *testing.T) {
+		r := newRepository(t)
+
+		u := createUser(t, r)
+
+		err := r.CreateUser(ctx, user.MustNew(u.ID(), newEmail()))
+		assertErrorType(t, err, errors.ErrorTypeAlreadyExists)
+	})
+
+	t.Run("FindUsers", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		var (
+			u1 = createUser(t, r)
+			u2 = createUser(t, r)
+		)
+
+		users, err := r.FindUsers(ctx, user.Filter{ID: u1.ID()})
+		i.NoErr(err)
+		i.Equal(
+			[]query.User{{ID: u1.ID(), Email: u1.Email(), Version: 1}},
+			users,
+		)
+
+		email := u2.Email()
+
+		users, err = r.FindUsers(ctx, user.Filter{Email: &email})
+		i.NoErr(err)
+		i.Equal(
+			[]query.User{{ID: u2.ID(), Email: u2.Email(), Version: 1}},
+			users,
+		)
+
+		users, err = r.FindUsers(ctx, user.Filter{ID: u1.ID(), Email: &email})
+		i.NoErr(err)
+		i.Equal(0, len(users))
+
+		users, err = r.FindUsers(ctx, user.Filter{})
+		i.NoErr(err)
+		i.True(contains(users, u1) && contains(users, u2))
+	})
+
+	t.Run("UsersPage", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		var (
+			u1      = createUser(t, r)
+			u2      = createUser(t, r)
+			deleted = createUser(t, r)
+		)
+
+		err := r.UpdateUser(
+			ctx,
+			deleted.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.Delete()
+			},
+		)
+		i.NoErr(err)
+
+		var (
+			users []query.User
+			after uuid.UUID
+		)
+
+		for {
+			page, err := r.UsersPage(ctx, after, 2)
+			i.NoErr(err)
+			i.True(len(page) <= 2)
+
+			if len(page) == 0 {
+				break
+			}
+
+			users = append(users, page...)
+			after = page[len(page)-1].ID
+		}
+
+		for idx := 1; idx < len(users); idx++ {
+			i.True(users[idx-1].ID.String() < users[idx].ID.String())
+		}
+
+		i.True(contains(users, u1) && contains(users, u2))
+		i.True(!contains(users, deleted))
+	})
+
+	t.Run("ImportUsersSkip", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		var (
+			existing = createUser(t, r)
+			newUser  = user.MustNew(uuid.New(), newEmail())
+		)
+
+		results, err := r.ImportUsers(
+			ctx,
+			[]*user.User{
+				newUser,
+				user.MustNew(existing.ID(), newEmail()),
+				user.MustNew(uuid.New(), existing.Email()),
+				user.MustNew(newUser.ID(), newEmail()),
+			},
+			user.ImportModeSkip,
+		)
+		i.NoErr(err)
+		i.Equal(
+			[]user.ImportStatus{
+				user.ImportStatusCreated,
+				user.ImportStatusSkipped,
+				user.ImportStatusSkipped,
+				user.ImportStatusSkipped,
+			},
+			importStatuses(results),
+		)
+
+		found, err := r.GetUserByID(ctx, newUser.ID())
+		i.NoErr(err)
+		i.Equal(
+			query.User{ID: newUser.ID(), Email: newUser.Email(), Version: 1},
+			found,
+		)
+
+		found, err = r.GetUserByID(ctx, existing.ID())
+		i.NoErr(err)
+		i.Equal(existing.Email(), found.Email)
+	})
+
+	t.Run("ImportUsersUpsert", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		var (
+			changed   = createUser(t, r)
+			unchanged = createUser(t, r)
+			deleted   = createUser(t, r)
+			newUser   = user.MustNew(uuid.New(), newEmail())
+			email     = newEmail()
+		)
+
+		err := r.UpdateUser(
+			ctx,
+			deleted.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.Delete()
+			},
+		)
+		i.NoErr(err)
+
+		results, err := r.ImportUsers(
+			ctx,
+			[]*user.User{
+				user.MustNew(changed.ID(), email),
+				user.MustNew(unchanged.ID(), unchanged.Email()),
+				user.MustNew(uuid.New(), unchanged.Email()),
+				user.MustNew(deleted.ID(), newEmail()),
+				newUser,
+				user.MustNew(newUser.ID(), newEmail()),
+			},
+			user.ImportModeUpsert,
+		)
+		i.NoErr(err)
+		i.Equal(
+			[]user.ImportStatus{
+				user.ImportStatusUpdated,
+				user.ImportStatusSkipped,
+				user.ImportStatusFailed,
+				user.ImportStatusFailed,
+				user.ImportStatusCreated,
+				user.ImportStatusFailed,
+			},
+			importStatuses(results),
+		)
+		assertErrorType(t, results[2].Err, errors.ErrorTypeAlreadyExists)
+		assertErrorType(t, results[3].Err, errors.ErrorTypeInvalid)
+		assertErrorType(t, results[5].Err, errors.ErrorTypeAlreadyExists)
+
+		found, err := r.GetUserByID(ctx, changed.ID())
+		i.NoErr(err)
+		i.Equal(query.User{ID: changed.ID(), Email: email, Version: 2}, found)
+
+		found, err = r.GetUserByID(ctx, newUser.ID())
+		i.NoErr(err)
+		i.Equal(newUser.Email(), found.Email)
+	})
+
+	t.Run("ChangeEmail", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		var (
+			u     = createUser(t, r)
+			email = newEmail()
+		)
+
+		err := r.UpdateUser(
+			ctx,
+			u.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.ChangeEmail(email)
+			},
+		)
+		i.NoErr(err)
+
+		found, err := r.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+		i.Equal(email, found.Email)
+		i.Equal(int64(2), found.Version)
+	})
+
+	t.Run("UpdateStaleVersion", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		u := createUser(t, r)
+
+		// Returning a user loaded before a concurrent update
+		// must not overwrite it.
+		err := r.UpdateUser(
+			ctx,
+			u.ID(),
+			func(_ context.Context, _ *user.User) (*user.User, error) {
+				return user.UnmarshalFromDatabase(
+					u.ID(),
+					newEmail(),
+					u.Version()-1,
+				), nil
+			},
+		)
+		assertErrorType(t, err, errors.ErrorTypeFailedPrecondition)
+
+		found, err := r.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+		i.Equal(u.Email(), found.Email)
+	})
+
+	t.Run("ChangeEmailDuplicate", func(t *testing.T) {
+		r := newRepository(t)
+
+		var (
+			u1 = createUser(t, r)
+			u2 = createUser(t, r)
+		)
+
+		err := r.UpdateUser(
+			ctx,
+			u1.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.ChangeEmail(u2.Email())
+			},
+		)
+		assertErrorType(t, err, errors.ErrorTypeAlreadyExists)
+	})
+
+	t.Run("UpdateFnError", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		u := createUser(t, r)
+
+		err := r.UpdateUser(
+			ctx,
+			u.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				err := u.ChangeEmail(newEmail())
+				if err != nil {
+					return nil, err
+				}
+
+				return nil, errors.NewInvalidError("update")
+			},
+		)
+		assertErrorType(t, err, errors.ErrorTypeInvalid)
+
+		found, err := r.GetUserByID(ctx, u.ID())
+		i.NoErr(err)
+		i.Equal(u.Email(), found.Email)
+	})
+
+	t.Run("UpdateUserNotFound", func(t *testing.T) {
+		r := newRepository(t)
+
+		err := r.UpdateUser(
+			ctx,
+			uuid.New(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, nil
+			},
+		)
+		assertErrorType(t, err, errors.ErrorTypeNotFound)
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		i := is.New(t)
+		r := newRepository(t)
+
+		u := createUser(t, r)
+
+		err := r.UpdateUser(
+			ctx,
+			u.ID(),
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				return u, u.Delete()
+			},
+		)
+		i.NoErr(err)
+
+		users, err := r.FindUsers(ctx, user.Filter{ID: u.ID()})
+		i.NoErr(err)
+		i.Equal(0, len(users))
+
+		_, err = r.GetUserByID(ctx, u.ID())
+		assertErrorType(t, err, errors.ErrorTypeNotFound)
+	})
+}
+
+func createUser(t *testing.T, r Repository) *user.User {
+	t.Helper()
+
+	u := user.MustNew(uuid.New(), newEmail())
+
+	err := r.CreateUser(context.Background(), u)
+	if err != nil {
+		t.Fatalf("create user: %s", err)
+	}
+
+	return u
+}
+
+func importStatuses(results []user.ImportResult) []user.ImportStatus {
+	statuses := make([]user.ImportStatus, 0, len(results))
+
+	for _, res := range results {
+		statuses = append(statuses, res.Status)
+	}
+
+	return statuses
+}
+
+func newEmail() string {
+	return uuid.New().String() + "@test.com"
+}
+
+func contains(users []query.User, u *user.User) bool {
+	for _, found := range users {
+		if found.ID == u.ID() && found.Email == u.Email() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func assertErrorType(t *testing.T, err error, errorType errors.ErrorType) {
+	t.Helper()
+
+	i := is.New(t)
+
+	var appErr *errors.Error
+
+	i.True(errors.As(err, &appErr))
+	i.Equal(errorType, appErr.Type())
+}
@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+var _ outbox.EventPublisher = (*Dispatcher)(nil)
+
+// Dispatcher records a delivery for every subscription
+// interested in a published event.
+type Dispatcher struct {
+	repo webhook.Repository
+}
+
+// NewDispatcher creates a new Dispatcher.
+func NewDispatcher(repo webhook.Repository) *Dispatcher {
+	if repo == nil {
+		panic(errors.NewInvalidError("nil webhook repo"))
+	}
+
+	return &Dispatcher{
+		repo: repo,
+	}
+}
+
+// Publish satisfies the outbox.EventPublisher interface.
+//
+// Publishing the same message twice does not create
+// duplicate deliveries.
+func (d *Dispatcher) Publish(ctx context.Context, msg outbox.Message) error {
+	subs, err := d.repo.MatchingSubscriptions(ctx, msg.EventType)
+	if err != nil {
+		return fmt.Errorf("matching subscriptions: %w", err)
+	}
+
+	deliveries := make([]*webhook.Delivery, 0, len(subs))
+
+	for _, s := range subs {
+		delivery, err := webhook.NewDelivery(
+			uuid.New(),
+			s.ID(),
+			msg.ID,
+			msg.EventType,
+			msg.Payload,
+			msg.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("new delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	err = d.repo.CreateDeliveries(ctx, deliveries)
+	if err != nil {
+		return fmt.Errorf("create deliveries: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"time"
+)
+
+type workerOptions struct {
+	pollInterval,
+	timeout,
+	minBackoff,
+	maxBackoff time.Duration
+	batchSize,
+	maxAttempts int
+	allowInternal bool
+}
+
+func defaultWorkerOptions() workerOptions {
+	const (
+		pollInterval = time.Second
+		timeout      = 10 * time.Second
+		minBackoff   = 30 * time.Second
+		maxBackoff   = 6 * time.Hour
+		batchSize    = 50
+		maxAttempts  = 10
+	)
+
+	return workerOptions{
+		pollInterval: pollInterval,
+		timeout:      timeout,
+		minBackoff:   minBackoff,
+		maxBackoff:   maxBackoff,
+		batchSize:    batchSize,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// WorkerOption configures the Worker.
+type WorkerOption interface {
+	apply(*workerOptions)
+}
+
+type funcWorkerOption struct {
+	f func(*workerOptions)
+}
+
+func (f *funcWorkerOption) apply(o *workerOptions) {
+	f.f(o)
+}
+
+func newFuncWorkerOption(f func(*workerOptions)) *funcWorkerOption {
+	return &funcWorkerOption{
+		f: f,
+	}
+}
+
+// WithPollInterval configures how often the Worker checks for due
+// deliveries. Default 1s.
+func WithPollInterval(d time.Duration) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	})
+}
+
+// WithTimeout configures the timeout of a request sent to a
+// subscription endpoint. Default 10s.
+func WithTimeout(d time.Duration) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if d > 0 {
+			o.timeout = d
+		}
+	})
+}
+
+// WithBackoff configures the exponential delay between two attempts
+// of the same delivery. Default 30s up to 6h.
+func WithBackoff(minBackoff, maxBackoff time.Duration) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if minBackoff > 0 {
+			o.minBackoff = minBackoff
+		}
+
+		if maxBackoff >= o.minBackoff {
+			o.maxBackoff = maxBackoff
+		}
+	})
+}
+
+// WithMaxAttempts configures after how many failed attempts a delivery
+// is moved to the dead state. Default 10.
+func WithMaxAttempts(n int) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	})
+}
+
+// WithBatchSize configures how many deliveries are claimed at once.
+// Default 50.
+func WithBatchSize(n int) WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	})
+}
+
+// WithInternalAddresses allows sending the deliveries to loopback,
+// link-local and private addresses, refused by default.
+// It is meant for tests and local development.
+func WithInternalAddresses() WorkerOption {
+	return newFuncWorkerOption(func(o *workerOptions) {
+		o.allowInternal = true
+	})
+}
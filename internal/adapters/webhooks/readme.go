@@ -0,0 +1,9 @@
+// Package webhooks delivers the domain events to the webhook
+// subscriptions over HTTP.
+//
+// The Dispatcher consumes the events published by the outbox relay
+// and records a delivery for every matching subscription.
+// The Worker sends the due deliveries, signing every payload with the
+// subscription secret, and retries the failed ones with an exponential
+// backoff until they are moved to the dead state.
+package webhooks
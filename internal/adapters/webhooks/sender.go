@@ -0,0 +1,152 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+// ErrUnexpectedStatusCode is returned when the subscription endpoint
+// does not acknowledge the delivery with a 2xx status code.
+var ErrUnexpectedStatusCode = errors.New("unexpected status code")
+
+// ErrInternalAddress is returned when the subscription endpoint
+// resolves to an address of the internal network.
+var ErrInternalAddress = errors.New("internal address")
+
+// payload is the JSON body sent to the subscription endpoints.
+type payload struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Sender sends signed deliveries over HTTP.
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender creates a new Sender, every request is limited
+// to the given timeout.
+//
+// The redirects are not followed and, unless allowInternal is set,
+// the connections to internal addresses are refused when they are
+// dialed, so an endpoint can't reach them by changing what its host
+// resolves to once subscribed.
+func NewSender(timeout time.Duration, allowInternal bool) *Sender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+	}
+
+	if !allowInternal {
+		dialer.Control = refuseInternalAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		client: &http.Client{
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+			Timeout: timeout,
+		},
+		now: time.Now,
+	}
+}
+
+// IsInternalIP reports whether ip belongs to the internal network,
+// which the subscription endpoints must not point to.
+func IsInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast()
+}
+
+func refuseInternalAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("split host port: %w", err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || IsInternalIP(ip) {
+		return fmt.Errorf("%w: %s", ErrInternalAddress, host)
+	}
+
+	return nil
+}
+
+// Send POSTs the delivery to the url, signed with the secret.
+// It returns the received status code, 0 if no response was received.
+func (s *Sender) Send(
+	ctx context.Context,
+	url,
+	secret string,
+	d *webhook.Delivery,
+) (int, error) {
+	body, err := json.Marshal(payload{
+		ID:         d.EventID(),
+		Type:       d.EventType(),
+		OccurredAt: d.OccurredAt(),
+		Data:       d.Payload(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		url,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("new request: %w", err)
+	}
+
+	now := s.now()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, d.ID().String())
+	req.Header.Set(HeaderEvent, d.EventType())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(secret, now, body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < http.StatusOK ||
+		res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf(
+			"%w: %d",
+			ErrUnexpectedStatusCode,
+			res.StatusCode,
+		)
+	}
+
+	return res.StatusCode, nil
+}
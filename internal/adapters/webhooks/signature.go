@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every webhook request.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const signatureVersion = "v1="
+
+var (
+	// ErrInvalidSignature is returned when the signature does not
+	// match the payload.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrTimestampOutsideTolerance is returned when the signature
+	// timestamp is too old, or too far in the future.
+	ErrTimestampOutsideTolerance = errors.New("timestamp outside tolerance")
+)
+
+// Sign returns the signature of the body sent at the given time.
+//
+// The signature is the hex encoded HMAC-SHA256 of
+// "{unix timestamp}.{body}" keyed with the subscription secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return signatureVersion + hex.EncodeToString(
+		mac(secret, strconv.FormatInt(timestamp.Unix(), 10), body),
+	)
+}
+
+// Verify checks the signature and timestamp headers received together
+// with the body. Receivers can use it to authenticate the requests.
+func Verify(
+	secret,
+	timestampHeader,
+	signatureHeader string,
+	body []byte,
+	tolerance time.Duration,
+	now time.Time,
+) error {
+	ts, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse timestamp: %w", err)
+	}
+
+	diff := now.Sub(time.Unix(ts, 0))
+	if diff > tolerance || diff < -tolerance {
+		return ErrTimestampOutsideTolerance
+	}
+
+	sig, err := hex.DecodeString(
+		strings.TrimPrefix(signatureHeader, signatureVersion),
+	)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	if !hmac.Equal(sig, mac(secret, timestampHeader, body)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+
+	_, _ = h.Write([]byte(timestamp))
+	_, _ = h.Write([]byte("."))
+	_, _ = h.Write(body)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,169 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+	"go.uber.org/zap"
+)
+
+// ClaimedDelivery represents a delivery claimed to be sent,
+// together with the endpoint of its subscription.
+type ClaimedDelivery struct {
+	Delivery *webhook.Delivery
+	URL      string
+	Secret   string
+}
+
+// Store defines how the Worker reads and updates deliveries.
+type Store interface {
+	// ClaimDeliveries returns up to limit deliveries that are due to be
+	// sent and hides them from other workers until lease passes.
+	ClaimDeliveries(
+		ctx context.Context,
+		limit int,
+		lease time.Duration,
+	) ([]ClaimedDelivery, error)
+
+	UpdateDelivery(
+		ctx context.Context,
+		id uuid.UUID,
+		updateFn func(ctx context.Context, d *webhook.Delivery) (*webhook.Delivery, error),
+	) error
+}
+
+// Worker sends the due deliveries to the subscription endpoints.
+type Worker struct {
+	logger *zap.Logger
+	store  Store
+	sender *Sender
+	opts   workerOptions
+}
+
+// NewWorker creates a new Worker.
+func NewWorker(
+	logger *zap.Logger,
+	store Store,
+	opt ...WorkerOption,
+) *Worker {
+	if store == nil {
+		panic(errors.NewInvalidError("nil webhook store"))
+	}
+
+	opts := defaultWorkerOptions()
+
+	for _, o := range opt {
+		o.apply(&opts)
+	}
+
+	return &Worker{
+		logger: logger.Named("webhooks.worker"),
+		store:  store,
+		sender: NewSender(opts.timeout, opts.allowInternal),
+		opts:   opts,
+	}
+}
+
+// Run sends the due deliveries until the context is canceled.
+func (w *Worker) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-timer.C:
+		}
+
+		sent, err := w.SendBatch(ctx)
+		if err != nil {
+			w.logger.Error("send batch", zap.Error(err))
+		}
+
+		wait := w.opts.pollInterval
+		if sent == w.opts.batchSize {
+			wait = 0
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+// SendBatch claims a batch of due deliveries and sends them.
+// It returns the number of attempted deliveries.
+func (w *Worker) SendBatch(ctx context.Context) (int, error) {
+	claimed, err := w.store.ClaimDeliveries(
+		ctx,
+		w.opts.batchSize,
+		w.opts.timeout*2,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("claim deliveries: %w", err)
+	}
+
+	for _, c := range claimed {
+		err := w.send(ctx, c)
+		if err != nil {
+			return 0, fmt.Errorf("send %s: %w", c.Delivery.ID(), err)
+		}
+	}
+
+	return len(claimed), nil
+}
+
+func (w *Worker) send(ctx context.Context, c ClaimedDelivery) error {
+	statusCode, sendErr := w.sender.Send(ctx, c.URL, c.Secret, c.Delivery)
+
+	now := time.Now()
+
+	return w.store.UpdateDelivery(
+		ctx,
+		c.Delivery.ID(),
+		func(_ context.Context, d *webhook.Delivery) (*webhook.Delivery, error) {
+			if sendErr == nil {
+				d.RecordSuccess(statusCode, now)
+
+				return d, nil
+			}
+
+			d.RecordFailure(
+				statusCode,
+				sendErr.Error(),
+				now.Add(w.backoff(d.Attempts())),
+				w.opts.maxAttempts,
+			)
+
+			w.logger.Warn(
+				"webhook delivery failed",
+				zap.String("delivery_id", d.ID().String()),
+				zap.String("status", d.Status().String()),
+				zap.Int("attempts", d.Attempts()),
+				zap.Error(sendErr),
+			)
+
+			return d, nil
+		},
+	)
+}
+
+// backoff returns the delay before the next attempt, doubling
+// it for every previous failed attempt.
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.opts.minBackoff
+
+	for i := 0; i < attempts && d < w.opts.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > w.opts.maxBackoff {
+		d = w.opts.maxBackoff
+	}
+
+	return d
+}
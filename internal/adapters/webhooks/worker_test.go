@@ -0,0 +1,395 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/webhooks"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+	"go.uber.org/zap"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func TestWorker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("SendsSignedPayloadAndRetries", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		receiver := newReceiver(http.StatusInternalServerError, http.StatusOK)
+		t.Cleanup(receiver.Close)
+
+		d := newDelivery(t)
+		store := newStore(receiver.URL, d)
+
+		worker := webhooks.NewWorker(
+			zap.NewNop(),
+			store,
+			webhooks.WithBackoff(time.Hour, time.Hour),
+			webhooks.WithInternalAddresses(),
+		)
+
+		sent, err := worker.SendBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, sent)
+
+		failed := store.get(d.ID())
+		i.Equal(webhook.DeliveryStatusPending, failed.Status())
+		i.Equal(1, failed.Attempts())
+		i.Equal(http.StatusInternalServerError, failed.LastStatusCode())
+		i.True(failed.NextAttemptAt().After(time.Now().Add(time.Minute)))
+
+		// The failed delivery waits for its retry.
+		sent, err = worker.SendBatch(ctx)
+		i.NoErr(err)
+		i.Equal(0, sent)
+
+		store.makeDue(d.ID())
+
+		sent, err = worker.SendBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, sent)
+
+		succeeded := store.get(d.ID())
+		i.Equal(webhook.DeliveryStatusSucceeded, succeeded.Status())
+		i.True(succeeded.DeliveredAt() != nil)
+
+		reqs := receiver.requests()
+		i.Equal(2, len(reqs))
+
+		for _, r := range reqs {
+			i.NoErr(webhooks.Verify(
+				secret,
+				r.header.Get(webhooks.HeaderTimestamp),
+				r.header.Get(webhooks.HeaderSignature),
+				r.body,
+				time.Minute,
+				time.Now(),
+			))
+			i.Equal(d.ID().String(), r.header.Get(webhooks.HeaderID))
+			i.Equal("user.created", r.header.Get(webhooks.HeaderEvent))
+		}
+
+		var body map[string]any
+
+		i.NoErr(json.Unmarshal(reqs[0].body, &body))
+		i.Equal("user.created", body["type"])
+		i.Equal(map[string]any{"email": "user@email.com"}, body["data"])
+	})
+
+	t.Run("MovesToDeadAndRedelivers", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		receiver := newReceiver(http.StatusGone, http.StatusGone, http.StatusOK)
+		t.Cleanup(receiver.Close)
+
+		d := newDelivery(t)
+		store := newStore(receiver.URL, d)
+
+		worker := webhooks.NewWorker(
+			zap.NewNop(),
+			store,
+			webhooks.WithMaxAttempts(2),
+			webhooks.WithInternalAddresses(),
+		)
+
+		for attempt := 0; attempt < 2; attempt++ {
+			store.makeDue(d.ID())
+
+			_, err := worker.SendBatch(ctx)
+			i.NoErr(err)
+		}
+
+		dead := store.get(d.ID())
+		i.Equal(webhook.DeliveryStatusDead, dead.Status())
+		i.Equal(2, dead.Attempts())
+
+		// A dead delivery is not sent anymore.
+		store.makeDue(d.ID())
+
+		sent, err := worker.SendBatch(ctx)
+		i.NoErr(err)
+		i.Equal(0, sent)
+
+		err = store.UpdateDelivery(
+			ctx,
+			d.ID(),
+			func(_ context.Context, d *webhook.Delivery) (*webhook.Delivery, error) {
+				return d, d.Redeliver(time.Now())
+			},
+		)
+		i.NoErr(err)
+
+		sent, err = worker.SendBatch(ctx)
+		i.NoErr(err)
+		i.Equal(1, sent)
+
+		i.Equal(webhook.DeliveryStatusSucceeded, store.get(d.ID()).Status())
+	})
+}
+
+func TestSender(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("RefusesInternalAddresses", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		receiver := newReceiver()
+		t.Cleanup(receiver.Close)
+
+		sender := webhooks.NewSender(time.Second, false)
+
+		statusCode, err := sender.Send(ctx, receiver.URL, secret, newDelivery(t))
+		i.True(errors.Is(err, webhooks.ErrInternalAddress))
+		i.Equal(0, statusCode)
+		i.Equal(0, len(receiver.requests()))
+	})
+
+	t.Run("DoesNotFollowRedirects", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		target := newReceiver()
+		t.Cleanup(target.Close)
+
+		redirect := httptest.NewServer(http.RedirectHandler(
+			target.URL,
+			http.StatusTemporaryRedirect,
+		))
+		t.Cleanup(redirect.Close)
+
+		sender := webhooks.NewSender(time.Second, true)
+
+		statusCode, err := sender.Send(ctx, redirect.URL, secret, newDelivery(t))
+		i.True(errors.Is(err, webhooks.ErrUnexpectedStatusCode))
+		i.Equal(http.StatusTemporaryRedirect, statusCode)
+		i.Equal(0, len(target.requests()))
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	var (
+		now  = time.Now()
+		body = []byte(`{"id":1}`)
+		sig  = webhooks.Sign(secret, now, body)
+		ts   = strconv.FormatInt(now.Unix(), 10)
+	)
+
+	i.NoErr(webhooks.Verify(secret, ts, sig, body, time.Minute, now))
+
+	i.Equal(
+		webhooks.ErrInvalidSignature,
+		webhooks.Verify(secret, ts, sig, []byte(`{"id":2}`), time.Minute, now),
+	)
+
+	i.Equal(
+		webhooks.ErrTimestampOutsideTolerance,
+		webhooks.Verify(secret, ts, sig, body, time.Minute, now.Add(time.Hour)),
+	)
+}
+
+func newDelivery(t *testing.T) *webhook.Delivery {
+	t.Helper()
+
+	d, err := webhook.NewDelivery(
+		uuid.New(),
+		uuid.New(),
+		1,
+		"user.created",
+		[]byte(`{"email":"user@email.com"}`),
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is a webhook endpoint replying with the given
+// status codes, in order.
+type receiver struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	statusCodes []int
+	reqs        []request
+}
+
+func newReceiver(statusCodes ...int) *receiver {
+	r := &receiver{
+		statusCodes: statusCodes,
+	}
+
+	r.Server = httptest.NewServer(http.HandlerFunc(r.handle))
+
+	return r
+}
+
+func (r *receiver) handle(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reqs = append(r.reqs, request{
+		header: req.Header.Clone(),
+		body:   body,
+	})
+
+	statusCode := http.StatusOK
+
+	if len(r.statusCodes) > 0 {
+		statusCode, r.statusCodes = r.statusCodes[0], r.statusCodes[1:]
+	}
+
+	w.WriteHeader(statusCode)
+}
+
+func (r *receiver) requests() []request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]request(nil), r.reqs...)
+}
+
+// store is an in memory webhooks.Store with the same claim
+// semantics as the PostgreSQL implementation.
+type store struct {
+	mu         sync.Mutex
+	url        string
+	deliveries map[uuid.UUID]*webhook.Delivery
+	leasedTill map[uuid.UUID]time.Time
+}
+
+func newStore(url string, deliveries ...*webhook.Delivery) *store {
+	s := &store{
+		url:        url,
+		deliveries: make(map[uuid.UUID]*webhook.Delivery),
+		leasedTill: make(map[uuid.UUID]time.Time),
+	}
+
+	for _, d := range deliveries {
+		s.deliveries[d.ID()] = d
+	}
+
+	return s
+}
+
+func (s *store) ClaimDeliveries(
+	_ context.Context,
+	limit int,
+	lease time.Duration,
+) ([]webhooks.ClaimedDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		now     = time.Now()
+		claimed []webhooks.ClaimedDelivery
+	)
+
+	for id, d := range s.deliveries {
+		if d.Status() != webhook.DeliveryStatusPending ||
+			d.NextAttemptAt().After(now) ||
+			s.leasedTill[id].After(now) ||
+			len(claimed) == limit {
+			continue
+		}
+
+		s.leasedTill[id] = now.Add(lease)
+
+		claimed = append(claimed, webhooks.ClaimedDelivery{
+			Delivery: copyDelivery(d),
+			URL:      s.url,
+			Secret:   secret,
+		})
+	}
+
+	return claimed, nil
+}
+
+func (s *store) UpdateDelivery(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, d *webhook.Delivery) (*webhook.Delivery, error),
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated, err := updateFn(ctx, copyDelivery(s.deliveries[id]))
+	if err != nil {
+		return err
+	}
+
+	s.deliveries[id] = updated
+	s.leasedTill[id] = time.Time{}
+
+	return nil
+}
+
+func (s *store) get(id uuid.UUID) *webhook.Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyDelivery(s.deliveries[id])
+}
+
+func (s *store) makeDue(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.deliveries[id]
+
+	s.deliveries[id] = webhook.UnmarshalDeliveryFromDatabase(
+		d.ID(),
+		d.SubscriptionID(),
+		d.EventID(),
+		d.EventType(),
+		d.Payload(),
+		d.OccurredAt(),
+		d.Status(),
+		d.Attempts(),
+		time.Time{},
+		d.LastStatusCode(),
+		d.LastError(),
+		d.DeliveredAt(),
+	)
+}
+
+func copyDelivery(d *webhook.Delivery) *webhook.Delivery {
+	c := *d
+
+	return &c
+}
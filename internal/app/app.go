@@ -10,16 +10,31 @@ import (
 type Application struct {
 	Commands Commands
 	Queries  Queries
+
+	// Webhooks tells whether the webhook commands and queries
+	// are set, they are not with every database driver.
+	Webhooks bool
 }
 
 // Commands represents the commands available in the application.
 type Commands struct {
-	CreateUser  command.CreateUserHandler
-	ReportError command.ReportErrorHandler
+	CreateUser      command.CreateUserHandler
+	ChangeUserEmail command.ChangeUserEmailHandler
+	DeleteUser      command.DeleteUserHandler
+	ImportUsers     command.ImportUsersHandler
+	ReportError     command.ReportErrorHandler
+
+	CreateWebhookSubscription command.CreateWebhookSubscriptionHandler
+	DeleteWebhookSubscription command.DeleteWebhookSubscriptionHandler
+	RedeliverWebhook          command.RedeliverWebhookHandler
 }
 
 // Queries represents the queries available in the application.
 type Queries struct {
-	FindUsers query.FindUsersHandler
-	UserByID  query.UserByIDHandler
+	FindUsers   query.FindUsersHandler
+	UserByID    query.UserByIDHandler
+	StreamUsers query.StreamUsersHandler
+
+	WebhookSubscriptions query.WebhookSubscriptionsHandler
+	WebhookDeliveries    query.WebhookDeliveriesHandler
 }
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+// ChangeUserEmail represents the data required
+// in order to change the email of a user.
+type ChangeUserEmail struct {
+	ID    uuid.UUID
+	Email string
+
+	// ExpectedVersion is the version the change is based on,
+	// it is not checked when zero.
+	ExpectedVersion int64
+}
+
+// ChangeUserEmailHandler holds the dependencies for changing
+// the email of a user.
+type ChangeUserEmailHandler struct {
+	uow UnitOfWork
+}
+
+// MustNewChangeUserEmailHandler returns an initialized
+// ChangeUserEmailHandler.
+func MustNewChangeUserEmailHandler(
+	uow UnitOfWork,
+) ChangeUserEmailHandler {
+	if uow == nil {
+		panic(errors.NewInvalidError("nil unit of work"))
+	}
+
+	return ChangeUserEmailHandler{
+		uow: uow,
+	}
+}
+
+// Handle executes the ChangeUserEmail command.
+func (h ChangeUserEmailHandler) Handle(
+	ctx context.Context,
+	cmd ChangeUserEmail,
+) error {
+	err := h.uow.Do(ctx, func(ctx context.Context, repos Repositories) error {
+		return repos.Users.UpdateUser(
+			ctx,
+			cmd.ID,
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				err := u.CheckVersion(cmd.ExpectedVersion)
+				if err != nil {
+					return nil, fmt.Errorf("check version: %w", err)
+				}
+
+				err = u.ChangeEmail(cmd.Email)
+				if err != nil {
+					return nil, fmt.Errorf("change email: %w", err)
+				}
+
+				return u, nil
+			},
+		)
+	})
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+// CreateWebhookSubscription represents the data required
+// in order to subscribe an endpoint to events.
+type CreateWebhookSubscription struct {
+	ID         uuid.UUID
+	URL        string
+	EventTypes []string
+	Secret     string
+}
+
+// CreateWebhookSubscriptionHandler holds the dependencies for
+// subscribing an endpoint to events.
+type CreateWebhookSubscriptionHandler struct {
+	webhookRepo webhook.Repository
+}
+
+// MustNewCreateWebhookSubscriptionHandler returns an initialized
+// CreateWebhookSubscriptionHandler.
+func MustNewCreateWebhookSubscriptionHandler(
+	webhookRepo webhook.Repository,
+) CreateWebhookSubscriptionHandler {
+	if webhookRepo == nil {
+		panic(errors.NewInvalidError("nil webhook repo"))
+	}
+
+	return CreateWebhookSubscriptionHandler{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// Handle executes the CreateWebhookSubscription command.
+func (h CreateWebhookSubscriptionHandler) Handle(
+	ctx context.Context,
+	cmd CreateWebhookSubscription,
+) error {
+	s, err := webhook.NewSubscription(
+		cmd.ID,
+		cmd.URL,
+		cmd.EventTypes,
+		cmd.Secret,
+	)
+	if err != nil {
+		return fmt.Errorf("new subscription: %w", err)
+	}
+
+	err = h.webhookRepo.CreateSubscription(ctx, s)
+	if err != nil {
+		return fmt.Errorf("create subscription: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+// DeleteUser represents the data required
+// in order to remove a user from the system.
+type DeleteUser struct {
+	ID uuid.UUID
+
+	// ExpectedVersion is the version the deletion is based on,
+	// it is not checked when zero.
+	ExpectedVersion int64
+}
+
+// DeleteUserHandler holds the dependencies for removing
+// a user from the system.
+type DeleteUserHandler struct {
+	uow UnitOfWork
+}
+
+// MustNewDeleteUserHandler returns an initialized DeleteUserHandler.
+func MustNewDeleteUserHandler(
+	uow UnitOfWork,
+) DeleteUserHandler {
+	if uow == nil {
+		panic(errors.NewInvalidError("nil unit of work"))
+	}
+
+	return DeleteUserHandler{
+		uow: uow,
+	}
+}
+
+// Handle executes the DeleteUser command.
+func (h DeleteUserHandler) Handle(
+	ctx context.Context,
+	cmd DeleteUser,
+) error {
+	err := h.uow.Do(ctx, func(ctx context.Context, repos Repositories) error {
+		return repos.Users.UpdateUser(
+			ctx,
+			cmd.ID,
+			func(_ context.Context, u *user.User) (*user.User, error) {
+				err := u.CheckVersion(cmd.ExpectedVersion)
+				if err != nil {
+					return nil, fmt.Errorf("check version: %w", err)
+				}
+
+				err = u.Delete()
+				if err != nil {
+					return nil, fmt.Errorf("delete: %w", err)
+				}
+
+				return u, nil
+			},
+		)
+	})
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+// DeleteWebhookSubscription represents the data required
+// in order to stop delivering events to an endpoint.
+type DeleteWebhookSubscription struct {
+	ID uuid.UUID
+}
+
+// DeleteWebhookSubscriptionHandler holds the dependencies for
+// removing a webhook subscription.
+type DeleteWebhookSubscriptionHandler struct {
+	webhookRepo webhook.Repository
+}
+
+// MustNewDeleteWebhookSubscriptionHandler returns an initialized
+// DeleteWebhookSubscriptionHandler.
+func MustNewDeleteWebhookSubscriptionHandler(
+	webhookRepo webhook.Repository,
+) DeleteWebhookSubscriptionHandler {
+	if webhookRepo == nil {
+		panic(errors.NewInvalidError("nil webhook repo"))
+	}
+
+	return DeleteWebhookSubscriptionHandler{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// Handle executes the DeleteWebhookSubscription command.
+func (h DeleteWebhookSubscriptionHandler) Handle(
+	ctx context.Context,
+	cmd DeleteWebhookSubscription,
+) error {
+	err := h.webhookRepo.DeleteSubscription(ctx, cmd.ID)
+	if err != nil {
+		return fmt.Errorf("delete subscription: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+// ImportBatchSize is the number of rows the callers of
+// ImportUsersHandler should import at once.
+const ImportBatchSize = 500
+
+// ImportUserRow represents a user to import.
+type ImportUserRow struct {
+	// ID is generated when empty.
+	ID    string
+	Email string
+}
+
+// ImportUsers represents the data required in order
+// to add a batch of users into the system.
+type ImportUsers struct {
+	Mode user.ImportMode
+	Rows []ImportUserRow
+}
+
+// ImportUserResult represents the outcome of importing a row.
+type ImportUserResult struct {
+	ID     uuid.UUID
+	Email  string
+	Status user.ImportStatus
+	// Err explains why the row failed to be imported.
+	Err error
+}
+
+// ImportUsersHandler holds the dependencies for adding
+// batches of users to the system.
+type ImportUsersHandler struct {
+	importer user.Importer
+}
+
+// MustNewImportUsersHandler returns an initialized ImportUsersHandler.
+func MustNewImportUsersHandler(
+	importer user.Importer,
+) ImportUsersHandler {
+	if importer == nil {
+		panic(errors.NewInvalidError("nil user importer"))
+	}
+
+	return ImportUsersHandler{
+		importer: importer,
+	}
+}
+
+// Handle executes the ImportUsers command and returns the result of
+// every row, in order.
+//
+// The invalid rows are reported as failed, the valid ones are
+// imported in a single transaction. An error is returned only when
+// the batch could not be imported at all.
+func (h ImportUsersHandler) Handle(
+	ctx context.Context,
+	cmd ImportUsers,
+) ([]ImportUserResult, error) {
+	var (
+		results = make([]ImportUserResult, len(cmd.Rows))
+		users   = make([]*user.User, 0, len(cmd.Rows))
+		indexes = make([]int, 0, len(cmd.Rows))
+	)
+
+	for i, row := range cmd.Rows {
+		results[i].Email = row.Email
+
+		id, err := importedUserID(row)
+		if err != nil {
+			results[i].Status = user.ImportStatusFailed
+			results[i].Err = err
+
+			continue
+		}
+
+		results[i].ID = id
+
+		u, err := user.New(id, row.Email)
+		if err != nil {
+			results[i].Status = user.ImportStatusFailed
+			results[i].Err = fmt.Errorf("new user: %w", err)
+
+			continue
+		}
+
+		users = append(users, u)
+		indexes = append(indexes, i)
+	}
+
+	if len(users) == 0 {
+		return results, nil
+	}
+
+	imported, err := h.importer.ImportUsers(ctx, users, cmd.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("import users: %w", err)
+	}
+
+	for i, res := range imported {
+		results[indexes[i]].Status = res.Status
+		results[indexes[i]].Err = res.Err
+	}
+
+	return results, nil
+}
+
+// importedUserID returns the id of the row, a new one when empty.
+func importedUserID(row ImportUserRow) (uuid.UUID, error) {
+	if row.ID == "" {
+		return uuid.New(), nil
+	}
+
+	id, err := uuid.Parse(row.ID)
+	if err != nil {
+		return uuid.UUID{}, errors.NewInvalidError("user id")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+// RedeliverWebhook represents the data required
+// in order to send a webhook delivery again.
+type RedeliverWebhook struct {
+	DeliveryID uuid.UUID
+}
+
+// RedeliverWebhookHandler holds the dependencies for
+// sending a webhook delivery again.
+type RedeliverWebhookHandler struct {
+	webhookRepo webhook.Repository
+}
+
+// MustNewRedeliverWebhookHandler returns an initialized
+// RedeliverWebhookHandler.
+func MustNewRedeliverWebhookHandler(
+	webhookRepo webhook.Repository,
+) RedeliverWebhookHandler {
+	if webhookRepo == nil {
+		panic(errors.NewInvalidError("nil webhook repo"))
+	}
+
+	return RedeliverWebhookHandler{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// Handle executes the RedeliverWebhook command.
+func (h RedeliverWebhookHandler) Handle(
+	ctx context.Context,
+	cmd RedeliverWebhook,
+) error {
+	err := h.webhookRepo.UpdateDelivery(
+		ctx,
+		cmd.DeliveryID,
+		func(_ context.Context, d *webhook.Delivery) (*webhook.Delivery, error) {
+			err := d.Redeliver(time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("redeliver: %w", err)
+			}
+
+			return d, nil
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("update delivery: %w", err)
+	}
+
+	return nil
+}
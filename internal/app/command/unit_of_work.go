@@ -0,0 +1,35 @@
+package command
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+// Repositories holds the repositories scoped to a unit of work.
+//
+// The changes made through them are committed or rolled back
+// together.
+type Repositories struct {
+	Users    user.Repository
+	Webhooks webhook.Repository
+}
+
+// UnitOfWork runs a function atomically against the repositories.
+type UnitOfWork interface {
+	// Do runs fn in a transaction that is committed if fn
+	// returns nil and rolled back otherwise.
+	//
+	// Calling Do with the context received by fn nests the
+	// new unit of work into the running one, so only its own
+	// changes are rolled back when it fails.
+	//
+	// fn may be called more than once if the transaction
+	// conflicts with a concurrent one, it must not have side
+	// effects outside of the repositories.
+	Do(
+		ctx context.Context,
+		fn func(ctx context.Context, repos Repositories) error,
+	) error
+}
@@ -0,0 +1,89 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+)
+
+// Limits of the batches the users are streamed in.
+const (
+	DefaultStreamBatchSize = 100
+	MaxStreamBatchSize     = 1000
+)
+
+// UsersPageReadModel represents the application can page
+// through all the users.
+type UsersPageReadModel interface {
+	// UsersPage returns up to limit users with an ID greater than
+	// after, ordered by ID. A zero after starts from the first user.
+	UsersPage(
+		ctx context.Context,
+		after uuid.UUID,
+		limit int,
+	) ([]User, error)
+}
+
+// StreamUsersHandler holds the dependencies for streaming
+// all the users of the system.
+type StreamUsersHandler struct {
+	readModel UsersPageReadModel
+}
+
+// MustNewStreamUsersHandler returns an initialized StreamUsersHandler.
+func MustNewStreamUsersHandler(
+	readModel UsersPageReadModel,
+) StreamUsersHandler {
+	if readModel == nil {
+		panic(errors.NewInvalidError("nil read model"))
+	}
+
+	return StreamUsersHandler{
+		readModel: readModel,
+	}
+}
+
+// Handle pages through the users, batchSize at a time, and passes
+// every batch to send before the next one is read.
+//
+// It stops when all the users were sent, send fails or ctx is
+// canceled. A batchSize out of bounds is replaced by the default.
+func (s StreamUsersHandler) Handle(
+	ctx context.Context,
+	batchSize int,
+	send func(users []User) error,
+) error {
+	if batchSize <= 0 || batchSize > MaxStreamBatchSize {
+		batchSize = DefaultStreamBatchSize
+	}
+
+	var after uuid.UUID
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("stream users: %w", err)
+		}
+
+		users, err := s.readModel.UsersPage(ctx, after, batchSize)
+		if err != nil {
+			return fmt.Errorf("read model: %w", err)
+		}
+
+		if len(users) == 0 {
+			return nil
+		}
+
+		err = send(users)
+		if err != nil {
+			return fmt.Errorf("send users: %w", err)
+		}
+
+		if len(users) < batchSize {
+			return nil
+		}
+
+		after = users[len(users)-1].ID
+	}
+}
@@ -7,6 +7,7 @@ import (
 // User represents the API model for the
 // domain User.
 type User struct {
-	ID    uuid.UUID
-	Email string
+	ID      uuid.UUID
+	Email   string
+	Version int64
 }
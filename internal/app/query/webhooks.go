@@ -0,0 +1,117 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+// WebhookSubscription represents the API model for the
+// domain webhook Subscription.
+type WebhookSubscription struct {
+	ID         uuid.UUID
+	URL        string
+	EventTypes []string
+	CreatedAt  time.Time
+}
+
+// WebhookDelivery represents the API model for the
+// domain webhook Delivery.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        int64
+	EventType      string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookSubscriptionsReadModel represents how the application is
+// querying webhook subscriptions.
+type WebhookSubscriptionsReadModel interface {
+	FindWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+}
+
+// WebhookSubscriptionsHandler holds the dependencies for querying
+// the webhook subscriptions.
+type WebhookSubscriptionsHandler struct {
+	readModel WebhookSubscriptionsReadModel
+}
+
+// MustNewWebhookSubscriptionsHandler returns an initialized
+// WebhookSubscriptionsHandler.
+func MustNewWebhookSubscriptionsHandler(
+	readModel WebhookSubscriptionsReadModel,
+) WebhookSubscriptionsHandler {
+	if readModel == nil {
+		panic(errors.NewInvalidError("nil read model"))
+	}
+
+	return WebhookSubscriptionsHandler{
+		readModel: readModel,
+	}
+}
+
+// Handle queries all the webhook subscriptions.
+func (h WebhookSubscriptionsHandler) Handle(
+	ctx context.Context,
+) ([]WebhookSubscription, error) {
+	subs, err := h.readModel.FindWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read model: %w", err)
+	}
+
+	return subs, nil
+}
+
+// WebhookDeliveriesReadModel represents how the application is
+// querying the webhook delivery log.
+type WebhookDeliveriesReadModel interface {
+	FindWebhookDeliveries(
+		ctx context.Context,
+		filter webhook.DeliveryFilter,
+	) ([]WebhookDelivery, error)
+}
+
+// WebhookDeliveriesHandler holds the dependencies for querying
+// the webhook delivery log.
+type WebhookDeliveriesHandler struct {
+	readModel WebhookDeliveriesReadModel
+}
+
+// MustNewWebhookDeliveriesHandler returns an initialized
+// WebhookDeliveriesHandler.
+func MustNewWebhookDeliveriesHandler(
+	readModel WebhookDeliveriesReadModel,
+) WebhookDeliveriesHandler {
+	if readModel == nil {
+		panic(errors.NewInvalidError("nil read model"))
+	}
+
+	return WebhookDeliveriesHandler{
+		readModel: readModel,
+	}
+}
+
+// Handle queries the webhook deliveries based on the filter
+// provided, newest first.
+func (h WebhookDeliveriesHandler) Handle(
+	ctx context.Context,
+	filter webhook.DeliveryFilter,
+) ([]WebhookDelivery, error) {
+	deliveries, err := h.readModel.FindWebhookDeliveries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("read model: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,48 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Info describes the build of the running binary.
+type Info struct {
+	// Version is the module version, (devel) when built
+	// from a local checkout.
+	Version string `json:"version"`
+	// Commit is the VCS revision the binary was built from and
+	// Modified tells whether the working tree had changes.
+	Commit   string `json:"commit"`
+	Modified bool   `json:"modified"`
+	// Time is the commit time, in RFC 3339.
+	Time      string `json:"time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Read returns the Info of the running binary, with the fields
+// missing from the build information left empty.
+func Read() Info {
+	info := Info{
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Version = bi.Main.Version
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
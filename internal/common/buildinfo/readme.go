@@ -0,0 +1,3 @@
+// Package buildinfo reports the version of the running binary
+// from the build information embedded by the go command.
+package buildinfo
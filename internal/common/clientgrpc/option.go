@@ -2,9 +2,11 @@ package clientgrpc
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -12,9 +14,14 @@ type options struct {
 	dialOptions []grpc.DialOption
 }
 
+// defaultOptions pass the request id carried by the
+// context of the calls along to the server.
 func defaultOptions() *options {
 	return &options{
-		dialOptions: []grpc.DialOption{},
+		dialOptions: []grpc.DialOption{
+			grpc.WithChainUnaryInterceptor(unaryRequestIDInterceptor),
+			grpc.WithChainStreamInterceptor(streamRequestIDInterceptor),
+		},
 	}
 }
 
@@ -48,6 +55,27 @@ func WithNoTLS() Option {
 	})
 }
 
+// WithTLS secures the connection with TLS configured by cfg.
+func WithTLS(cfg *tls.Config) Option {
+	return newFuncServerOption(func(o *options) {
+		o.dialOptions = append(
+			o.dialOptions,
+			grpc.WithTransportCredentials(credentials.NewTLS(cfg)),
+		)
+	})
+}
+
+// WithBearerToken sends the given token in the authorization
+// metadata of every call.
+func WithBearerToken(token string) Option {
+	return newFuncServerOption(func(o *options) {
+		o.dialOptions = append(
+			o.dialOptions,
+			grpc.WithPerRPCCredentials(bearerToken(token)),
+		)
+	})
+}
+
 // WithContextDialer wraps the grpc.WithContextDialer option.
 func WithContextDialer(
 	d func(context.Context, string) (net.Conn, error),
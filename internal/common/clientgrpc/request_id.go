@@ -0,0 +1,51 @@
+package clientgrpc
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// withRequestID returns a copy of ctx sending the request id
+// it carries in the outgoing metadata, unless already set.
+func withRequestID(ctx context.Context) context.Context {
+	id, ok := requestid.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if len(md.Get(requestid.MetadataKey)) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, id)
+}
+
+// unaryRequestIDInterceptor passes the request id
+// along to the unary calls.
+func unaryRequestIDInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+// streamRequestIDInterceptor passes the request id
+// along to the streams.
+func streamRequestIDInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return streamer(withRequestID(ctx), desc, cc, method, opts...)
+}
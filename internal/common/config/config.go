@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -11,17 +13,66 @@ import (
 // Config the config.json file should be set at the root level.
 // nolint: revive // reports nested structs not allowed. TODO: fix
 type Config struct {
+	// Storage selects where the application keeps its data:
+	// database, the one selected by DB.Driver, or memory.
+	Storage string `mapstructure:"storage"`
+
 	SERVER struct {
 		Port    int    `mapstructure:"port"`
 		Address string `mapstructure:"address"`
+		// GRPCPort is the port of the grpc server, Port-1 when not
+		// set. It is ignored in the single port mode.
+		GRPCPort int `mapstructure:"grpc_port"`
+		// SinglePort serves the grpc server and the gateway on Port.
+		SinglePort bool `mapstructure:"single_port"`
+		// InProcessGateway connects the gateway to the grpc server
+		// in-memory instead of over TCP, always the case in the
+		// single port mode.
+		InProcessGateway bool `mapstructure:"in_process_gateway"`
+		// Reflection serves the grpc reflection service, used by
+		// tools like grpcurl to discover the API.
+		Reflection bool `mapstructure:"reflection"`
+	}
+	HTTP struct {
+		CORS struct {
+			// AllowedOrigins may contain a * wildcard, no
+			// cross-origin request is allowed when empty.
+			// AllowCredentials requires explicit origins.
+			AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+			AllowedMethods   []string      `mapstructure:"allowed_methods"`
+			AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+			AllowCredentials bool          `mapstructure:"allow_credentials"`
+			MaxAge           time.Duration `mapstructure:"max_age"`
+		} `mapstructure:"cors"`
+		// HSTSMaxAge enables the Strict-Transport-Security header.
+		HSTSMaxAge   time.Duration `mapstructure:"hsts_max_age"`
+		FrameOptions string        `mapstructure:"frame_options"`
+		// MaxBodySize is the size, in bytes, of the largest request
+		// body accepted, 0 for no limit.
+		MaxBodySize   int64 `mapstructure:"max_body_size"`
+		CompressLevel int   `mapstructure:"compress_level"`
+		// TrustedProxies are the CIDRs of the proxies whose
+		// X-Forwarded-For and X-Real-IP headers are trusted.
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
 	}
 	DB struct {
+		// Driver selects the database: postgres or sqlite.
+		// NAME is the database file for sqlite.
 		Driver      string `mapstructure:"driver"`
 		HOST        string `mapstructure:"host"`
 		USER        string `mapstructure:"user"`
 		PASSWORD    string `mapstructure:"password"`
 		NAME        string `mapstructure:"name"`
 		Automigrate bool   `mapstructure:"automigrate"`
+
+		// REPLICAS are the hosts of the postgres read replicas,
+		// they share the credentials of the primary.
+		REPLICAS             []string      `mapstructure:"replicas"`
+		MaxReplicaLag        time.Duration `mapstructure:"max_replica_lag"`
+		ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
+		// ReadYourWritesWindow configures for how long the reads
+		// of a session go to the primary after it wrote.
+		ReadYourWritesWindow time.Duration `mapstructure:"read_your_writes_window"`
 	}
 
 	JWT struct {
@@ -29,6 +80,124 @@ type Config struct {
 		RefreshTokenExp int    `mapstructure:"refresh_token_exp"`
 		AccessTokenExp  int    `mapstructure:"access_token_exp"`
 	}
+
+	OUTBOX struct {
+		// Publisher selects where the domain events are published:
+		// memory, webhook or broker.
+		Publisher      string        `mapstructure:"publisher"`
+		WebhookURL     string        `mapstructure:"webhook_url"`
+		WebhookTimeout time.Duration `mapstructure:"webhook_timeout"`
+		TopicPrefix    string        `mapstructure:"topic_prefix"`
+		PollInterval   time.Duration `mapstructure:"poll_interval"`
+		BatchSize      int           `mapstructure:"batch_size"`
+	}
+
+	WEBHOOKS struct {
+		Timeout      time.Duration `mapstructure:"timeout"`
+		PollInterval time.Duration `mapstructure:"poll_interval"`
+		BatchSize    int           `mapstructure:"batch_size"`
+		MaxAttempts  int           `mapstructure:"max_attempts"`
+		MinBackoff   time.Duration `mapstructure:"min_backoff"`
+		MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+	}
+
+	CACHE struct {
+		// Backend selects where the user read models are cached:
+		// none, memory or redis.
+		Backend      string        `mapstructure:"backend"`
+		TTL          time.Duration `mapstructure:"ttl"`
+		Size         int           `mapstructure:"size"`
+		RedisAddress string        `mapstructure:"redis_address"`
+	}
+
+	RATE_LIMIT struct {
+		// Store selects where the token buckets are kept:
+		// none, memory or redis.
+		Store        string `mapstructure:"store"`
+		RedisAddress string `mapstructure:"redis_address"`
+		// Rate, in tokens per second, and Burst are the default
+		// limit of every RPC, METHODS overrides it per RPC name.
+		Rate    float64 `mapstructure:"rate"`
+		Burst   int     `mapstructure:"burst"`
+		METHODS map[string]struct {
+			Rate  float64 `mapstructure:"rate"`
+			Burst int     `mapstructure:"burst"`
+		} `mapstructure:"methods"`
+
+		// APIKeys are the keys clients can send in the X-Api-Key
+		// header to be limited by key, the calls with any other key
+		// are limited by IP address.
+		APIKeys []string `mapstructure:"api_keys"`
+	}
+
+	IDEMPOTENCY struct {
+		// TTL configures for how long the responses of the requests
+		// carrying an idempotency key are replayed.
+		TTL time.Duration `mapstructure:"ttl"`
+	}
+
+	JOBS struct {
+		Concurrency  int           `mapstructure:"concurrency"`
+		PollInterval time.Duration `mapstructure:"poll_interval"`
+		Lease        time.Duration `mapstructure:"lease"`
+		// Retention configures for how long finished jobs and
+		// published outbox messages are kept.
+		Retention     time.Duration `mapstructure:"retention"`
+		PruneInterval time.Duration `mapstructure:"prune_interval"`
+	}
+
+	LOG struct {
+		// Level is the lowest level logged: debug, info, warn or
+		// error. It can be changed at runtime on the admin server.
+		Level string `mapstructure:"level"`
+		// Format of the logs: json or console.
+		Format string `mapstructure:"format"`
+		// Every second, the first SamplingInitial logs with the same
+		// level and message are written, then every
+		// SamplingThereafter-th one. Sampling is disabled when 0.
+		SamplingInitial    int `mapstructure:"sampling_initial"`
+		SamplingThereafter int `mapstructure:"sampling_thereafter"`
+		// Redact are the names of the log fields whose values are
+		// redacted, email when empty.
+		Redact []string `mapstructure:"redact"`
+		SQL    struct {
+			// Level of the query logs: silent, error for the failed
+			// queries, warn for the slow ones too or info for all.
+			Level         string        `mapstructure:"level"`
+			SlowThreshold time.Duration `mapstructure:"slow_threshold"`
+			// Params logs the query parameters instead of redacting
+			// them, only meant for development.
+			Params bool `mapstructure:"params"`
+		} `mapstructure:"sql"`
+	}
+
+	ADMIN struct {
+		// Address the admin server listens to, it is
+		// disabled when empty.
+		Address string `mapstructure:"address"`
+	}
+}
+
+// redactedSecret replaces the secrets of the Redacted config.
+const redactedSecret = "[REDACTED]"
+
+// Redacted returns a copy of the Config with its secrets, the
+// database password, the JWT secret and the password of the
+// outbox webhook URL, replaced.
+func (c Config) Redacted() Config {
+	if c.DB.PASSWORD != "" {
+		c.DB.PASSWORD = redactedSecret
+	}
+
+	if c.JWT.Secret != "" {
+		c.JWT.Secret = redactedSecret
+	}
+
+	if u, err := url.Parse(c.OUTBOX.WebhookURL); err == nil {
+		c.OUTBOX.WebhookURL = u.Redacted()
+	}
+
+	return c
 }
 
 // ConfigFile stores the config filepath.
@@ -47,6 +47,20 @@ var (
 	// HTTP: 401
 	// GRPC: 7.
 	ErrorTypeUnauthorized = ErrorType{"unauthorized"}
+
+	// ErrorTypeAlreadyExists is used when a resource conflicts
+	// with an existing one.
+	// Maps to:
+	// HTTP: 409
+	// GRPC: 6.
+	ErrorTypeAlreadyExists = ErrorType{"already-exists"}
+
+	// ErrorTypeFailedPrecondition is used when a resource changed
+	// since the version the action was based on.
+	// Maps to:
+	// HTTP: 412
+	// GRPC: 9.
+	ErrorTypeFailedPrecondition = ErrorType{"failed-precondition"}
 )
 
 // ApplicationErrorCode holds error codes specific to the application.
@@ -223,6 +237,23 @@ func NewNotFoundErrorWithDetails(
 	return err
 }
 
+// NewAlreadyExistsError creates a new application Already Exists Error.
+func NewAlreadyExistsError(msg string) *Error {
+	return &Error{
+		t:   ErrorTypeAlreadyExists,
+		msg: msg,
+	}
+}
+
+// NewFailedPreconditionError creates a new application
+// Failed Precondition Error.
+func NewFailedPreconditionError(msg string) *Error {
+	return &Error{
+		t:   ErrorTypeFailedPrecondition,
+		msg: msg,
+	}
+}
+
 // NewInternalError creates a new application Internal Error.
 // This type of errors should never be shown to a user.
 func NewInternalError(msg string) *Error {
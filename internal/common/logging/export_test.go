@@ -0,0 +1,4 @@
+package logging
+
+// NewWithOutput is New writing to the given output.
+var NewWithOutput = newLogger
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Values accepted by the LOG.FORMAT config.
+const (
+	formatJSON    = "json"
+	formatConsole = "console"
+)
+
+// defaultRedactedFields are the fields redacted when
+// LOG.REDACT is empty.
+var defaultRedactedFields = []string{"email"}
+
+// New returns the logger configured in cfg, writing to stderr,
+// and the level it logs at, which can be changed at runtime.
+//
+// The logs of the standard library logger, like the ones of the
+// go-commons auth interceptor, are redirected to it at the debug
+// level.
+func New(cfg *config.Config) (*zap.Logger, zap.AtomicLevel, error) {
+	logger, level, err := newLogger(cfg, zapcore.Lock(os.Stderr))
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
+	}
+
+	_, err = zap.RedirectStdLogAt(logger.Named("stdlog"), zap.DebugLevel)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, fmt.Errorf("redirect std log: %w", err)
+	}
+
+	return logger, level, nil
+}
+
+func newLogger(
+	cfg *config.Config,
+	out zapcore.WriteSyncer,
+) (*zap.Logger, zap.AtomicLevel, error) {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+
+	if cfg.LOG.Level != "" {
+		err := level.UnmarshalText([]byte(cfg.LOG.Level))
+		if err != nil {
+			return nil, zap.AtomicLevel{}, fmt.Errorf("parse level: %w", err)
+		}
+	}
+
+	var encoder zapcore.Encoder
+
+	switch cfg.LOG.Format {
+	case formatJSON, "":
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+
+	case formatConsole:
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+
+	default:
+		return nil, zap.AtomicLevel{}, fmt.Errorf(
+			"unknown format %q",
+			cfg.LOG.Format,
+		)
+	}
+
+	redacted := cfg.LOG.Redact
+	if len(redacted) == 0 {
+		redacted = defaultRedactedFields
+	}
+
+	core := newRedactCore(zapcore.NewCore(encoder, out, level), redacted)
+
+	if cfg.LOG.SamplingInitial > 0 && cfg.LOG.SamplingThereafter > 0 {
+		core = zapcore.NewSamplerWithOptions(
+			core,
+			time.Second,
+			cfg.LOG.SamplingInitial,
+			cfg.LOG.SamplingThereafter,
+		)
+	}
+
+	return zap.New(
+		core,
+		zap.AddCaller(),
+		zap.AddStacktrace(zap.ErrorLevel),
+		zap.ErrorOutput(out),
+	), level, nil
+}
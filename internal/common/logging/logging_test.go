@@ -0,0 +1,214 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestLogger(
+	t *testing.T,
+	configure func(*config.Config),
+) (*zap.Logger, zap.AtomicLevel, *bytes.Buffer) {
+	t.Helper()
+
+	cfg := new(config.Config)
+	if configure != nil {
+		configure(cfg)
+	}
+
+	var buf bytes.Buffer
+
+	logger, level, err := logging.NewWithOutput(cfg, zapcore.AddSync(&buf))
+	is.New(t).NoErr(err)
+
+	return logger, level, &buf
+}
+
+// logLines returns the json logs written to buf.
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if l == "" {
+			continue
+		}
+
+		var line map[string]any
+
+		is.New(t).NoErr(json.Unmarshal([]byte(l), &line))
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestNew_Redact(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		redact []string
+		log    func(*zap.Logger)
+		fields map[string]any
+	}{
+		"DefaultEmail": {
+			log: func(l *zap.Logger) {
+				l.Info("msg", zap.String("email", "a@b.c"), zap.Int("n", 1))
+			},
+			fields: map[string]any{
+				"email": "[REDACTED]",
+				"n":     float64(1),
+			},
+		},
+		"Prefixed": {
+			log: func(l *zap.Logger) {
+				l.Info("msg", zap.String("grpc.request.Email", "a@b.c"))
+			},
+			fields: map[string]any{
+				"grpc.request.Email": "[REDACTED]",
+			},
+		},
+		"With": {
+			log: func(l *zap.Logger) {
+				l.With(zap.String("email", "a@b.c")).Info("msg")
+			},
+			fields: map[string]any{
+				"email": "[REDACTED]",
+			},
+		},
+		"Configured": {
+			redact: []string{"token"},
+			log: func(l *zap.Logger) {
+				l.Info(
+					"msg",
+					zap.String("token", "secret"),
+					zap.String("email", "a@b.c"),
+				)
+			},
+			fields: map[string]any{
+				"token": "[REDACTED]",
+				"email": "a@b.c",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			logger, _, buf := newTestLogger(t, func(cfg *config.Config) {
+				cfg.LOG.Redact = test.redact
+			})
+
+			test.log(logger)
+
+			lines := logLines(t, buf)
+			i.Equal(len(lines), 1)
+
+			for key, value := range test.fields {
+				i.Equal(lines[0][key], value) // field
+			}
+		})
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	logger, level, buf := newTestLogger(t, func(cfg *config.Config) {
+		cfg.LOG.Level = "warn"
+	})
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	level.SetLevel(zap.DebugLevel)
+
+	logger.Debug("kept after level change")
+
+	lines := logLines(t, buf)
+	i.Equal(len(lines), 2)
+	i.Equal(lines[0]["msg"], "kept")
+	i.Equal(lines[1]["msg"], "kept after level change")
+}
+
+func TestNew_Sampling(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	logger, _, buf := newTestLogger(t, func(cfg *config.Config) {
+		cfg.LOG.SamplingInitial = 2
+		cfg.LOG.SamplingThereafter = 3
+	})
+
+	for n := 0; n < 8; n++ {
+		logger.Info("repeated", zap.String("email", "a@b.c"))
+	}
+
+	// The first 2, then the 5th and the 8th.
+	lines := logLines(t, buf)
+	i.Equal(len(lines), 4)
+	i.Equal(lines[0]["email"], "[REDACTED]")
+}
+
+func TestNew_Console(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	logger, _, buf := newTestLogger(t, func(cfg *config.Config) {
+		cfg.LOG.Format = "console"
+	})
+
+	logger.Info("console", zap.String("email", "a@b.c"))
+
+	i.True(strings.Contains(buf.String(), "console"))
+	i.True(!strings.Contains(buf.String(), "a@b.c")) // email redacted
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(*config.Config){
+		"Level": func(cfg *config.Config) {
+			cfg.LOG.Level = "verbose"
+		},
+		"Format": func(cfg *config.Config) {
+			cfg.LOG.Format = "xml"
+		},
+	}
+
+	for name, configure := range tests {
+		configure := configure
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := new(config.Config)
+			configure(cfg)
+
+			_, _, err := logging.NewWithOutput(
+				cfg,
+				zapcore.AddSync(&bytes.Buffer{}),
+			)
+			is.New(t).True(err != nil)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package logging builds the zap logger of the application from
+// the LOG config, redacting the personal data of its fields.
+package logging
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces the values of the redacted fields.
+const redactedValue = "[REDACTED]"
+
+// redactCore is a zapcore.Core replacing the values of the fields
+// with a redacted name. A field is redacted when its name, or the
+// last dot separated part of it like in grpc.request.email, is
+// redacted, regardless of the case.
+type redactCore struct {
+	zapcore.Core
+	redacted map[string]struct{}
+}
+
+func newRedactCore(core zapcore.Core, fields []string) zapcore.Core {
+	redacted := make(map[string]struct{}, len(fields))
+
+	for _, f := range fields {
+		redacted[strings.ToLower(f)] = struct{}{}
+	}
+
+	return &redactCore{
+		Core:     core,
+		redacted: redacted,
+	}
+}
+
+// With redacts the fields before adding them to the core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{
+		Core:     c.Core.With(c.redact(fields)),
+		redacted: c.redacted,
+	}
+}
+
+// Check adds the redactCore, not the wrapped one, to the entry so
+// its fields go through Write.
+func (c *redactCore) Check(
+	entry zapcore.Entry,
+	checked *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+// Write redacts the fields before writing the entry.
+func (c *redactCore) Write(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	return c.Core.Write(entry, c.redact(fields))
+}
+
+// redact returns fields with the values of the redacted ones
+// replaced, it copies fields only when one is redacted.
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	var redactedFields []zapcore.Field
+
+	for i, f := range fields {
+		if !c.isRedacted(f.Key) {
+			continue
+		}
+
+		if redactedFields == nil {
+			redactedFields = make([]zapcore.Field, len(fields))
+			copy(redactedFields, fields)
+		}
+
+		redactedFields[i] = zap.String(f.Key, redactedValue)
+	}
+
+	if redactedFields == nil {
+		return fields
+	}
+
+	return redactedFields
+}
+
+func (c *redactCore) isRedacted(key string) bool {
+	key = strings.ToLower(key)
+
+	if _, ok := c.redacted[key]; ok {
+		return true
+	}
+
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		_, ok := c.redacted[key[i+1:]]
+
+		return ok
+	}
+
+	return false
+}
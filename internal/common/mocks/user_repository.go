@@ -11,6 +11,7 @@ import (
 
 var (
 	_ user.Repository          = (*UserRepository)(nil)
+	_ user.Importer            = (*UserRepository)(nil)
 	_ query.FindUsersReadModel = (*UserRepository)(nil)
 	_ query.UserByIDReadModel  = (*UserRepository)(nil)
 )
@@ -25,6 +26,26 @@ func (m *UserRepository) CreateUser(ctx context.Context, u *user.User) error {
 	return args.Error(0)
 }
 
+func (m *UserRepository) UpdateUser(
+	ctx context.Context,
+	id uuid.UUID,
+	updateFn func(ctx context.Context, u *user.User) (*user.User, error),
+) error {
+	args := m.Called(ctx, id, updateFn)
+
+	return args.Error(0)
+}
+
+func (m *UserRepository) ImportUsers(
+	ctx context.Context,
+	users []*user.User,
+	mode user.ImportMode,
+) ([]user.ImportResult, error) {
+	args := m.Called(ctx, users, mode)
+
+	return args.Get(0).([]user.ImportResult), args.Error(1)
+}
+
 func (m *UserRepository) FindUsers(
 	ctx context.Context,
 	filter user.Filter,
@@ -34,6 +55,16 @@ func (m *UserRepository) FindUsers(
 	return args.Get(0).([]query.User), args.Error(1)
 }
 
+func (m *UserRepository) UsersPage(
+	ctx context.Context,
+	after uuid.UUID,
+	limit int,
+) ([]query.User, error) {
+	args := m.Called(ctx, after, limit)
+
+	return args.Get(0).([]query.User), args.Error(1)
+}
+
 func (m *UserRepository) GetUserByID(
 	ctx context.Context,
 	id uuid.UUID,
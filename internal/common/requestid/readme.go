@@ -0,0 +1,3 @@
+// Package requestid identifies the request a log line, an error
+// or an outbound call belongs to.
+package requestid
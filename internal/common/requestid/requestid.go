@@ -0,0 +1,85 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// Header is the HTTP header holding the request id.
+	Header = "X-Request-Id"
+
+	// MetadataKey is the grpc metadata key holding the request id.
+	MetadataKey = "x-request-id"
+
+	// LogKey is the key of the log field holding the request id.
+	LogKey = "request_id"
+)
+
+// maxLen is the length of the longest request id accepted
+// from the clients.
+const maxLen = 128
+
+type contextKey struct{}
+
+// New returns a new request id.
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid reports whether the request id sent by a client is kept:
+// not empty, at most 128 characters long and made of printable
+// ASCII characters, so it can't break the log lines.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id carried by ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+
+	return id, ok && id != ""
+}
+
+// FromIncomingContext returns the valid request id sent
+// in the incoming grpc metadata, if any.
+func FromIncomingContext(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, id := range md.Get(MetadataKey) {
+		if Valid(id) {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// Field returns the log field holding the request id carried
+// by ctx, a skipped field when there is none.
+func Field(ctx context.Context) zap.Field {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return zap.Skip()
+	}
+
+	return zap.String(LogKey, id)
+}
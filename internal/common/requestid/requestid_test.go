@@ -0,0 +1,89 @@
+package requestid_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		id    string
+		valid bool
+	}{
+		"UUID": {
+			id:    "0b4c4f3a-2f4e-4d8c-9f7a-0e9c2b1c6d5e",
+			valid: true,
+		},
+		"Empty": {
+			id:    "",
+			valid: false,
+		},
+		"TooLong": {
+			id:    strings.Repeat("a", 129),
+			valid: false,
+		},
+		"NewLine": {
+			id:    "id\nforged log line",
+			valid: false,
+		},
+		"Space": {
+			id:    "request id",
+			valid: false,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			is.New(t).Equal(requestid.Valid(test.id), test.valid)
+		})
+	}
+}
+
+func TestFromIncomingContext(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	id, ok := requestid.FromIncomingContext(context.Background())
+	i.True(!ok)
+	i.Equal(id, "")
+
+	id, ok = requestid.FromIncomingContext(metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs(requestid.MetadataKey, "request-1"),
+	))
+	i.True(ok)
+	i.Equal(id, "request-1")
+
+	_, ok = requestid.FromIncomingContext(metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs(requestid.MetadataKey, "request 1"),
+	))
+	i.True(!ok)
+}
+
+func TestField(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	i.Equal(requestid.Field(context.Background()), zap.Skip())
+	i.Equal(
+		requestid.Field(
+			requestid.NewContext(context.Background(), "request-1"),
+		),
+		zap.String(requestid.LogKey, "request-1"),
+	)
+}
@@ -0,0 +1,3 @@
+// Package session identifies the client session
+// a request belongs to.
+package session
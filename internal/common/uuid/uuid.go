@@ -62,6 +62,18 @@ func (u UUID) Value() (driver.Value, error) {
 	return u.internalUUID.Value()
 }
 
+// MarshalText implements encoding.TextMarshaler and it's a wrapper
+// over the internal uuid MarshalText Implementation.
+func (u UUID) MarshalText() ([]byte, error) {
+	return u.internalUUID.MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and it's a wrapper
+// over the internal uuid UnmarshalText Implementation.
+func (u *UUID) UnmarshalText(data []byte) error {
+	return u.internalUUID.UnmarshalText(data)
+}
+
 // SetTestUUID sets the Reader from where the uuid
 // package reads random bytes to a bogus reader that always
 // returns the same data in order to provide deterministic ids.
@@ -0,0 +1,64 @@
+package user
+
+import (
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+)
+
+// AggregateType is the name under which the events recorded
+// by the User aggregate are published.
+const AggregateType = "user"
+
+// Names of the events recorded by the User aggregate.
+const (
+	EventNameUserCreated      = "user.created"
+	EventNameUserEmailChanged = "user.email_changed"
+	EventNameUserDeleted      = "user.deleted"
+)
+
+// Event represents something that happened to a User.
+//
+// Events are recorded on the aggregate when its state changes
+// and are persisted by the repository together with the aggregate.
+type Event interface {
+	// EventName returns the unique name of the event.
+	EventName() string
+}
+
+var (
+	_ Event = UserCreated{}
+	_ Event = UserEmailChanged{}
+	_ Event = UserDeleted{}
+)
+
+// UserCreated is recorded when a new user is added to the system.
+type UserCreated struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+}
+
+// EventName satisfies the Event interface.
+func (UserCreated) EventName() string {
+	return EventNameUserCreated
+}
+
+// UserEmailChanged is recorded when the user email is changed.
+type UserEmailChanged struct {
+	UserID   uuid.UUID `json:"user_id"`
+	OldEmail string    `json:"old_email"`
+	NewEmail string    `json:"new_email"`
+}
+
+// EventName satisfies the Event interface.
+func (UserEmailChanged) EventName() string {
+	return EventNameUserEmailChanged
+}
+
+// UserDeleted is recorded when the user is removed from the system.
+type UserDeleted struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// EventName satisfies the Event interface.
+func (UserDeleted) EventName() string {
+	return EventNameUserDeleted
+}
@@ -0,0 +1,168 @@
+package user
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+)
+
+// ImportMode tells what an import does with the users
+// that already exist.
+type ImportMode string
+
+// The available import modes.
+const (
+	// ImportModeSkip leaves the existing users untouched.
+	ImportModeSkip ImportMode = "skip"
+	// ImportModeUpsert replaces the email of the existing users.
+	ImportModeUpsert ImportMode = "upsert"
+)
+
+// ParseImportMode returns the import mode with the given name,
+// ImportModeSkip when the name is empty.
+func ParseImportMode(name string) (ImportMode, error) {
+	switch mode := ImportMode(name); mode {
+	case "":
+		return ImportModeSkip, nil
+
+	case ImportModeSkip, ImportModeUpsert:
+		return mode, nil
+
+	default:
+		return "", errors.NewInvalidError("import mode")
+	}
+}
+
+// ImportStatus tells what happened to an imported user.
+type ImportStatus string
+
+// The outcomes of importing a user.
+const (
+	ImportStatusCreated ImportStatus = "created"
+	ImportStatusUpdated ImportStatus = "updated"
+	ImportStatusSkipped ImportStatus = "skipped"
+	ImportStatusFailed  ImportStatus = "failed"
+)
+
+// ImportResult represents the outcome of importing a user.
+type ImportResult struct {
+	Status ImportStatus
+	// Err explains why the user failed to be imported.
+	Err error
+}
+
+// Importer defines the bulk import of users.
+type Importer interface {
+	// ImportUsers persists, in one transaction, the changes
+	// planned by PlanImport for the given users and returns
+	// the result of every user, in order.
+	ImportUsers(
+		ctx context.Context,
+		users []*User,
+		mode ImportMode,
+	) ([]ImportResult, error)
+}
+
+// StoredUser represents a user found in the repository
+// while planning an import, including the deleted ones.
+type StoredUser struct {
+	ID      uuid.UUID
+	Email   string
+	Version int64
+	Deleted bool
+}
+
+// ImportPlan represents the changes an import makes to the
+// stored users.
+type ImportPlan struct {
+	// Create holds the users to insert.
+	Create []*User
+	// Update holds the stored users whose email was replaced.
+	Update []*User
+	// Results holds the result of every imported user, in order.
+	Results []ImportResult
+}
+
+// PlanImport decides what happens to every imported user, given
+// the stored users sharing an id or an email with them.
+//
+// The new users are created. The existing ones are skipped, or,
+// in ImportModeUpsert, get the imported email. An email owned by
+// another user, a deleted user or a user repeated within the
+// same import fail the upsert.
+func PlanImport(
+	users []*User,
+	stored []StoredUser,
+	mode ImportMode,
+) ImportPlan {
+	var (
+		byID    = make(map[uuid.UUID]StoredUser, len(stored))
+		byEmail = make(map[string]uuid.UUID, len(stored))
+		touched = make(map[uuid.UUID]bool, len(users))
+		plan    = ImportPlan{Results: make([]ImportResult, len(users))}
+	)
+
+	for _, s := range stored {
+		byID[s.ID] = s
+		byEmail[s.Email] = s.ID
+	}
+
+	for i, u := range users {
+		s, idExists := byID[u.ID()]
+		owner, emailTaken := byEmail[u.Email()]
+
+		switch {
+		case !idExists && !emailTaken:
+			plan.Create = append(plan.Create, u)
+			plan.Results[i] = ImportResult{Status: ImportStatusCreated}
+
+			byID[u.ID()] = StoredUser{ID: u.ID(), Email: u.Email()}
+			byEmail[u.Email()] = u.ID()
+			touched[u.ID()] = true
+
+		case mode != ImportModeUpsert:
+			plan.Results[i] = ImportResult{Status: ImportStatusSkipped}
+
+		case touched[u.ID()] || (emailTaken && touched[owner]):
+			plan.Results[i] = failedImport(
+				errors.NewAlreadyExistsError("user repeated in import"),
+			)
+
+		case !idExists || (emailTaken && owner != u.ID()):
+			plan.Results[i] = failedImport(
+				errors.NewAlreadyExistsError("user"),
+			)
+
+		case s.Deleted:
+			plan.Results[i] = failedImport(
+				errors.NewInvalidError("user deleted"),
+			)
+
+		case s.Email == u.Email():
+			plan.Results[i] = ImportResult{Status: ImportStatusSkipped}
+
+		default:
+			updated := UnmarshalFromDatabase(s.ID, s.Email, s.Version)
+
+			// The email is known to be set and the user not deleted.
+			_ = updated.ChangeEmail(u.Email())
+
+			plan.Update = append(plan.Update, updated)
+			plan.Results[i] = ImportResult{Status: ImportStatusUpdated}
+
+			delete(byEmail, s.Email)
+			byEmail[u.Email()] = s.ID
+			touched[s.ID] = true
+		}
+	}
+
+	return plan
+}
+
+func failedImport(err error) ImportResult {
+	return ImportResult{
+		Status: ImportStatusFailed,
+		Err:    err,
+	}
+}
@@ -9,6 +9,18 @@ import (
 // Repository defines methods for User CRUD actions.
 type Repository interface {
 	CreateUser(ctx context.Context, u *User) error
+
+	// UpdateUser loads the user with the given id, applies updateFn
+	// and persists the result together with the recorded events.
+	//
+	// The user returned by updateFn is persisted only if its version
+	// is still the stored one, a failed precondition error is
+	// returned otherwise. The stored version is then incremented.
+	UpdateUser(
+		ctx context.Context,
+		id uuid.UUID,
+		updateFn func(ctx context.Context, u *User) (*User, error),
+	) error
 }
 
 // Filter represents the data that can be used for
@@ -5,10 +5,20 @@ import (
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
 )
 
+// InitialVersion is the version of a newly created user.
+const InitialVersion int64 = 1
+
 // User domain model.
+//
+// The version is incremented by the repository every time the
+// user is persisted, it is used to detect concurrent updates.
 type User struct {
-	id    uuid.UUID
-	email string
+	id      uuid.UUID
+	email   string
+	deleted bool
+	version int64
+
+	events []Event
 }
 
 // New instantiates a new user entity.
@@ -24,10 +34,18 @@ func New(
 		return nil, errors.NewEmailNotProvided()
 	}
 
-	return &User{
-		id:    id,
-		email: email,
-	}, nil
+	u := &User{
+		id:      id,
+		email:   email,
+		version: InitialVersion,
+	}
+
+	u.record(UserCreated{
+		UserID: id,
+		Email:  email,
+	})
+
+	return u, nil
 }
 
 // MustNew instantiates a new user entity.
@@ -56,6 +74,90 @@ func (u User) Email() string {
 	return u.email
 }
 
+// Version returns the version the user was loaded with.
+func (u User) Version() int64 {
+	return u.version
+}
+
+// CheckVersion returns a failed precondition error when the
+// expected version is set and differs from the user version.
+func (u User) CheckVersion(expected int64) error {
+	if expected != 0 && expected != u.version {
+		return errors.NewFailedPreconditionError("user version mismatch")
+	}
+
+	return nil
+}
+
+// IsDeleted flags if the user was removed from the system.
+func (u User) IsDeleted() bool {
+	return u.deleted
+}
+
+// ChangeEmail replaces the user email with the given one.
+func (u *User) ChangeEmail(email string) error {
+	if u.deleted {
+		return errors.NewInvalidError("user deleted")
+	}
+
+	if email == "" {
+		return errors.NewEmailNotProvided()
+	}
+
+	if email == u.email {
+		return nil
+	}
+
+	u.record(UserEmailChanged{
+		UserID:   u.id,
+		OldEmail: u.email,
+		NewEmail: email,
+	})
+
+	u.email = email
+
+	return nil
+}
+
+// Delete marks the user as removed from the system.
+func (u *User) Delete() error {
+	if u.deleted {
+		return errors.NewInvalidError("user already deleted")
+	}
+
+	u.deleted = true
+
+	u.record(UserDeleted{
+		UserID: u.id,
+	})
+
+	return nil
+}
+
+// Events returns the events recorded since the user was
+// instantiated or since the last call to PopEvents.
+//
+// It should be called by repositories when persisting the user,
+// they call PopEvents once the transaction is committed so the
+// events are saved again if it is retried.
+func (u *User) Events() []Event {
+	return u.events
+}
+
+// PopEvents returns the events recorded since the user was
+// instantiated or since the last call and clears them.
+func (u *User) PopEvents() []Event {
+	events := u.events
+
+	u.events = nil
+
+	return events
+}
+
+func (u *User) record(e Event) {
+	u.events = append(u.events, e)
+}
+
 // UnmarshalFromDatabase unmarshals User from the database.
 //
 // It should be used only for unmarshalling from the database!
@@ -63,9 +165,11 @@ func (u User) Email() string {
 func UnmarshalFromDatabase(
 	id uuid.UUID,
 	email string,
+	version int64,
 ) *User {
 	return &User{
-		id:    id,
-		email: email,
+		id:      id,
+		email:   email,
+		version: version,
 	}
 }
@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+)
+
+// DeliveryStatus represents the state of a Delivery.
+type DeliveryStatus struct {
+	s string
+}
+
+func (s DeliveryStatus) String() string {
+	return s.s
+}
+
+var (
+	// DeliveryStatusPending is used for deliveries waiting
+	// to be sent, either for the first time or retried.
+	DeliveryStatusPending = DeliveryStatus{"pending"}
+
+	// DeliveryStatusSucceeded is used for deliveries acknowledged
+	// by the subscription endpoint.
+	DeliveryStatusSucceeded = DeliveryStatus{"succeeded"}
+
+	// DeliveryStatusDead is used for deliveries that exhausted
+	// their attempts. They are only sent again on manual redelivery.
+	DeliveryStatusDead = DeliveryStatus{"dead"}
+)
+
+// ParseDeliveryStatus returns the DeliveryStatus with the given name.
+func ParseDeliveryStatus(s string) (DeliveryStatus, error) {
+	for _, status := range []DeliveryStatus{
+		DeliveryStatusPending,
+		DeliveryStatusSucceeded,
+		DeliveryStatusDead,
+	} {
+		if status.s == s {
+			return status, nil
+		}
+	}
+
+	return DeliveryStatus{}, errors.NewInvalidError("delivery status")
+}
+
+// Delivery represents an event sent, or to be sent,
+// to a subscription.
+type Delivery struct {
+	id             uuid.UUID
+	subscriptionID uuid.UUID
+	eventID        int64
+	eventType      string
+	payload        []byte
+	occurredAt     time.Time
+
+	status         DeliveryStatus
+	attempts       int
+	nextAttemptAt  time.Time
+	lastStatusCode int
+	lastError      string
+	deliveredAt    *time.Time
+}
+
+// NewDelivery instantiates a new pending delivery of the event
+// to the given subscription.
+func NewDelivery(
+	id uuid.UUID,
+	subscriptionID uuid.UUID,
+	eventID int64,
+	eventType string,
+	payload []byte,
+	occurredAt time.Time,
+) (*Delivery, error) {
+	if id.IsZero() {
+		return nil, errors.NewInvalidError("delivery id")
+	}
+
+	if subscriptionID.IsZero() {
+		return nil, errors.NewInvalidError("delivery subscription id")
+	}
+
+	if eventType == "" {
+		return nil, errors.NewInvalidError("delivery event type")
+	}
+
+	return &Delivery{
+		id:             id,
+		subscriptionID: subscriptionID,
+		eventID:        eventID,
+		eventType:      eventType,
+		payload:        payload,
+		occurredAt:     occurredAt,
+		status:         DeliveryStatusPending,
+		nextAttemptAt:  occurredAt,
+	}, nil
+}
+
+// ID returns the delivery ID.
+func (d Delivery) ID() uuid.UUID {
+	return d.id
+}
+
+// SubscriptionID returns the ID of the subscription the
+// event is delivered to.
+func (d Delivery) SubscriptionID() uuid.UUID {
+	return d.subscriptionID
+}
+
+// EventID returns the ID of the delivered event.
+func (d Delivery) EventID() int64 {
+	return d.eventID
+}
+
+// EventType returns the type of the delivered event.
+func (d Delivery) EventType() string {
+	return d.eventType
+}
+
+// Payload returns the JSON payload of the delivered event.
+func (d Delivery) Payload() []byte {
+	return d.payload
+}
+
+// OccurredAt returns the time the event occurred at.
+func (d Delivery) OccurredAt() time.Time {
+	return d.occurredAt
+}
+
+// Status returns the delivery status.
+func (d Delivery) Status() DeliveryStatus {
+	return d.status
+}
+
+// Attempts returns how many times the delivery was sent.
+func (d Delivery) Attempts() int {
+	return d.attempts
+}
+
+// NextAttemptAt returns when the delivery should be sent next.
+func (d Delivery) NextAttemptAt() time.Time {
+	return d.nextAttemptAt
+}
+
+// LastStatusCode returns the HTTP status code received on the
+// last attempt, 0 if no response was received.
+func (d Delivery) LastStatusCode() int {
+	return d.lastStatusCode
+}
+
+// LastError returns the reason the last attempt failed.
+func (d Delivery) LastError() string {
+	return d.lastError
+}
+
+// DeliveredAt returns when the delivery succeeded, if it did.
+func (d Delivery) DeliveredAt() *time.Time {
+	return d.deliveredAt
+}
+
+// RecordSuccess records an attempt acknowledged by the endpoint.
+func (d *Delivery) RecordSuccess(statusCode int, at time.Time) {
+	d.attempts++
+	d.status = DeliveryStatusSucceeded
+	d.lastStatusCode = statusCode
+	d.lastError = ""
+	d.deliveredAt = &at
+}
+
+// RecordFailure records a failed attempt. The delivery is retried
+// at retryAt, or moved to the dead state once maxAttempts are used.
+func (d *Delivery) RecordFailure(
+	statusCode int,
+	cause string,
+	retryAt time.Time,
+	maxAttempts int,
+) {
+	d.attempts++
+	d.lastStatusCode = statusCode
+	d.lastError = cause
+	d.nextAttemptAt = retryAt
+
+	if d.attempts >= maxAttempts {
+		d.status = DeliveryStatusDead
+	}
+}
+
+// Redeliver schedules the delivery to be sent again at the given time,
+// regardless of its previous outcome.
+func (d *Delivery) Redeliver(at time.Time) error {
+	if d.status == DeliveryStatusPending {
+		return errors.NewInvalidError("delivery already pending")
+	}
+
+	d.status = DeliveryStatusPending
+	d.nextAttemptAt = at
+	d.attempts = 0
+
+	return nil
+}
+
+// UnmarshalDeliveryFromDatabase unmarshals Delivery from the database.
+//
+// It should be used only for unmarshalling from the database!
+// You can't use it as a constructor - It may put domain into the invalid state!
+func UnmarshalDeliveryFromDatabase(
+	id uuid.UUID,
+	subscriptionID uuid.UUID,
+	eventID int64,
+	eventType string,
+	payload []byte,
+	occurredAt time.Time,
+	status DeliveryStatus,
+	attempts int,
+	nextAttemptAt time.Time,
+	lastStatusCode int,
+	lastError string,
+	deliveredAt *time.Time,
+) *Delivery {
+	return &Delivery{
+		id:             id,
+		subscriptionID: subscriptionID,
+		eventID:        eventID,
+		eventType:      eventType,
+		payload:        payload,
+		occurredAt:     occurredAt,
+		status:         status,
+		attempts:       attempts,
+		nextAttemptAt:  nextAttemptAt,
+		lastStatusCode: lastStatusCode,
+		lastError:      lastError,
+		deliveredAt:    deliveredAt,
+	}
+}
@@ -0,0 +1,4 @@
+// Package webhook holds the definition of the webhook
+// subscriptions partners use to be notified about changes
+// in the system, and of the deliveries sent to them.
+package webhook
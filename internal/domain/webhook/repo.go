@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+)
+
+// Repository defines methods for webhook subscriptions and
+// deliveries CRUD actions.
+type Repository interface {
+	CreateSubscription(ctx context.Context, s *Subscription) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+
+	// MatchingSubscriptions returns the subscriptions that want
+	// to receive events of the given type.
+	MatchingSubscriptions(
+		ctx context.Context,
+		eventType string,
+	) ([]*Subscription, error)
+
+	// CreateDeliveries stores the given deliveries, skipping the ones
+	// for an event already delivered to the same subscription.
+	CreateDeliveries(ctx context.Context, deliveries []*Delivery) error
+
+	// UpdateDelivery loads the delivery with the given id, applies
+	// updateFn and persists the result.
+	UpdateDelivery(
+		ctx context.Context,
+		id uuid.UUID,
+		updateFn func(ctx context.Context, d *Delivery) (*Delivery, error),
+	) error
+}
+
+// DeliveryFilter represents the data that can be used for
+// filtering deliveries found in the repository.
+type DeliveryFilter struct {
+	SubscriptionID uuid.UUID
+	Status         *DeliveryStatus
+}
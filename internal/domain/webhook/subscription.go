@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+)
+
+// AllEvents can be used as an event type to subscribe to all events.
+const AllEvents = "*"
+
+// Subscription represents an endpoint that wants to receive
+// the events of the given types.
+type Subscription struct {
+	id         uuid.UUID
+	url        string
+	eventTypes []string
+	secret     string
+}
+
+// NewSubscription instantiates a new webhook subscription.
+// The secret is used to sign the payloads sent to the url, which
+// must be an https one.
+func NewSubscription(
+	id uuid.UUID,
+	endpoint string,
+	eventTypes []string,
+	secret string,
+) (*Subscription, error) {
+	if id.IsZero() {
+		return nil, errors.NewInvalidError("subscription id")
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, errors.NewInvalidError("subscription url must be https")
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, errors.NewInvalidError("subscription event types")
+	}
+
+	for _, t := range eventTypes {
+		if t == "" {
+			return nil, errors.NewInvalidError("subscription event type")
+		}
+	}
+
+	const minSecretLength = 16
+
+	if len(secret) < minSecretLength {
+		return nil, errors.NewInvalidError("subscription secret too short")
+	}
+
+	return &Subscription{
+		id:         id,
+		url:        endpoint,
+		eventTypes: eventTypes,
+		secret:     secret,
+	}, nil
+}
+
+// ID returns the subscription ID.
+func (s Subscription) ID() uuid.UUID {
+	return s.id
+}
+
+// URL returns the endpoint the events are delivered to.
+func (s Subscription) URL() string {
+	return s.url
+}
+
+// EventTypes returns the types of the events the subscription
+// wants to receive.
+func (s Subscription) EventTypes() []string {
+	return s.eventTypes
+}
+
+// Secret returns the secret used to sign the payloads.
+func (s Subscription) Secret() string {
+	return s.secret
+}
+
+// Matches flags if the event type should be delivered
+// to the subscription.
+func (s Subscription) Matches(eventType string) bool {
+	for _, t := range s.eventTypes {
+		if t == AllEvents || t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnmarshalSubscriptionFromDatabase unmarshals Subscription from the database.
+//
+// It should be used only for unmarshalling from the database!
+// You can't use it as a constructor - It may put domain into the invalid state!
+func UnmarshalSubscriptionFromDatabase(
+	id uuid.UUID,
+	endpoint string,
+	eventTypes []string,
+	secret string,
+) *Subscription {
+	return &Subscription{
+		id:         id,
+		url:        endpoint,
+		eventTypes: eventTypes,
+		secret:     secret,
+	}
+}
+
+// GenerateSecret returns a random secret that can be used
+// to sign the payloads of a subscription.
+func GenerateSecret() (string, error) {
+	const secretBytes = 32
+
+	b := make([]byte, secretBytes)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
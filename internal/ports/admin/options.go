@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type options struct {
+	logLevel          *zap.AtomicLevel
+	config            any
+	dbStats           func() map[string]sql.DBStats
+	readHeaderTimeout time.Duration
+}
+
+func defaultOptions() options {
+	const readHeaderTimeout = 10 * time.Second
+
+	return options{
+		logLevel:          nil,
+		config:            nil,
+		dbStats:           nil,
+		readHeaderTimeout: readHeaderTimeout,
+	}
+}
+
+// Option configures the Server.
+type Option interface {
+	apply(*options)
+}
+
+type funcOption struct {
+	f func(*options)
+}
+
+func (f *funcOption) apply(o *options) {
+	f.f(o)
+}
+
+func newFuncOption(f func(*options)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+// WithLogLevel serves the given level at LogLevelPath.
+func WithLogLevel(level zap.AtomicLevel) Option {
+	return newFuncOption(func(o *options) {
+		o.logLevel = &level
+	})
+}
+
+// WithConfig serves the given config as JSON at ConfigPath, it
+// should have its secrets redacted.
+func WithConfig(cfg any) Option {
+	return newFuncOption(func(o *options) {
+		o.config = cfg
+	})
+}
+
+// WithDBStats serves the statistics of the database connection
+// pools returned by stats, by pool name, at DBPath.
+func WithDBStats(stats func() map[string]sql.DBStats) Option {
+	return newFuncOption(func(o *options) {
+		o.dbStats = stats
+	})
+}
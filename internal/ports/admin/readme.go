@@ -0,0 +1,3 @@
+// Package admin implements the admin HTTP server, meant to listen
+// to a private address, to operate the running application.
+package admin
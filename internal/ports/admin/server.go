@@ -0,0 +1,167 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/buildinfo"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+)
+
+// The paths of the admin endpoints.
+const (
+	// LogLevelPath is the path of the log level endpoint, GET
+	// returns the level as {"level":"info"} and PUT changes it,
+	// taking the same JSON body or a level form value.
+	LogLevelPath = "/log/level"
+	PprofPath    = "/debug/pprof/"
+	VarsPath     = "/debug/vars"
+	BuildPath    = "/debug/build"
+	ConfigPath   = "/debug/config"
+	DBPath       = "/debug/db"
+)
+
+// shutdownTimeout is how long Close waits for the
+// requests in flight.
+const shutdownTimeout = 5 * time.Second
+
+// Server is the admin HTTP server. Next to its HTTP endpoints, it
+// serves the gRPC channelz service, over HTTP/2 without TLS, to
+// inspect the gRPC servers and connections of the process.
+type Server struct {
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	listener   net.Listener
+}
+
+// NewServer returns a Server listening to the given address,
+// Serve serves its requests.
+//
+// It turns channelz on, so it should be created before the gRPC
+// servers and connections to inspect.
+func NewServer(address string, opts ...Option) (*Server, error) {
+	options := defaultOptions()
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	grpcServer := grpc.NewServer()
+	channelz.RegisterChannelzServiceToServer(grpcServer)
+
+	router := newRouter(options)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isGRPCRequest(r) {
+			grpcServer.ServeHTTP(w, r)
+
+			return
+		}
+
+		router.ServeHTTP(w, r)
+	})
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	return &Server{
+		httpServer: &http.Server{
+			Handler:           h2c.NewHandler(handler, &http2.Server{}),
+			ReadHeaderTimeout: options.readHeaderTimeout,
+		},
+		grpcServer: grpcServer,
+		listener:   lis,
+	}, nil
+}
+
+func newRouter(options options) http.Handler {
+	router := chi.NewRouter()
+
+	router.HandleFunc(PprofPath+"*", pprof.Index)
+	router.HandleFunc(PprofPath+"cmdline", pprof.Cmdline)
+	router.HandleFunc(PprofPath+"profile", pprof.Profile)
+	router.HandleFunc(PprofPath+"symbol", pprof.Symbol)
+	router.HandleFunc(PprofPath+"trace", pprof.Trace)
+
+	router.Handle(VarsPath, expvar.Handler())
+
+	router.Get(BuildPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, buildinfo.Read())
+	})
+
+	if options.logLevel != nil {
+		router.Handle(LogLevelPath, options.logLevel)
+	}
+
+	if options.config != nil {
+		router.Get(ConfigPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, options.config)
+		})
+	}
+
+	if options.dbStats != nil {
+		router.Get(DBPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, options.dbStats())
+		})
+	}
+
+	return router
+}
+
+// Addr returns the address the Server listens to.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve serves the requests until the Server is closed.
+func (s *Server) Serve() error {
+	err := s.httpServer.Serve(s.listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Close stops the Server, waiting for the requests in flight.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		shutdownTimeout,
+	)
+	defer cancel()
+
+	err := s.httpServer.Shutdown(ctx)
+
+	s.grpcServer.Stop()
+
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	_ = enc.Encode(v)
+}
+
+func isGRPCRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 &&
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
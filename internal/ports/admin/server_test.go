@@ -0,0 +1,192 @@
+package admin_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/admin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newTestServer returns the base URL of a served Server.
+func newTestServer(t *testing.T, opts ...admin.Option) (string, *admin.Server) {
+	t.Helper()
+
+	i := is.New(t)
+
+	server, err := admin.NewServer("127.0.0.1:0", opts...)
+	i.NoErr(err)
+
+	go func() {
+		_ = server.Serve()
+	}()
+
+	t.Cleanup(func() {
+		i.NoErr(server.Close())
+	})
+
+	return "http://" + server.Addr().String(), server
+}
+
+func get(t *testing.T, url string) (int, []byte) {
+	t.Helper()
+
+	i := is.New(t)
+
+	resp, err := http.Get(url)
+	i.NoErr(err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	i.NoErr(err)
+
+	return resp.StatusCode, body
+}
+
+func TestServer_LogLevel(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+
+	baseURL, _ := newTestServer(t, admin.WithLogLevel(level))
+
+	url := baseURL + admin.LogLevelPath
+
+	req, err := http.NewRequest(
+		http.MethodPut,
+		url,
+		strings.NewReader(`{"level":"debug"}`),
+	)
+	i.NoErr(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	i.NoErr(err)
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	i.Equal(resp.StatusCode, http.StatusOK)
+	i.Equal(level.Level(), zap.DebugLevel)
+
+	code, body := get(t, url)
+	i.Equal(code, http.StatusOK)
+
+	var payload struct {
+		Level string `json:"level"`
+	}
+
+	i.NoErr(json.Unmarshal(body, &payload))
+	i.Equal(payload.Level, "debug")
+}
+
+func TestServer_Debug(t *testing.T) {
+	t.Parallel()
+
+	baseURL, _ := newTestServer(
+		t,
+		admin.WithConfig(map[string]string{"secret": "[REDACTED]"}),
+		admin.WithDBStats(func() map[string]sql.DBStats {
+			return map[string]sql.DBStats{
+				"primary": {OpenConnections: 2},
+			}
+		}),
+	)
+
+	tests := map[string]struct {
+		path     string
+		contains string
+	}{
+		"Pprof": {
+			path:     admin.PprofPath,
+			contains: "goroutine",
+		},
+		"PprofProfile": {
+			path:     admin.PprofPath + "goroutine?debug=1",
+			contains: "goroutine profile",
+		},
+		"Vars": {
+			path:     admin.VarsPath,
+			contains: `"memstats"`,
+		},
+		"Build": {
+			path:     admin.BuildPath,
+			contains: `"go_version": "go`,
+		},
+		"Config": {
+			path:     admin.ConfigPath,
+			contains: `"secret": "[REDACTED]"`,
+		},
+		"DB": {
+			path:     admin.DBPath,
+			contains: `"OpenConnections": 2`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			code, body := get(t, baseURL+test.path)
+			i.Equal(code, http.StatusOK)
+			i.True(strings.Contains(string(body), test.contains)) // body
+		})
+	}
+}
+
+func TestServer_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	baseURL, _ := newTestServer(t)
+
+	for _, path := range []string{
+		admin.LogLevelPath,
+		admin.ConfigPath,
+		admin.DBPath,
+	} {
+		code, _ := get(t, baseURL+path)
+		is.New(t).Equal(code, http.StatusNotFound) // path
+	}
+}
+
+func TestServer_Channelz(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	_, server := newTestServer(t)
+
+	conn, err := grpc.Dial(
+		server.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	i.NoErr(err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	resp, err := channelzpb.NewChannelzClient(conn).GetTopChannels(
+		context.Background(),
+		&channelzpb.GetTopChannelsRequest{},
+	)
+	i.NoErr(err)
+
+	// The connection of the test is a top channel.
+	i.True(len(resp.Channel) > 0)
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
 
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
 )
 
 // CreateUser adds a new user to the system.
@@ -29,9 +31,10 @@ func (s *Server) CreateUser(
 	}
 
 	return &startergrpc.CreateUserResponse{
-		User: &startergrpc.User{
-			Id:    newUserID.String(),
-			Email: req.Email,
-		},
+		User: userToProto(query.User{
+			ID:      newUserID,
+			Email:   req.Email,
+			Version: user.InitialVersion,
+		}),
 	}, nil
 }
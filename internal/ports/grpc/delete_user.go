@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/auth"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+)
+
+// DeleteUser removes the authenticated user.
+func (s *Server) DeleteUser(
+	ctx context.Context,
+	req *startergrpc.DeleteUserRequest,
+) (*emptypb.Empty, error) {
+	u, err := auth.UUIDFromContextJWT(ctx, s.jwtManager)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"authenticate user: %w",
+			err,
+		)
+	}
+
+	if u.String() != req.Id {
+		return nil, errors.NewUnauthorizedError("user")
+	}
+
+	userID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, errors.NewInvalidError("invalid user id")
+	}
+
+	version := req.Version
+
+	if version == 0 {
+		version, err = versionFromIfMatch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("version from if match: %w", err)
+		}
+	}
+
+	err = s.app.Commands.DeleteUser.Handle(ctx, command.DeleteUser{
+		ID:              userID,
+		ExpectedVersion: version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"delete user command: %w",
+			err,
+		)
+	}
+
+	return &emptypb.Empty{}, nil
+}
@@ -39,6 +39,11 @@ func (s Server) handleErr(err error) error {
 		// message.
 		details = errorDetailsToGRPCDetails(applicationError)
 
+	// If the error already is a grpc status, like the ones of the
+	// invalid requests, return it as is.
+	case isStatusErr(err):
+		return err
+
 	// If the error is an internal error, report it to an external
 	// service.
 	default:
@@ -94,6 +99,15 @@ func errorToGRPCStatus(err *errors.Error) *status.Status {
 	case errors.ErrorTypeNotFound:
 		code = codes.NotFound
 
+	case errors.ErrorTypeUnauthorized:
+		code = codes.PermissionDenied
+
+	case errors.ErrorTypeAlreadyExists:
+		code = codes.AlreadyExists
+
+	case errors.ErrorTypeFailedPrecondition:
+		code = codes.FailedPrecondition
+
 	default:
 		code = codes.Unknown
 	}
@@ -124,6 +138,13 @@ func errorDetailsToGRPCDetails(d *errors.Error) *startergrpc.ErrorResponse {
 	}
 }
 
+// isStatusErr tells whether the error was created by the status package.
+func isStatusErr(err error) bool {
+	_, ok := status.FromError(err)
+
+	return ok
+}
+
 // logErr logs the error.
 func (s Server) logErr(err error) {
 	s.logger.Error("grpc", zap.Error(err))
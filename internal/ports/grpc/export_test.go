@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// ReadMaskInterceptor exports readMaskInterceptor for the tests.
+var ReadMaskInterceptor = readMaskInterceptor
+
+// GatewayMuxOptions exports gatewayMuxOptions for the tests.
+var GatewayMuxOptions = gatewayMuxOptions
+
+// MaxImportUsers exports maxImportUsers for the tests.
+const MaxImportUsers = maxImportUsers
+
+// HTTPMiddlewares exports httpMiddlewares for the tests.
+var HTTPMiddlewares = httpMiddlewares
+
+// WebProtocols exports webProtocols for the tests.
+func (s *Server) WebProtocols(next http.Handler) http.Handler {
+	return s.webProtocols(next)
+}
+
+// HTTPAddr returns the address of the port served
+// in the single port mode.
+func (s *Server) HTTPAddr() string {
+	return s.httpListener.Addr().String()
+}
+
+// GatewayConnState returns the state of the connection
+// of the gateway to the grpc server.
+func (s *Server) GatewayConnState() connectivity.State {
+	return s.gatewayConn.GetState()
+}
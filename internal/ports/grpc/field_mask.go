@@ -0,0 +1,202 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// readMasked is implemented by the requests accepting a read mask.
+type readMasked interface {
+	GetReadMask() *fieldmaskpb.FieldMask
+}
+
+// readMaskInterceptor applies the read_mask of the request to the
+// resources of its response, the message fields of the response
+// message, keeping only the fields named by the mask.
+//
+// The paths of the mask are relative to the resource and are checked
+// before the handler runs. An empty mask returns the whole resources.
+func readMaskInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	masked, ok := req.(readMasked)
+	if !ok || len(masked.GetReadMask().GetPaths()) == 0 {
+		return handler(ctx, req)
+	}
+
+	paths := masked.GetReadMask().GetPaths()
+
+	resources, err := methodResources(info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if !validResourcePath(resources, path) {
+			return nil, invalidRequestError(
+				&errdetails.BadRequest_FieldViolation{
+					Field:       "read_mask",
+					Description: "must not contain " + path,
+				},
+			)
+		}
+	}
+
+	res, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, ok := res.(proto.Message)
+	if !ok {
+		return res, nil
+	}
+
+	tree := newMaskTree(paths)
+
+	m := msg.ProtoReflect()
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.Message() == nil || fd.IsMap():
+
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				tree.prune(v.List().Get(i).Message())
+			}
+
+		default:
+			tree.prune(v.Message())
+		}
+
+		return true
+	})
+
+	return res, nil
+}
+
+// methodResources returns the descriptors of the resources
+// returned by the method.
+func methodResources(
+	fullMethod string,
+) ([]protoreflect.MessageDescriptor, error) {
+	// The full method is formatted as /package.Service/Method.
+	name := strings.Replace(strings.TrimPrefix(fullMethod, "/"), "/", ".", 1)
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(
+		protoreflect.FullName(name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find method descriptor: %w", err)
+	}
+
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a method", name)
+	}
+
+	var (
+		resources []protoreflect.MessageDescriptor
+		fields    = method.Output().Fields()
+	)
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		if fd.Message() != nil && !fd.IsMap() {
+			resources = append(resources, fd.Message())
+		}
+	}
+
+	return resources, nil
+}
+
+// validResourcePath tells whether the path names a field
+// of one of the resources.
+func validResourcePath(
+	resources []protoreflect.MessageDescriptor,
+	path string,
+) bool {
+	for _, md := range resources {
+		if validPath(md, strings.Split(path, ".")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validPath(md protoreflect.MessageDescriptor, names []string) bool {
+	fd := md.Fields().ByName(protoreflect.Name(names[0]))
+	if fd == nil {
+		return false
+	}
+
+	if len(names) == 1 {
+		return true
+	}
+
+	if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+		return false
+	}
+
+	return validPath(fd.Message(), names[1:])
+}
+
+// maskTree holds the paths of a field mask by their field names,
+// a field without children is kept whole.
+type maskTree map[protoreflect.Name]maskTree
+
+func newMaskTree(paths []string) maskTree {
+	tree := make(maskTree)
+
+	for _, path := range paths {
+		node := tree
+
+		for _, name := range strings.Split(path, ".") {
+			child, ok := node[protoreflect.Name(name)]
+			if !ok {
+				child = make(maskTree)
+				node[protoreflect.Name(name)] = child
+			}
+
+			node = child
+		}
+	}
+
+	return tree
+}
+
+// prune clears the fields of the message not named by the tree.
+func (t maskTree) prune(m protoreflect.Message) {
+	var cleared []protoreflect.FieldDescriptor
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		child, ok := t[fd.Name()]
+
+		switch {
+		case !ok:
+			cleared = append(cleared, fd)
+
+		case len(child) > 0 && fd.Message() != nil:
+			child.prune(v.Message())
+		}
+
+		return true
+	})
+
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
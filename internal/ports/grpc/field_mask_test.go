@@ -0,0 +1,305 @@
+package grpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	portsgrpc "github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const testUserID = "b028f046-787c-4a3c-adfd-b838c15b9509"
+
+// fullMethod returns the full name of the GoStarter method.
+func fullMethod(method string) string {
+	return "/" + startergrpc.GoStarter_ServiceDesc.ServiceName + "/" + method
+}
+
+func TestReadMaskInterceptor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+
+	newUser := func() *startergrpc.User {
+		return &startergrpc.User{
+			Id:      proto.String(testUserID),
+			Email:   proto.String("user@email.com"),
+			Version: proto.Int64(2),
+		}
+	}
+
+	tests := map[string]struct {
+		method   string
+		req      any
+		res      any
+		expected any
+	}{
+		"GetUser": {
+			method: "GetUser",
+			req: &startergrpc.GetUserRequest{
+				Id: testUserID,
+				ReadMask: &fieldmaskpb.FieldMask{
+					Paths: []string{"email"},
+				},
+			},
+			res: &startergrpc.GetUserResponse{User: newUser()},
+			expected: &startergrpc.GetUserResponse{
+				User: &startergrpc.User{
+					Email: proto.String("user@email.com"),
+				},
+			},
+		},
+		"GetUser_NoMask": {
+			method:   "GetUser",
+			req:      &startergrpc.GetUserRequest{Id: testUserID},
+			res:      &startergrpc.GetUserResponse{User: newUser()},
+			expected: &startergrpc.GetUserResponse{User: newUser()},
+		},
+		"FindUsers": {
+			method: "FindUsers",
+			req: &startergrpc.FindUsersRequest{
+				ReadMask: &fieldmaskpb.FieldMask{
+					Paths: []string{"id", "version"},
+				},
+			},
+			res: &startergrpc.FindUsersResponse{
+				Users: []*startergrpc.User{newUser(), newUser()},
+			},
+			expected: &startergrpc.FindUsersResponse{
+				Users: []*startergrpc.User{
+					{Id: proto.String(testUserID), Version: proto.Int64(2)},
+					{Id: proto.String(testUserID), Version: proto.Int64(2)},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			res, err := portsgrpc.ReadMaskInterceptor(
+				ctx,
+				test.req,
+				&grpc.UnaryServerInfo{FullMethod: fullMethod(test.method)},
+				func(context.Context, any) (any, error) {
+					return test.res, nil
+				},
+			)
+			i.NoErr(err)
+
+			i.True(proto.Equal(
+				test.expected.(proto.Message),
+				res.(proto.Message),
+			))
+		})
+	}
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		_, err := portsgrpc.ReadMaskInterceptor(
+			ctx,
+			&startergrpc.GetUserRequest{
+				Id: testUserID,
+				ReadMask: &fieldmaskpb.FieldMask{
+					Paths: []string{"email", "password"},
+				},
+			},
+			&grpc.UnaryServerInfo{FullMethod: fullMethod("GetUser")},
+			func(context.Context, any) (any, error) {
+				t.Fatal("handler called")
+
+				return nil, nil
+			},
+		)
+
+		i.Equal(
+			map[string]string{"read_mask": "must not contain password"},
+			fieldViolations(t, err),
+		)
+	})
+}
+
+func TestServer_UpdateUserMask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+
+	_, conn := newTestServer(t, app.Application{})
+
+	client := startergrpc.NewGoStarterClient(conn)
+
+	tests := map[string]struct {
+		req        *startergrpc.UpdateUserRequest
+		violations map[string]string
+	}{
+		"UnknownPath": {
+			req: &startergrpc.UpdateUserRequest{
+				Id:   testUserID,
+				User: &startergrpc.User{},
+				UpdateMask: &fieldmaskpb.FieldMask{
+					Paths: []string{"email", "password"},
+				},
+			},
+			violations: map[string]string{
+				"update_mask": "must not contain password",
+			},
+		},
+		"ImmutablePaths": {
+			req: &startergrpc.UpdateUserRequest{
+				Id:   testUserID,
+				User: &startergrpc.User{},
+				UpdateMask: &fieldmaskpb.FieldMask{
+					Paths: []string{"id", "version"},
+				},
+			},
+			violations: map[string]string{
+				"update_mask": "must contain a mutable field",
+			},
+		},
+		"OtherUserID": {
+			req: &startergrpc.UpdateUserRequest{
+				Id: testUserID,
+				User: &startergrpc.User{
+					Id: proto.String("b028f046-787c-4a3c-adfd-b838c15b9500"),
+				},
+			},
+			violations: map[string]string{
+				"user.id": "must be the id of the user",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			_, err := client.UpdateUser(ctx, test.req)
+
+			i.Equal(test.violations, fieldViolations(t, err))
+		})
+	}
+}
+
+// updateUserRecorder records the UpdateUser requests
+// received from the gateway.
+type updateUserRecorder struct {
+	startergrpc.UnimplementedGoStarterServer
+
+	req *startergrpc.UpdateUserRequest
+}
+
+func (r *updateUserRecorder) UpdateUser(
+	_ context.Context,
+	req *startergrpc.UpdateUserRequest,
+) (*startergrpc.UpdateUserResponse, error) {
+	r.req = req
+
+	return &startergrpc.UpdateUserResponse{User: req.GetUser()}, nil
+}
+
+func TestGateway_UpdateUserMask(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		target   string
+		body     string
+		expected []string
+	}{
+		"MaskFromBody": {
+			target:   "/v1/users/" + testUserID,
+			body:     `{"email": "new@email.com"}`,
+			expected: []string{"email"},
+		},
+		"MaskFromQuery": {
+			target:   "/v1/users/" + testUserID + "?update_mask=version",
+			body:     `{"email": "new@email.com", "version": "1"}`,
+			expected: []string{"version"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			var (
+				recorder = &updateUserRecorder{}
+				mux      = runtime.NewServeMux()
+			)
+
+			i.NoErr(startergrpc.RegisterGoStarterHandlerServer(
+				context.TODO(),
+				mux,
+				recorder,
+			))
+
+			req := httptest.NewRequest(
+				http.MethodPatch,
+				test.target,
+				strings.NewReader(test.body),
+			)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			i.Equal(http.StatusOK, rec.Code)
+			i.Equal(testUserID, recorder.req.GetId())
+			i.Equal("new@email.com", recorder.req.GetUser().GetEmail())
+			i.Equal(test.expected, recorder.req.GetUpdateMask().GetPaths())
+		})
+	}
+}
+
+// fieldViolations returns the field violations of the
+// InvalidArgument error by field.
+func fieldViolations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	i := is.New(t)
+
+	st, ok := status.FromError(err)
+	i.True(ok)
+	i.Equal(codes.InvalidArgument, st.Code())
+
+	byField := make(map[string]string)
+
+	for _, d := range st.Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		for _, v := range br.GetFieldViolations() {
+			byField[v.GetField()] = v.GetDescription()
+		}
+	}
+
+	return byField
+}
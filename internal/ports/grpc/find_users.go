@@ -8,13 +8,12 @@ import (
 
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/auth"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
-	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // FindUsers queries the system for users.
 func (s *Server) FindUsers(
 	ctx context.Context,
-	_ *emptypb.Empty,
+	_ *startergrpc.FindUsersRequest,
 ) (*startergrpc.FindUsersResponse, error) {
 	userID, err := auth.UUIDFromContextJWT(ctx, s.jwtManager)
 	if err != nil {
@@ -40,10 +39,7 @@ func (s *Server) FindUsers(
 	resUsers := make([]*startergrpc.User, 0, len(users))
 
 	for _, u := range users {
-		resUsers = append(resUsers, &startergrpc.User{
-			Id:    u.ID.String(),
-			Email: u.Email,
-		})
+		resUsers = append(resUsers, userToProto(u))
 	}
 
 	return &startergrpc.FindUsersResponse{
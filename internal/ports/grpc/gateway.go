@@ -0,0 +1,200 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ifMatchMetadataKey is the metadata key under which the gateway
+// forwards the If-Match header.
+const ifMatchMetadataKey = "grpcgateway-if-match"
+
+// gatewayMuxOptions returns the options of the gateway mux.
+//
+// Besides the default JSON marshaler, and the NDJSON one picked by
+// the Accept header, it sets the ETag header of the responses
+// carrying a user, writes the errors as problem details, answers
+// version mismatches with 412 Precondition Failed and forwards the
+// API key, idempotency and rate limit headers.
+func gatewayMuxOptions() []runtime.ServeMuxOption {
+	jsonMarshaler := &runtime.HTTPBodyMarshaler{
+		Marshaler: &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				UseEnumNumbers:  false,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		},
+	}
+
+	return []runtime.ServeMuxOption{
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, jsonMarshaler),
+		runtime.WithMarshalerOption(
+			ndjsonContentType,
+			ndjsonMarshaler{Marshaler: jsonMarshaler},
+		),
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithForwardResponseOption(setETag),
+		runtime.WithErrorHandler(handleGatewayErr),
+	}
+}
+
+// incomingHeaderMatcher forwards the X-Api-Key, Idempotency-Key and
+// X-Request-Id headers besides the ones forwarded by
+// runtime.DefaultHeaderMatcher.
+func incomingHeaderMatcher(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case apiKeyMetadataKey, idempotency.MetadataKey, requestid.MetadataKey:
+		return strings.ToLower(key), true
+
+	default:
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+// outgoingHeaderMatcher writes the rate limit and idempotency
+// metadata as plain headers and the other ones prefixed with Grpc-Metadata-,
+// like the default matcher. The request id is left out, the requestID
+// middleware already wrote it.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	switch key {
+	case requestid.MetadataKey:
+		return "", false
+
+	case rateLimitLimitMetadataKey,
+		rateLimitRemainingMetadataKey,
+		rateLimitResetMetadataKey,
+		retryAfterMetadataKey,
+		idempotency.ReplayedMetadataKey:
+		return key, true
+
+	default:
+		return runtime.MetadataHeaderPrefix + key, true
+	}
+}
+
+// ndjsonContentType is the content type of newline delimited JSON.
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonMarshaler marshals like the wrapped marshaler, the gateway
+// writes every message of a stream on its own line, but tells the
+// clients the response is newline delimited JSON.
+type ndjsonMarshaler struct {
+	runtime.Marshaler
+}
+
+// ContentType satisfies the runtime.Marshaler interface.
+func (ndjsonMarshaler) ContentType(any) string {
+	return ndjsonContentType
+}
+
+// Delimiter satisfies the runtime.Delimited interface.
+func (ndjsonMarshaler) Delimiter() []byte {
+	return []byte("\n")
+}
+
+// setETag sets the ETag header to the version of the returned user.
+func setETag(
+	_ context.Context,
+	w http.ResponseWriter,
+	msg proto.Message,
+) error {
+	res, ok := msg.(interface{ GetUser() *startergrpc.User })
+	if !ok || res.GetUser() == nil {
+		return nil
+	}
+
+	w.Header().Set(
+		"ETag",
+		strconv.Quote(strconv.FormatInt(res.GetUser().GetVersion(), 10)),
+	)
+
+	return nil
+}
+
+// versionFromIfMatch returns the version sent in the If-Match header,
+// zero when the header is missing or matches any version.
+func versionFromIfMatch(ctx context.Context) (int64, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(ifMatchMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	etag := strings.TrimSpace(values[0])
+
+	if etag == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(
+		strings.Trim(strings.TrimPrefix(etag, "W/"), `"`),
+		10,
+		64,
+	)
+	if err != nil || version <= 0 {
+		return 0, errors.NewInvalidError("invalid if-match header")
+	}
+
+	return version, nil
+}
+
+// handleGatewayErr writes the error as RFC 7807 problem details,
+// or like runtime.DefaultHTTPErrorHandler for the clients preferring
+// application/json in their Accept header.
+//
+// Failed preconditions are answered with 412 Precondition Failed
+// instead of 400 Bad Request.
+func handleGatewayErr(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	marshaler runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	httpStatus := runtime.HTTPStatusFromCode(status.Code(err))
+
+	var customStatus *runtime.HTTPStatusError
+
+	switch {
+	case errors.As(err, &customStatus):
+		httpStatus = customStatus.HTTPStatus
+
+	case status.Code(err) == codes.FailedPrecondition:
+		httpStatus = http.StatusPreconditionFailed
+
+		err = &runtime.HTTPStatusError{
+			HTTPStatus: httpStatus,
+			Err:        err,
+		}
+	}
+
+	if !acceptsLegacyErrors(r) {
+		marshaler = problemMarshaler{
+			Marshaler:  marshaler,
+			httpStatus: httpStatus,
+			instance:   r.Header.Get(requestid.Header),
+		}
+	}
+
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// inProcessEndpoint is the endpoint the gateway dials
+// when it is connected in-process to the grpc server.
+const inProcessEndpoint = "in-process"
+
+// inProcessNetwork is the network of the addresses of the
+// in-process connection.
+const inProcessNetwork = "bufconn"
+
+// inProcessBufferSize is the size of the buffers of the in-memory
+// connections between the gateway and the grpc server.
+const inProcessBufferSize = 1024 * 1024
+
+// grpcAddress returns the address the grpc server listens to when it
+// doesn't share the port of the gateway: SERVER.GRPCPort or, when
+// not set, the port before SERVER.Port.
+func grpcAddress(cfg *config.Config) string {
+	port := cfg.SERVER.GRPCPort
+	if port == 0 {
+		port = cfg.SERVER.Port - 1
+	}
+
+	return fmt.Sprintf("%s:%d", cfg.SERVER.Address, port)
+}
+
+// gatewayDialOptions returns the dial options connecting the gateway
+// to the grpc server, through the in-memory listener when set.
+func gatewayDialOptions(lis *bufconn.Listener) []grpc.DialOption {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+
+	if lis != nil {
+		opts = append(opts, grpc.WithContextDialer(
+			func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			},
+		))
+	}
+
+	return opts
+}
+
+// newGatewayServer returns the HTTP server of the gateway, connected
+// to the grpc server with the dial options. In the single port mode
+// it serves the native grpc requests too, with the grpc server.
+//
+// Cleartext HTTP/2 is accepted for the grpc clients
+// not using TLS.
+func (s *Server) newGatewayServer(
+	middlewares chi.Middlewares,
+	dialOptions []grpc.DialOption,
+	singlePort bool,
+) (*http.Server, error) {
+	mux := runtime.NewServeMux(gatewayMuxOptions()...)
+
+	err := s.registerGatewayServer(mux, dialOptions)
+	if err != nil {
+		return nil, fmt.Errorf("register gateway: %w", err)
+	}
+
+	r := chi.NewRouter()
+
+	r.Use(middlewares...)
+	r.Get("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Mount("/", mux)
+
+	if !singlePort {
+		return &http.Server{
+			Handler: r,
+		}, nil
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if isGRPCRequest(req) {
+			s.grpcServer.ServeHTTP(w, req)
+
+			return
+		}
+
+		r.ServeHTTP(w, req)
+	})
+
+	return &http.Server{
+		Handler: h2c.NewHandler(handler, &http2.Server{}),
+	}, nil
+}
+
+// isGRPCRequest reports whether the request is a native grpc one,
+// sent over HTTP/2 with the application/grpc content type.
+func isGRPCRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+
+	return r.ProtoMajor == 2 &&
+		(contentType == "application/grpc" ||
+			strings.HasPrefix(contentType, "application/grpc+") ||
+			strings.HasPrefix(contentType, "application/grpc;"))
+}
@@ -0,0 +1,597 @@
+package grpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/auth"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/ratelimit"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/clientgrpc"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	portsgrpc "github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/connectivity"
+)
+
+// gatewayModes are the ways the gateway is connected
+// to the grpc server.
+var gatewayModes = []struct {
+	name      string
+	configure func(cfg *config.Config)
+}{
+	{
+		name:      "TCP",
+		configure: func(*config.Config) {},
+	},
+	{
+		name: "InProcess",
+		configure: func(cfg *config.Config) {
+			cfg.SERVER.InProcessGateway = true
+		},
+	},
+	{
+		name: "SinglePort",
+		configure: func(cfg *config.Config) {
+			cfg.SERVER.SinglePort = true
+		},
+	},
+}
+
+// noWebhookSubscriptions is a read model without any
+// webhook subscription.
+type noWebhookSubscriptions struct{}
+
+func (noWebhookSubscriptions) FindWebhookSubscriptions(
+	context.Context,
+) ([]query.WebhookSubscription, error) {
+	return nil, nil
+}
+
+// newGatewayTestServer returns a server listening to random ports,
+// configured by configure.
+func newGatewayTestServer(
+	tb testing.TB,
+	configure func(cfg *config.Config),
+	opts ...portsgrpc.ServerOption,
+) *portsgrpc.Server {
+	tb.Helper()
+
+	cfg := new(config.Config)
+
+	cfg.SERVER.Address = "127.0.0.1"
+	cfg.SERVER.GRPCPort = freePort(tb)
+
+	configure(cfg)
+
+	var (
+		userRepo = memory.NewUserRepository()
+		uow      = memory.NewUnitOfWork(userRepo)
+	)
+
+	s, err := portsgrpc.NewGrpcServer(
+		zap.NewNop(),
+		cfg,
+		app.Application{
+			Commands: app.Commands{
+				CreateUser:      command.MustNewCreateUserHandler(userRepo),
+				ChangeUserEmail: command.MustNewChangeUserEmailHandler(uow),
+				DeleteUser:      command.MustNewDeleteUserHandler(uow),
+			},
+			Queries: app.Queries{
+				UserByID: query.MustNewUserByIDHandler(userRepo),
+				WebhookSubscriptions: query.MustNewWebhookSubscriptionsHandler(
+					noWebhookSubscriptions{},
+				),
+			},
+			Webhooks: true,
+		},
+		auth.NewJWTManager("secret", time.Hour),
+		opts...,
+	)
+	is.New(tb).NoErr(err)
+
+	tb.Cleanup(func() {
+		_ = s.Close()
+	})
+
+	return s
+}
+
+// freePort returns a port free to listen to.
+func freePort(tb testing.TB) int {
+	tb.Helper()
+
+	i := is.New(tb)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	i.NoErr(err)
+
+	i.NoErr(lis.Close())
+
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+func TestServer_Gateway(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range gatewayModes {
+		mode := mode
+
+		t.Run(mode.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			s := newGatewayTestServer(t, mode.configure)
+
+			res, err := http.Post(
+				"http://"+s.HTTPAddr()+"/v1/users",
+				"application/json",
+				strings.NewReader(`{"email": "gateway@test.com"}`),
+			)
+			i.NoErr(err)
+
+			defer res.Body.Close()
+
+			i.Equal(res.StatusCode, http.StatusOK)
+		})
+	}
+}
+
+func TestServer_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	cfg := new(config.Config)
+
+	cfg.SERVER.Address = "127.0.0.1"
+	cfg.SERVER.GRPCPort = freePort(t)
+	cfg.HTTP.CORS.AllowCredentials = true
+
+	s, err := portsgrpc.NewGrpcServer(
+		zap.NewNop(),
+		cfg,
+		app.Application{},
+		auth.NewJWTManager("secret", time.Hour),
+	)
+	i.True(err != nil)
+	i.True(s == nil)
+}
+
+func TestServer_CloseGatewayConn(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range gatewayModes {
+		mode := mode
+
+		t.Run(mode.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			s := newGatewayTestServer(t, mode.configure)
+
+			i.NoErr(s.Close())
+			i.Equal(connectivity.Shutdown, s.GatewayConnState())
+		})
+	}
+}
+
+func TestServer_GatewayWebhooksRequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	s := newGatewayTestServer(t, func(*config.Config) {})
+
+	tests := map[string]struct {
+		role       string
+		statusCode int
+	}{
+		"Anonymous": {
+			statusCode: http.StatusUnauthorized,
+		},
+		"User": {
+			role:       "user",
+			statusCode: http.StatusUnauthorized,
+		},
+		"Admin": {
+			role:       "admin",
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			req, err := http.NewRequest(
+				http.MethodGet,
+				"http://"+s.HTTPAddr()+"/v1/webhooks",
+				nil,
+			)
+			i.NoErr(err)
+
+			if test.role != "" {
+				req.Header.Set(
+					"Authorization",
+					"Bearer "+testToken(t, test.role),
+				)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			i.NoErr(err)
+
+			defer res.Body.Close()
+
+			i.Equal(res.StatusCode, test.statusCode)
+		})
+	}
+}
+
+func TestServer_GatewayRateLimitByClientIP(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range gatewayModes {
+		mode := mode
+
+		t.Run(mode.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			s := newGatewayTestServer(
+				t,
+				func(cfg *config.Config) {
+					mode.configure(cfg)
+
+					cfg.RATE_LIMIT.Rate = 0.001
+					cfg.RATE_LIMIT.Burst = 1
+				},
+				portsgrpc.WithRateLimitStore(ratelimit.NewMemory()),
+			)
+
+			token := testToken(t, "admin")
+
+			get := func(ip string) int {
+				return getWebhooksFrom(t, s.HTTPAddr(), ip, http.Header{
+					"Authorization": {"Bearer " + token},
+				})
+			}
+
+			i.Equal(get("127.0.0.2"), http.StatusOK)
+			i.Equal(get("127.0.0.2"), http.StatusTooManyRequests)
+			i.Equal(get("127.0.0.3"), http.StatusOK)
+		})
+	}
+}
+
+func TestServer_GatewayRateLimitByAPIKey(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	s := newGatewayTestServer(
+		t,
+		func(cfg *config.Config) {
+			cfg.SERVER.SinglePort = true
+
+			cfg.RATE_LIMIT.Rate = 0.001
+			cfg.RATE_LIMIT.Burst = 1
+			cfg.RATE_LIMIT.APIKeys = []string{"known"}
+		},
+		portsgrpc.WithRateLimitStore(ratelimit.NewMemory()),
+	)
+
+	token := testToken(t, "admin")
+
+	get := func(ip, apiKey string) int {
+		return getWebhooksFrom(t, s.HTTPAddr(), ip, http.Header{
+			"Authorization": {"Bearer " + token},
+			"X-Api-Key":     {apiKey},
+		})
+	}
+
+	// The unknown keys share the limit of their IP address.
+	i.Equal(get("127.0.0.2", "unknown-1"), http.StatusOK)
+	i.Equal(get("127.0.0.2", "unknown-2"), http.StatusTooManyRequests)
+
+	// A configured key has its own limit, whatever the IP address.
+	i.Equal(get("127.0.0.2", "known"), http.StatusOK)
+	i.Equal(get("127.0.0.3", "known"), http.StatusTooManyRequests)
+}
+
+// getWebhooksFrom lists the webhooks through the gateway listening
+// on addr as a client calling from the given IP, returning the
+// status code.
+func getWebhooksFrom(
+	tb testing.TB,
+	addr,
+	ip string,
+	header http.Header,
+) int {
+	tb.Helper()
+
+	i := is.New(tb)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				LocalAddr: &net.TCPAddr{IP: net.ParseIP(ip)},
+			}).DialContext,
+		},
+	}
+
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr+"/v1/webhooks", nil)
+	i.NoErr(err)
+
+	req.Header = header
+
+	res, err := client.Do(req)
+	i.NoErr(err)
+
+	defer res.Body.Close()
+
+	return res.StatusCode
+}
+
+// testToken returns a token with the given role signed with
+// the secret of the gateway test servers.
+func testToken(tb testing.TB, role string) string {
+	tb.Helper()
+
+	return testUserToken(tb, role, "")
+}
+
+// testUserToken returns a token of the user with the given id
+// and role signed with the secret of the gateway test servers.
+func testUserToken(tb testing.TB, role, id string) string {
+	tb.Helper()
+
+	i := is.New(tb)
+
+	token, err := jwt.NewWithClaims(
+		jwt.SigningMethodHS256,
+		auth.UserClaims{
+			StandardClaims: jwt.StandardClaims{
+				Subject:   id,
+				ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			},
+			Role: role,
+		},
+	).SignedString([]byte("secret"))
+	i.NoErr(err)
+
+	return token
+}
+
+func TestServer_GatewayETag(t *testing.T) {
+	t.Parallel()
+
+	// updateUser changes the email of the created user through the
+	// gateway with the given If-Match header.
+	updateUser := func(t *testing.T, s *portsgrpc.Server, ifMatch string) *http.Response {
+		i := is.New(t)
+
+		req, err := http.NewRequest(
+			http.MethodPatch,
+			"http://"+s.HTTPAddr()+"/v1/users/"+testID.String(),
+			strings.NewReader(`{"email": "changed@test.com"}`),
+		)
+		i.NoErr(err)
+
+		req.Header.Set(
+			"Authorization",
+			"Bearer "+testUserToken(t, "user", testID.String()),
+		)
+		req.Header.Set("If-Match", ifMatch)
+
+		res, err := http.DefaultClient.Do(req)
+		i.NoErr(err)
+
+		t.Cleanup(func() {
+			_ = res.Body.Close()
+		})
+
+		return res
+	}
+
+	tests := map[string]struct {
+		ifMatch    string
+		statusCode int
+		etag       string
+	}{
+		"CurrentVersion": {
+			ifMatch:    `"1"`,
+			statusCode: http.StatusOK,
+			etag:       `"2"`,
+		},
+		"WeakCurrentVersion": {
+			ifMatch:    `W/"1"`,
+			statusCode: http.StatusOK,
+			etag:       `"2"`,
+		},
+		"AnyVersion": {
+			ifMatch:    "*",
+			statusCode: http.StatusOK,
+			etag:       `"2"`,
+		},
+		"StaleVersion": {
+			ifMatch:    `"5"`,
+			statusCode: http.StatusPreconditionFailed,
+		},
+		"Malformed": {
+			ifMatch:    `"one"`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			s := newGatewayTestServer(t, func(*config.Config) {})
+
+			res, err := http.Post(
+				"http://"+s.HTTPAddr()+"/v1/users",
+				"application/json",
+				strings.NewReader(`{"email": "gateway@test.com"}`),
+			)
+			i.NoErr(err)
+
+			defer res.Body.Close()
+
+			i.Equal(res.StatusCode, http.StatusOK)
+			i.Equal(res.Header.Get("ETag"), `"1"`)
+
+			res = updateUser(t, s, test.ifMatch)
+
+			i.Equal(res.StatusCode, test.statusCode)
+			i.Equal(res.Header.Get("ETag"), test.etag)
+		})
+	}
+}
+
+func TestServer_GatewayDeleteUser(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	s := newGatewayTestServer(t, func(*config.Config) {})
+
+	res, err := http.Post(
+		"http://"+s.HTTPAddr()+"/v1/users",
+		"application/json",
+		strings.NewReader(`{"email": "gateway@test.com"}`),
+	)
+	i.NoErr(err)
+
+	defer res.Body.Close()
+
+	i.Equal(res.StatusCode, http.StatusOK)
+
+	deleteUser := func(token, ifMatch string) int {
+		req, err := http.NewRequest(
+			http.MethodDelete,
+			"http://"+s.HTTPAddr()+"/v1/users/"+testID.String(),
+			nil,
+		)
+		i.NoErr(err)
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("If-Match", ifMatch)
+
+		res, err := http.DefaultClient.Do(req)
+		i.NoErr(err)
+
+		defer res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	var (
+		otherToken = testUserToken(
+			t,
+			"user",
+			"8f2a3c1e-5b7d-4e9f-a1c3-2d4e6f8a0b1c",
+		)
+		token = testUserToken(t, "user", testID.String())
+	)
+
+	i.Equal(deleteUser(otherToken, `"1"`), http.StatusForbidden)
+	i.Equal(deleteUser(token, `"5"`), http.StatusPreconditionFailed)
+	i.Equal(deleteUser(token, `"1"`), http.StatusOK)
+	i.Equal(deleteUser(token, "*"), http.StatusNotFound)
+}
+
+func TestServer_SinglePort(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	s := newGatewayTestServer(t, func(cfg *config.Config) {
+		cfg.SERVER.SinglePort = true
+	})
+
+	conn, err := clientgrpc.NewClientConn(
+		s.HTTPAddr(),
+		clientgrpc.WithNoTLS(),
+	)
+	i.NoErr(err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	res, err := startergrpc.NewGoStarterClient(conn).CreateUser(
+		context.Background(),
+		&startergrpc.CreateUserRequest{Email: "grpc@test.com"},
+	)
+	i.NoErr(err)
+	i.Equal(res.GetUser().GetEmail(), "grpc@test.com")
+
+	docs, err := http.Get("http://" + s.HTTPAddr() + "/openapi.json")
+	i.NoErr(err)
+
+	defer docs.Body.Close()
+
+	i.Equal(docs.StatusCode, http.StatusOK)
+}
+
+// BenchmarkGateway measures the latency of a REST request
+// for each way the gateway is connected to the grpc server.
+func BenchmarkGateway(b *testing.B) {
+	for _, mode := range gatewayModes {
+		mode := mode
+
+		b.Run(mode.name, func(b *testing.B) {
+			i := is.New(b)
+
+			s := newGatewayTestServer(b, mode.configure)
+
+			req, err := http.NewRequest(
+				http.MethodGet,
+				"http://"+s.HTTPAddr()+"/v1/webhooks",
+				nil,
+			)
+			i.NoErr(err)
+
+			req.Header.Set("Authorization", "Bearer "+testToken(b, "admin"))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				res, err := http.DefaultClient.Do(req)
+				i.NoErr(err)
+
+				_, err = io.Copy(io.Discard, res.Body)
+				i.NoErr(err)
+
+				i.NoErr(res.Body.Close())
+				i.Equal(res.StatusCode, http.StatusOK)
+			}
+		})
+	}
+}
@@ -45,9 +45,6 @@ func (s *Server) GetUser(
 	}
 
 	return &startergrpc.GetUserResponse{
-		User: &startergrpc.User{
-			Id:    user.ID.String(),
-			Email: user.Email,
-		},
+		User: userToProto(user),
 	}, nil
 }
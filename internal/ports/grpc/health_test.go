@@ -0,0 +1,96 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/clientgrpc"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_HealthWatch(t *testing.T) {
+	t.Parallel()
+
+	watch := func(
+		ctx context.Context,
+		t *testing.T,
+		conn *grpc.ClientConn,
+	) grpc_health_v1.Health_WatchClient {
+		t.Helper()
+
+		i := is.New(t)
+
+		stream, err := grpc_health_v1.NewHealthClient(conn).Watch(
+			ctx,
+			&grpc_health_v1.HealthCheckRequest{},
+		)
+		i.NoErr(err)
+
+		res, err := stream.Recv()
+		i.NoErr(err)
+		i.Equal(res.GetStatus(), grpc_health_v1.HealthCheckResponse_SERVING)
+
+		return stream
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		_, conn := newSinglePortConn(t, func(*config.Config) {})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream := watch(ctx, t, conn)
+
+		cancel()
+
+		_, err := stream.Recv()
+		i.Equal(status.Code(err), codes.Canceled)
+	})
+
+	t.Run("ServerClosed", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		var grpcAddr string
+
+		s := newGatewayTestServer(t, func(cfg *config.Config) {
+			grpcAddr = fmt.Sprintf("127.0.0.1:%d", cfg.SERVER.GRPCPort)
+		})
+
+		conn, err := clientgrpc.NewClientConn(
+			grpcAddr,
+			clientgrpc.WithNoTLS(),
+		)
+		i.NoErr(err)
+
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+
+		stream := watch(context.Background(), t, conn)
+
+		i.NoErr(s.Close())
+
+		res, err := stream.Recv()
+		i.NoErr(err)
+		i.Equal(
+			res.GetStatus(),
+			grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		)
+
+		_, err = stream.Recv()
+		i.Equal(err, io.EOF)
+	})
+}
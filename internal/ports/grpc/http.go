@@ -0,0 +1,310 @@
+package grpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	"github.com/rs/cors"
+)
+
+// defaultFrameOptions is the X-Frame-Options header
+// sent when none is configured.
+const defaultFrameOptions = "DENY"
+
+var (
+	// defaultCORSMethods are the methods allowed to the
+	// cross-origin requests when none are configured.
+	defaultCORSMethods = []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPatch,
+		http.MethodPut,
+		http.MethodDelete,
+	}
+
+	// defaultCORSHeaders are the headers allowed to the
+	// cross-origin requests when none are configured.
+	defaultCORSHeaders = []string{
+		"Accept",
+		"Authorization",
+		"Content-Type",
+		"If-Match",
+		idempotency.MetadataKey,
+		apiKeyMetadataKey,
+		requestid.Header,
+		"Connect-Protocol-Version",
+		"Connect-Timeout-Ms",
+		"Grpc-Timeout",
+		"X-Grpc-Web",
+		"X-User-Agent",
+	}
+
+	// corsExposedHeaders are the headers written by the gateway
+	// that the browsers show to the cross-origin requests.
+	corsExposedHeaders = []string{
+		"ETag",
+		rateLimitLimitMetadataKey,
+		rateLimitRemainingMetadataKey,
+		rateLimitResetMetadataKey,
+		retryAfterMetadataKey,
+		idempotency.ReplayedMetadataKey,
+		requestid.Header,
+		"Grpc-Status",
+		"Grpc-Message",
+		"Grpc-Status-Details-Bin",
+	}
+
+	// compressedContentTypes are the content types
+	// of the responses that are compressed.
+	compressedContentTypes = []string{
+		"application/json",
+		problemContentType,
+		ndjsonContentType,
+	}
+)
+
+// httpMiddlewares returns the middlewares of the gateway, in order:
+// the request id, the real IP of the clients behind the trusted
+// proxies, CORS, security headers, request body size limit and
+// response compression.
+func httpMiddlewares(cfg *config.Config) (chi.Middlewares, error) {
+	realIP, err := newRealIP(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("new real ip: %w", err)
+	}
+
+	corsMW, err := corsHandler(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cors handler: %w", err)
+	}
+
+	mws := chi.Middlewares{
+		requestID,
+		realIP.handler,
+		corsMW,
+		securityHeaders(cfg),
+	}
+
+	if cfg.HTTP.MaxBodySize > 0 {
+		mws = append(mws, limitBodySize(cfg.HTTP.MaxBodySize))
+	}
+
+	if cfg.HTTP.CompressLevel > 0 {
+		mws = append(
+			mws,
+			middleware.Compress(
+				cfg.HTTP.CompressLevel,
+				compressedContentTypes...,
+			),
+		)
+	}
+
+	return mws, nil
+}
+
+// corsHandler returns the middleware answering the CORS preflight
+// requests and setting the CORS headers of the other ones.
+//
+// The cross-origin requests are allowed only from the configured
+// origins, none by default. Allowing the credentials requires an
+// explicit list of origins: sent to every origin, they would let
+// any website call the API on behalf of its visitors.
+func corsHandler(
+	cfg *config.Config,
+) (func(http.Handler) http.Handler, error) {
+	origins := cfg.HTTP.CORS.AllowedOrigins
+
+	if cfg.HTTP.CORS.AllowCredentials {
+		if len(origins) == 0 {
+			return nil, errors.NewInvalidError(
+				"cors credentials allowed without allowed origins",
+			)
+		}
+
+		for _, origin := range origins {
+			if origin == "*" {
+				return nil, errors.NewInvalidError(
+					"cors credentials allowed to every origin",
+				)
+			}
+		}
+	}
+
+	if len(origins) == 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}, nil
+	}
+
+	opts := cors.Options{
+		AllowedOrigins:   origins,
+		AllowedMethods:   cfg.HTTP.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.HTTP.CORS.AllowedHeaders,
+		ExposedHeaders:   corsExposedHeaders,
+		AllowCredentials: cfg.HTTP.CORS.AllowCredentials,
+		MaxAge:           int(cfg.HTTP.CORS.MaxAge.Seconds()),
+	}
+
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = defaultCORSMethods
+	}
+
+	if len(opts.AllowedHeaders) == 0 {
+		opts.AllowedHeaders = defaultCORSHeaders
+	}
+
+	return cors.New(opts).Handler, nil
+}
+
+// securityHeaders returns the middleware setting the
+// X-Content-Type-Options, X-Frame-Options and, when enabled,
+// Strict-Transport-Security headers.
+func securityHeaders(cfg *config.Config) func(http.Handler) http.Handler {
+	frameOptions := cfg.HTTP.FrameOptions
+	if frameOptions == "" {
+		frameOptions = defaultFrameOptions
+	}
+
+	var hsts string
+
+	if cfg.HTTP.HSTSMaxAge > 0 {
+		hsts = "max-age=" +
+			strconv.Itoa(int(cfg.HTTP.HSTSMaxAge.Seconds())) +
+			"; includeSubDomains"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", frameOptions)
+
+			if hsts != "" {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitBodySize returns the middleware answering the requests whose
+// body is larger than maxSize with 413 Request Entity Too Large.
+//
+// The uploads of users are limited by maxImportFileSize instead.
+func limitBodySize(maxSize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == importUsersPath {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if r.ContentLength > maxSize {
+				http.Error(
+					w,
+					http.StatusText(http.StatusRequestEntityTooLarge),
+					http.StatusRequestEntityTooLarge,
+				)
+
+				return
+			}
+
+			// The bodies of unknown length fail to be read past
+			// the limit.
+			r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// realIP replaces the remote address of the requests coming from
+// the trusted proxies with the address of the client they forward.
+type realIP struct {
+	trusted []*net.IPNet
+}
+
+func newRealIP(cidrs []string) (*realIP, error) {
+	trusted := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("parse trusted proxy: %w", err)
+		}
+
+		trusted = append(trusted, ipNet)
+	}
+
+	return &realIP{
+		trusted: trusted,
+	}, nil
+}
+
+func (ri *realIP) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, port, err := net.SplitHostPort(r.RemoteAddr)
+		if err == nil && ri.isTrusted(host) {
+			if ip := ri.clientIP(r); ip != "" {
+				r.RemoteAddr = net.JoinHostPort(ip, port)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the last address of the X-Forwarded-For header
+// not belonging to a trusted proxy, or the X-Real-IP header.
+func (ri *realIP) clientIP(r *http.Request) string {
+	var forwarded []string
+
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+
+		if net.ParseIP(ip) == nil {
+			return ""
+		}
+
+		if i == 0 || !ri.isTrusted(ip) {
+			return ip
+		}
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	return ""
+}
+
+func (ri *realIP) isTrusted(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range ri.trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,229 @@
+package grpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	portsgrpc "github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
+)
+
+func TestHTTPMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	cfg := new(config.Config)
+	cfg.HTTP.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	cfg.HTTP.CORS.AllowCredentials = true
+	cfg.HTTP.CORS.MaxAge = 10 * time.Minute
+	cfg.HTTP.HSTSMaxAge = 24 * time.Hour
+	cfg.HTTP.MaxBodySize = 16
+	cfg.HTTP.CompressLevel = 5
+	cfg.HTTP.TrustedProxies = []string{"10.0.0.0/8"}
+
+	mws, err := portsgrpc.HTTPMiddlewares(cfg)
+	is.New(t).NoErr(err)
+
+	// The handler echoes the remote address of the request.
+	handler := mws.Handler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			_, _ = w.Write([]byte(`{"remote_addr": "` + r.RemoteAddr + `"}`))
+		},
+	))
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, r)
+
+		return rec
+	}
+
+	t.Run("Preflight", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		req := httptest.NewRequest(http.MethodOptions, "/v1/users", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+		req.Header.Set("Access-Control-Request-Headers", "If-Match")
+
+		rec := serve(req)
+
+		i.Equal(
+			"https://app.example.com",
+			rec.Header().Get("Access-Control-Allow-Origin"),
+		)
+		i.Equal(http.MethodPatch, rec.Header().Get("Access-Control-Allow-Methods"))
+		i.Equal("If-Match", rec.Header().Get("Access-Control-Allow-Headers"))
+		i.Equal("true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		i.Equal("600", rec.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("OriginNotAllowed", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+
+		rec := serve(req)
+
+		i.Equal("", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("Headers", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+
+		rec := serve(req)
+
+		i.Equal(http.StatusOK, rec.Code)
+		i.Equal(
+			"https://app.example.com",
+			rec.Header().Get("Access-Control-Allow-Origin"),
+		)
+		i.True(strings.Contains(
+			rec.Header().Get("Access-Control-Expose-Headers"),
+			"Etag",
+		))
+		i.Equal("nosniff", rec.Header().Get("X-Content-Type-Options"))
+		i.Equal("DENY", rec.Header().Get("X-Frame-Options"))
+		i.Equal(
+			"max-age=86400; includeSubDomains",
+			rec.Header().Get("Strict-Transport-Security"),
+		)
+	})
+
+	t.Run("BodyTooLarge", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/v1/users",
+			strings.NewReader(`{"email": "user@example.com"}`),
+		)
+
+		rec := serve(req)
+
+		i.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("Compress", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rec := serve(req)
+
+		i.Equal("gzip", rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("RealIP", func(t *testing.T) {
+		t.Parallel()
+
+		tests := map[string]struct {
+			remoteAddr string
+			forwarded  string
+			expected   string
+		}{
+			"TrustedProxy": {
+				remoteAddr: "10.0.0.1:1234",
+				forwarded:  "198.51.100.1, 203.0.113.7, 10.0.0.2",
+				expected:   "203.0.113.7:1234",
+			},
+			"UntrustedProxy": {
+				remoteAddr: "192.0.2.1:1234",
+				forwarded:  "203.0.113.7",
+				expected:   "192.0.2.1:1234",
+			},
+		}
+
+		for name, test := range tests {
+			test := test
+
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				i := is.New(t)
+
+				req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+				req.RemoteAddr = test.remoteAddr
+				req.Header.Set("X-Forwarded-For", test.forwarded)
+
+				rec := serve(req)
+
+				i.Equal(
+					`{"remote_addr": "`+test.expected+`"}`,
+					rec.Body.String(),
+				)
+			})
+		}
+	})
+}
+
+func TestHTTPMiddlewares_CORS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NoAllowedOrigins", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		mws, err := portsgrpc.HTTPMiddlewares(new(config.Config))
+		i.NoErr(err)
+
+		handler := mws.Handler(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+		))
+
+		req := httptest.NewRequest(http.MethodOptions, "/v1/users", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		i.Equal("", rec.Header().Get("Access-Control-Allow-Origin"))
+		i.Equal("", rec.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	tests := map[string][]string{
+		"CredentialsWithoutOrigins": nil,
+		"CredentialsToEveryOrigin":  {"*"},
+	}
+
+	for name, origins := range tests {
+		origins := origins
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := new(config.Config)
+			cfg.HTTP.CORS.AllowedOrigins = origins
+			cfg.HTTP.CORS.AllowCredentials = true
+
+			_, err := portsgrpc.HTTPMiddlewares(cfg)
+			is.New(t).True(err != nil)
+		})
+	}
+}
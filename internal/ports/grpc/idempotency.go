@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"go.uber.org/zap"
+)
+
+// idempotentMethods are the mutating RPCs replaying their response
+// when retried with the same idempotency key.
+var idempotentMethods = []string{
+	"CreateUser",
+	"UpdateUser",
+	"DeleteUser",
+	"CreateWebhookSubscription",
+	"DeleteWebhookSubscription",
+	"RedeliverWebhook",
+}
+
+// newIdempotencyInterceptor returns the interceptor making the
+// mutating RPCs idempotent, the keys are scoped to the client
+// making the call.
+func newIdempotencyInterceptor(
+	logger *zap.Logger,
+	store idempotency.Store,
+	ttl time.Duration,
+	clients clientIdentifier,
+) *idempotency.Interceptor {
+	methods := make([]string, 0, len(idempotentMethods))
+
+	for _, m := range idempotentMethods {
+		methods = append(
+			methods,
+			"/"+startergrpc.GoStarter_ServiceDesc.ServiceName+"/"+m,
+		)
+	}
+
+	return idempotency.NewInterceptor(
+		logger,
+		store,
+		methods,
+		idempotency.WithTTL(ttl),
+		idempotency.WithScope(func(ctx context.Context) string {
+			return clients.key(ctx)
+		}),
+	)
+}
@@ -0,0 +1,333 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/usercsv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxImportUsers is the number of users a single ImportUsers call
+// can import, bounding the results held for its response.
+const maxImportUsers = 100_000
+
+// ImportUsers imports the streamed users.
+//
+// The users are imported every command.ImportBatchSize received
+// users, only the result of every row is held until the response
+// is sent, and at most maxImportUsers users are imported by a call.
+// The batches imported before an error are kept.
+func (s *Server) ImportUsers(
+	stream startergrpc.GoStarter_ImportUsersServer,
+) error {
+	var (
+		ctx      = stream.Context()
+		mode     user.ImportMode
+		rows     = make([]command.ImportUserRow, 0, command.ImportBatchSize)
+		res      = &startergrpc.ImportUsersResponse{}
+		received int
+	)
+
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("receive users: %w", err)
+		}
+
+		if first {
+			mode, err = user.ParseImportMode(req.GetMode())
+			if err != nil {
+				return err
+			}
+		}
+
+		received += len(req.GetUsers())
+		if received > maxImportUsers {
+			return status.Errorf(
+				codes.InvalidArgument,
+				"at most %d users can be imported at once",
+				maxImportUsers,
+			)
+		}
+
+		for _, u := range req.GetUsers() {
+			rows = append(rows, command.ImportUserRow{
+				ID:    u.GetId(),
+				Email: u.GetEmail(),
+			})
+
+			if len(rows) < command.ImportBatchSize {
+				continue
+			}
+
+			err = s.importUsers(ctx, mode, rows, res)
+			if err != nil {
+				return err
+			}
+
+			rows = rows[:0]
+		}
+	}
+
+	err := s.importUsers(ctx, mode, rows, res)
+	if err != nil {
+		return err
+	}
+
+	err = stream.SendAndClose(res)
+	if err != nil {
+		return fmt.Errorf("send import report: %w", err)
+	}
+
+	return nil
+}
+
+// importUsers imports a batch of users and adds their
+// results to the response.
+func (s *Server) importUsers(
+	ctx context.Context,
+	mode user.ImportMode,
+	rows []command.ImportUserRow,
+	res *startergrpc.ImportUsersResponse,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	results, err := s.app.Commands.ImportUsers.Handle(
+		ctx,
+		command.ImportUsers{
+			Mode: mode,
+			Rows: rows,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("import users command: %w", err)
+	}
+
+	for _, r := range results {
+		row := usercsv.NewReportRow(len(res.Results)+1, r)
+
+		res.Results = append(res.Results, &startergrpc.ImportUserResult{
+			Row:    int32(row.Row),
+			Id:     row.ID,
+			Email:  row.Email,
+			Status: row.Status,
+			Error:  row.Error,
+		})
+
+		switch r.Status {
+		case user.ImportStatusCreated:
+			res.Created++
+
+		case user.ImportStatusUpdated:
+			res.Updated++
+
+		case user.ImportStatusSkipped:
+			res.Skipped++
+
+		case user.ImportStatusFailed:
+			res.Failed++
+		}
+	}
+
+	return nil
+}
+
+const (
+	// importUsersPath is the gateway path the CSV files
+	// of users are uploaded to.
+	importUsersPath = "/v1/users:import"
+
+	// importUsersFileField is the multipart/form-data field
+	// holding the uploaded CSV file.
+	importUsersFileField = "file"
+
+	// maxImportFileSize is the size of the largest CSV file
+	// accepted by the gateway.
+	maxImportFileSize = 64 << 20
+)
+
+// importUsersCSV returns the gateway handler importing the users of
+// an uploaded CSV file through the ImportUsers RPC, so the request
+// goes through the same interceptors as the gRPC ones.
+//
+// The file is sent either as the request body or as the "file" field
+// of a multipart/form-data body and the mode as the "mode" query
+// parameter.
+func importUsersCSV(
+	mux *runtime.ServeMux,
+	client startergrpc.GoStarterClient,
+) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		_, outboundMarshaler := runtime.MarshalerForRequest(mux, r)
+
+		res, md, err := importCSV(ctx, mux, client, r)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+
+			return
+		}
+
+		runtime.ForwardResponseMessage(
+			runtime.NewServerMetadataContext(ctx, md),
+			mux,
+			outboundMarshaler,
+			w,
+			r,
+			res,
+		)
+	}
+}
+
+func importCSV(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	client startergrpc.GoStarterClient,
+	r *http.Request,
+) (*startergrpc.ImportUsersResponse, runtime.ServerMetadata, error) {
+	var md runtime.ServerMetadata
+
+	ctx, err := runtime.AnnotateContext(
+		ctx,
+		mux,
+		r,
+		"/"+startergrpc.GoStarter_ServiceDesc.ServiceName+"/ImportUsers",
+		runtime.WithHTTPPathPattern(importUsersPath),
+	)
+	if err != nil {
+		return nil, md, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	file, err := csvFile(r)
+	if err != nil {
+		return nil, md, err
+	}
+
+	reader, err := usercsv.NewReader(file)
+	if err != nil {
+		return nil, md, appErrToStatus(err)
+	}
+
+	stream, err := client.ImportUsers(ctx)
+	if err != nil {
+		return nil, md, err
+	}
+
+	var (
+		req = &startergrpc.ImportUsersRequest{
+			Mode: r.URL.Query().Get("mode"),
+		}
+		sent bool
+	)
+
+	for {
+		rows, err := reader.ReadBatch(command.ImportBatchSize)
+		if err == io.EOF {
+			break
+		}
+
+		// Returning cancels the stream, which stops the import.
+		if err != nil {
+			return nil, md, appErrToStatus(err)
+		}
+
+		for _, row := range rows {
+			req.Users = append(req.Users, &startergrpc.ImportUserRow{
+				Id:    row.ID,
+				Email: row.Email,
+			})
+		}
+
+		sent = true
+
+		// A failed send is reported by CloseAndRecv.
+		if stream.Send(req) != nil {
+			break
+		}
+
+		req = &startergrpc.ImportUsersRequest{}
+	}
+
+	// The mode is validated even when the file holds no users.
+	if !sent {
+		_ = stream.Send(req)
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, md, err
+	}
+
+	md.HeaderMD, err = stream.Header()
+	if err != nil {
+		return nil, md, err
+	}
+
+	md.TrailerMD = stream.Trailer()
+
+	return res, md, nil
+}
+
+// csvFile returns the CSV file uploaded with the request.
+func csvFile(r *http.Request) (io.Reader, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxImportFileSize)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, status.Errorf(
+				codes.InvalidArgument,
+				"missing %q field",
+				importUsersFileField,
+			)
+		}
+
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if part.FormName() == importUsersFileField {
+			return part, nil
+		}
+	}
+}
+
+// appErrToStatus returns the status of the application error
+// wrapped by err.
+func appErrToStatus(err error) error {
+	var appErr *errors.Error
+
+	if !errors.As(err, &appErr) {
+		return err
+	}
+
+	return errorToGRPCStatus(appErr).Err()
+}
@@ -0,0 +1,137 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	portsgrpc "github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_ImportUsers(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+	ctx := context.Background()
+
+	repo := memory.NewUserRepository()
+
+	id, err := uuid.Parse("00000000-0000-0000-0000-000000000001")
+	i.NoErr(err)
+
+	existing := user.MustNew(id, "existing@test.com")
+
+	i.NoErr(repo.CreateUser(ctx, existing))
+
+	_, conn := newTestServer(t, app.Application{
+		Commands: app.Commands{
+			ImportUsers: command.MustNewImportUsersHandler(repo),
+		},
+	})
+
+	client := startergrpc.NewGoStarterClient(conn)
+
+	t.Run("Upsert", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		stream, err := client.ImportUsers(ctx)
+		i.NoErr(err)
+
+		i.NoErr(stream.Send(&startergrpc.ImportUsersRequest{
+			Mode: "upsert",
+			Users: []*startergrpc.ImportUserRow{
+				{
+					Id:    "00000000-0000-0000-0000-000000000002",
+					Email: "new@test.com",
+				},
+				{
+					Id:    existing.ID().String(),
+					Email: "changed@test.com",
+				},
+			},
+		}))
+
+		i.NoErr(stream.Send(&startergrpc.ImportUsersRequest{
+			Users: []*startergrpc.ImportUserRow{
+				{Id: "invalid", Email: "invalid@test.com"},
+				{Id: "00000000-0000-0000-0000-000000000003"},
+			},
+		}))
+
+		res, err := stream.CloseAndRecv()
+		i.NoErr(err)
+
+		i.Equal(int32(1), res.GetCreated())
+		i.Equal(int32(1), res.GetUpdated())
+		i.Equal(int32(0), res.GetSkipped())
+		i.Equal(int32(2), res.GetFailed())
+
+		i.Equal(4, len(res.GetResults()))
+
+		for idx, r := range res.GetResults() {
+			i.Equal(int32(idx+1), r.GetRow())
+		}
+
+		i.Equal("created", res.GetResults()[0].GetStatus())
+		i.Equal("updated", res.GetResults()[1].GetStatus())
+		i.Equal("failed", res.GetResults()[2].GetStatus())
+		i.Equal("invalid: user id", res.GetResults()[2].GetError())
+		i.Equal("", res.GetResults()[2].GetId())
+		i.Equal("failed", res.GetResults()[3].GetStatus())
+
+		found, err := repo.GetUserByID(ctx, existing.ID())
+		i.NoErr(err)
+		i.Equal("changed@test.com", found.Email)
+	})
+
+	t.Run("InvalidMode", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		stream, err := client.ImportUsers(ctx)
+		i.NoErr(err)
+
+		i.NoErr(stream.Send(&startergrpc.ImportUsersRequest{
+			Mode: "replace",
+		}))
+
+		_, err = stream.CloseAndRecv()
+		i.Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("TooManyUsers", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		stream, err := client.ImportUsers(ctx)
+		i.NoErr(err)
+
+		users := make(
+			[]*startergrpc.ImportUserRow,
+			portsgrpc.MaxImportUsers+1,
+		)
+
+		for idx := range users {
+			users[idx] = &startergrpc.ImportUserRow{Email: "user@test.com"}
+		}
+
+		// The server may end the stream before
+		// receiving the whole message.
+		_ = stream.Send(&startergrpc.ImportUsersRequest{Users: users})
+
+		_, err = stream.CloseAndRecv()
+		i.Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/ratelimit"
+)
+
+type serverOptions struct {
+	rateLimitStore   ratelimit.Store
+	idempotencyStore idempotency.Store
+	idempotencyTTL   time.Duration
+}
+
+// ServerOption configures the Server.
+type ServerOption interface {
+	apply(*serverOptions)
+}
+
+type funcServerOption struct {
+	f func(*serverOptions)
+}
+
+func (f *funcServerOption) apply(o *serverOptions) {
+	f.f(o)
+}
+
+func newFuncServerOption(f func(*serverOptions)) *funcServerOption {
+	return &funcServerOption{
+		f: f,
+	}
+}
+
+// WithRateLimitStore rate limits the calls with the buckets kept
+// in the given store. By default the calls are not limited.
+func WithRateLimitStore(store ratelimit.Store) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.rateLimitStore = store
+	})
+}
+
+// WithIdempotencyStore makes the mutating RPCs idempotent for the
+// calls carrying an idempotency key, the responses are kept in the
+// given store for ttl. By default the keys are ignored.
+func WithIdempotencyStore(
+	store idempotency.Store,
+	ttl time.Duration,
+) ServerOption {
+	return newFuncServerOption(func(o *serverOptions) {
+		o.idempotencyStore = store
+		o.idempotencyTTL = ttl
+	})
+}
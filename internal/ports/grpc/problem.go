@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// problemContentType is the content type of the RFC 7807
+	// problem details.
+	problemContentType = "application/problem+json"
+
+	// legacyErrorContentType is the content type the clients ask for
+	// to receive the errors as grpc-gateway statuses.
+	legacyErrorContentType = "application/json"
+
+	// problemTypePrefix prefixes the type URIs of the problems
+	// caused by an ErrorResponse error code.
+	problemTypePrefix = "/problems/"
+
+	// blankProblemType is the type of the problems described by
+	// their HTTP status alone.
+	blankProblemType = "about:blank"
+)
+
+// problem represents the RFC 7807 problem details of an error.
+type problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []invalidParam `json:"invalid-params,omitempty"`
+}
+
+// invalidParam represents a field violation of an invalid request.
+type invalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// newProblem returns the problem details of the status answered
+// with the given HTTP status code.
+func newProblem(st *status.Status, httpStatus int, instance string) problem {
+	p := problem{
+		Type:     blankProblemType,
+		Title:    http.StatusText(httpStatus),
+		Status:   httpStatus,
+		Detail:   st.Message(),
+		Instance: instance,
+	}
+
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *startergrpc.ErrorResponse:
+			if d.GetErrorCode() != startergrpc.ErrorResponse_ERROR_CODE_UNSPECIFIED {
+				p.Type = problemType(d.GetErrorCode())
+			}
+
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				p.InvalidParams = append(p.InvalidParams, invalidParam{
+					Name:   v.GetField(),
+					Reason: v.GetDescription(),
+				})
+			}
+		}
+	}
+
+	return p
+}
+
+// problemType returns the type URI of the problems with
+// the given error code, like /problems/not-enough-balance.
+func problemType(code startergrpc.ErrorResponse_ErrorCode) string {
+	name := strings.TrimPrefix(code.String(), "ERROR_CODE_")
+
+	return problemTypePrefix +
+		strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// problemMarshaler marshals the statuses written by
+// runtime.DefaultHTTPErrorHandler as problem details.
+type problemMarshaler struct {
+	runtime.Marshaler
+
+	httpStatus int
+	instance   string
+}
+
+// ContentType satisfies the runtime.Marshaler interface.
+func (problemMarshaler) ContentType(any) string {
+	return problemContentType
+}
+
+// Marshal satisfies the runtime.Marshaler interface.
+func (m problemMarshaler) Marshal(v any) ([]byte, error) {
+	pb, ok := v.(*spb.Status)
+	if !ok {
+		return nil, fmt.Errorf("unexpected error message %T", v)
+	}
+
+	return json.Marshal(
+		newProblem(status.FromProto(pb), m.httpStatus, m.instance),
+	)
+}
+
+// acceptsLegacyErrors tells whether the request prefers the
+// errors as grpc-gateway statuses to the problem details, the
+// Accept header naming application/json with a higher quality
+// than application/problem+json.
+func acceptsLegacyErrors(r *http.Request) bool {
+	var legacyQuality, problemQuality float64
+
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+
+			quality := 1.0
+
+			if q, ok := params["q"]; ok {
+				quality, err = strconv.ParseFloat(q, 64)
+				if err != nil {
+					continue
+				}
+			}
+
+			switch mediaType {
+			case legacyErrorContentType:
+				legacyQuality = quality
+
+			case problemContentType:
+				problemQuality = quality
+			}
+		}
+	}
+
+	return legacyQuality > problemQuality
+}
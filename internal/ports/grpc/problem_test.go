@@ -0,0 +1,153 @@
+package grpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	portsgrpc "github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// getUserErr answers the GetUser requests with an error.
+type getUserErr struct {
+	startergrpc.UnimplementedGoStarterServer
+
+	err error
+}
+
+func (s getUserErr) GetUser(
+	context.Context,
+	*startergrpc.GetUserRequest,
+) (*startergrpc.GetUserResponse, error) {
+	return nil, s.err
+}
+
+// withDetails returns the status with the details attached.
+func withDetails(
+	t *testing.T,
+	st *status.Status,
+	details ...protoiface.MessageV1,
+) error {
+	t.Helper()
+
+	withDetails, err := st.WithDetails(details...)
+	is.New(t).NoErr(err)
+
+	return withDetails.Err()
+}
+
+func TestGateway_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err         error
+		accept      string
+		contentType string
+		status      int
+		body        map[string]any
+	}{
+		"Problem": {
+			err: withDetails(
+				t,
+				status.New(codes.InvalidArgument, "invalid request"),
+				&errdetails.BadRequest{
+					FieldViolations: []*errdetails.BadRequest_FieldViolation{
+						{Field: "id", Description: "must be a UUID"},
+					},
+				},
+			),
+			contentType: "application/problem+json",
+			status:      http.StatusBadRequest,
+			body: map[string]any{
+				"type":     "about:blank",
+				"title":    "Bad Request",
+				"status":   float64(http.StatusBadRequest),
+				"detail":   "invalid request",
+				"instance": "request-1",
+				"invalid-params": []any{
+					map[string]any{"name": "id", "reason": "must be a UUID"},
+				},
+			},
+		},
+		"ProblemWithErrorCode": {
+			err: withDetails(
+				t,
+				status.New(codes.FailedPrecondition, "not enough balance"),
+				&startergrpc.ErrorResponse{
+					ErrorCode: startergrpc.ErrorResponse_ERROR_CODE_NOT_ENOUGH_BALANCE,
+				},
+			),
+			accept:      "application/problem+json, application/json;q=0.5",
+			contentType: "application/problem+json",
+			status:      http.StatusPreconditionFailed,
+			body: map[string]any{
+				"type":     "/problems/not-enough-balance",
+				"title":    "Precondition Failed",
+				"status":   float64(http.StatusPreconditionFailed),
+				"detail":   "not enough balance",
+				"instance": "request-1",
+			},
+		},
+		"Legacy": {
+			err:         status.Error(codes.NotFound, "user not found"),
+			accept:      "application/json",
+			contentType: "application/json",
+			status:      http.StatusNotFound,
+			body: map[string]any{
+				"code":    float64(codes.NotFound),
+				"message": "user not found",
+				"details": []any{},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			mux := runtime.NewServeMux(portsgrpc.GatewayMuxOptions()...)
+
+			i.NoErr(startergrpc.RegisterGoStarterHandlerServer(
+				context.TODO(),
+				mux,
+				getUserErr{err: test.err},
+			))
+
+			req := httptest.NewRequest(
+				http.MethodGet,
+				"/v1/user?id="+testUserID,
+				nil,
+			)
+			req.Header.Set("X-Request-Id", "request-1")
+
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			i.Equal(test.status, rec.Code)
+			i.Equal(test.contentType, rec.Header().Get("Content-Type"))
+
+			var body map[string]any
+
+			i.NoErr(json.Unmarshal(rec.Body.Bytes(), &body))
+			i.Equal(test.body, body)
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/purposeinplay/go-commons/auth"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/ratelimit"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Metadata keys used by the rate limiter, the gateway forwards
+// the response ones as plain HTTP headers.
+const (
+	apiKeyMetadataKey             = "x-api-key"
+	forwardedForMetadataKey       = "x-forwarded-for"
+	rateLimitLimitMetadataKey     = "x-ratelimit-limit"
+	rateLimitRemainingMetadataKey = "x-ratelimit-remaining"
+	rateLimitResetMetadataKey     = "x-ratelimit-reset"
+	retryAfterMetadataKey         = "retry-after"
+)
+
+// rateLimiter limits the calls each client makes to every RPC
+// with a token bucket.
+//
+// The clients are identified by a clientIdentifier.
+type rateLimiter struct {
+	logger       *zap.Logger
+	store        ratelimit.Store
+	clients      clientIdentifier
+	defaultLimit ratelimit.Limit
+	// limits holds the limits of the RPCs by their lower cased name.
+	limits map[string]ratelimit.Limit
+}
+
+func newRateLimiter(
+	logger *zap.Logger,
+	cfg *config.Config,
+	store ratelimit.Store,
+	clients clientIdentifier,
+) *rateLimiter {
+	limits := make(
+		map[string]ratelimit.Limit,
+		len(cfg.RATE_LIMIT.METHODS),
+	)
+
+	// The config keys are case insensitive.
+	for method, limit := range cfg.RATE_LIMIT.METHODS {
+		limits[strings.ToLower(method)] = ratelimit.Limit{
+			Rate:  limit.Rate,
+			Burst: limit.Burst,
+		}
+	}
+
+	return &rateLimiter{
+		logger:  logger.Named("grpc.rate_limiter"),
+		store:   store,
+		clients: clients,
+		defaultLimit: ratelimit.Limit{
+			Rate:  cfg.RATE_LIMIT.Rate,
+			Burst: cfg.RATE_LIMIT.Burst,
+		},
+		limits: limits,
+	}
+}
+
+// unary takes a token for the client and the RPC before calling the
+// handler and rejects the call with ResourceExhausted when there is
+// none left.
+//
+// The calls are let through when the store fails, so an outage of the
+// store doesn't take the API down with it.
+func (l *rateLimiter) unary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	limit := l.limit(info.FullMethod)
+	if !limit.Enabled() {
+		return handler(ctx, req)
+	}
+
+	res, err := l.store.Take(
+		ctx,
+		info.FullMethod+":"+l.clients.key(ctx),
+		limit,
+	)
+	if err != nil {
+		l.logger.Warn(
+			"take rate limit token",
+			zap.Error(err),
+			requestid.Field(ctx),
+		)
+
+		return handler(ctx, req)
+	}
+
+	// It only fails when ctx doesn't belong to a server stream.
+	_ = grpc.SetHeader(ctx, rateLimitMetadata(res))
+
+	if !res.Allowed {
+		return nil, rateLimitedErr(res.RetryAfter)
+	}
+
+	return handler(ctx, req)
+}
+
+func (l *rateLimiter) limit(fullMethod string) ratelimit.Limit {
+	limit, ok := l.limits[strings.ToLower(path.Base(fullMethod))]
+	if ok {
+		return limit
+	}
+
+	return l.defaultLimit
+}
+
+// clientIdentifier identifies the client making a call by the
+// subject of its JWT, by its API key or by its IP address.
+//
+// Only the configured API keys are trusted, the calls with an unknown
+// one are identified by IP address so clients can't get a fresh rate
+// limit or idempotency scope by making up keys.
+type clientIdentifier struct {
+	jwtManager *auth.JWTManager
+	// apiKeys holds the hex encoded SHA-256 of the configured keys.
+	apiKeys map[string]struct{}
+}
+
+func newClientIdentifier(
+	cfg *config.Config,
+	jwtManager *auth.JWTManager,
+) clientIdentifier {
+	apiKeys := make(map[string]struct{}, len(cfg.RATE_LIMIT.APIKeys))
+
+	for _, k := range cfg.RATE_LIMIT.APIKeys {
+		if k != "" {
+			apiKeys[hashAPIKey(k)] = struct{}{}
+		}
+	}
+
+	return clientIdentifier{
+		jwtManager: jwtManager,
+		apiKeys:    apiKeys,
+	}
+}
+
+// key returns the key of the client making the call.
+func (c clientIdentifier) key(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	token, err := auth.ExtractTokenFromMetadata(md)
+	if err == nil && c.jwtManager != nil {
+		claims, err := c.jwtManager.Verify(token)
+		if err == nil && claims.Subject != "" {
+			return "sub:" + claims.Subject
+		}
+	}
+
+	if keys := md.Get(apiKeyMetadataKey); len(keys) > 0 && keys[0] != "" {
+		hash := hashAPIKey(keys[0])
+
+		if _, ok := c.apiKeys[hash]; ok {
+			return "key:" + hash
+		}
+	}
+
+	return "ip:" + peerIP(ctx, md)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// peerIP returns the IP address of the client.
+//
+// The calls coming from the gateway, over the loopback interface or
+// the in-process connection, are attributed to the address the
+// gateway appended last to the X-Forwarded-For header, the other
+// ones can't be trusted.
+func peerIP(ctx context.Context, md metadata.MD) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if !isGatewayPeer(p.Addr, host) {
+		return host
+	}
+
+	forwarded := md.Get(forwardedForMetadataKey)
+	if len(forwarded) == 0 {
+		return host
+	}
+
+	hops := strings.Split(forwarded[len(forwarded)-1], ",")
+
+	if last := strings.TrimSpace(hops[len(hops)-1]); last != "" {
+		return last
+	}
+
+	return host
+}
+
+// isGatewayPeer reports whether the call comes from the gateway,
+// the only client dialing the in-process listener or, when it
+// connects over TCP, the loopback interface.
+func isGatewayPeer(addr net.Addr, host string) bool {
+	if addr.Network() == inProcessNetwork {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func rateLimitMetadata(res ratelimit.Result) metadata.MD {
+	md := metadata.Pairs(
+		rateLimitLimitMetadataKey, strconv.Itoa(res.Limit),
+		rateLimitRemainingMetadataKey, strconv.Itoa(res.Remaining),
+		rateLimitResetMetadataKey, strconv.Itoa(ceilSeconds(res.Reset)),
+	)
+
+	if !res.Allowed {
+		md.Set(retryAfterMetadataKey, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+	}
+
+	return md
+}
+
+// rateLimitedErr returns a ResourceExhausted status telling the
+// client when to retry.
+func rateLimitedErr(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+
+	withDetails, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
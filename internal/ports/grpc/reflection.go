@@ -0,0 +1,34 @@
+package grpc
+
+import (
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reflectionMethodPrefix prefixes the methods of the grpc
+// reflection services, go-commons registers them on every server.
+const reflectionMethodPrefix = "/grpc.reflection."
+
+// noReflectionInterceptor rejects the calls to the reflection
+// services like the server does for the unknown services, so
+// the reflection can be disabled.
+func noReflectionInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if strings.HasPrefix(info.FullMethod, reflectionMethodPrefix) {
+		return status.Errorf(
+			codes.Unimplemented,
+			"unknown service %v",
+			strings.TrimPrefix(path.Dir(info.FullMethod), "/"),
+		)
+	}
+
+	return handler(srv, stream)
+}
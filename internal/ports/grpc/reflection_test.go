@@ -0,0 +1,109 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/clientgrpc"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	portsgrpc "github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// newSinglePortConn returns a single port server configured by
+// configure and a connection to it.
+func newSinglePortConn(
+	t *testing.T,
+	configure func(cfg *config.Config),
+) (*portsgrpc.Server, *grpc.ClientConn) {
+	t.Helper()
+
+	s := newGatewayTestServer(t, func(cfg *config.Config) {
+		cfg.SERVER.SinglePort = true
+
+		configure(cfg)
+	})
+
+	conn, err := clientgrpc.NewClientConn(
+		s.HTTPAddr(),
+		clientgrpc.WithNoTLS(),
+	)
+	is.New(t).NoErr(err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return s, conn
+}
+
+func TestServer_Reflection(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		reflection bool
+		code       codes.Code
+	}{
+		"Enabled": {
+			reflection: true,
+			code:       codes.OK,
+		},
+		"Disabled": {
+			reflection: false,
+			code:       codes.Unimplemented,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			_, conn := newSinglePortConn(t, func(cfg *config.Config) {
+				cfg.SERVER.Reflection = test.reflection
+			})
+
+			stream, err := reflectionpb.NewServerReflectionClient(conn).
+				ServerReflectionInfo(context.Background())
+			i.NoErr(err)
+
+			err = stream.Send(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.
+					ServerReflectionRequest_ListServices{},
+			})
+			i.NoErr(err)
+
+			res, err := stream.Recv()
+			i.Equal(status.Code(err), test.code)
+
+			if test.code != codes.OK {
+				return
+			}
+
+			var services []string
+
+			for _, s := range res.GetListServicesResponse().GetService() {
+				services = append(services, s.GetName())
+			}
+
+			i.True(contains(services, "startergrpc.v1.GoStarter"))
+		})
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
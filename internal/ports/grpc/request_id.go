@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDInterceptor stores in the context the request id sent by
+// the client, or a new one, and sends it back in the header
+// metadata. The id is added to the log tags, so the grpc_zap
+// interceptors log it.
+func requestIDInterceptor(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	ctx = withRequestID(ctx)
+
+	// It only fails when ctx doesn't belong to a server stream.
+	_ = grpc.SetHeader(ctx, requestIDMetadata(ctx))
+
+	return handler(ctx, req)
+}
+
+// streamRequestIDInterceptor is the requestIDInterceptor
+// of the streams.
+func streamRequestIDInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	wrapped := grpc_middleware.WrapServerStream(stream)
+	wrapped.WrappedContext = withRequestID(stream.Context())
+
+	_ = stream.SetHeader(requestIDMetadata(wrapped.WrappedContext))
+
+	return handler(srv, wrapped)
+}
+
+// withRequestID returns a copy of ctx carrying the request id
+// and holding it in its log tags.
+func withRequestID(ctx context.Context) context.Context {
+	id, ok := requestid.FromIncomingContext(ctx)
+	if !ok {
+		id = requestid.New()
+	}
+
+	ctx = requestid.NewContext(ctx, id)
+
+	// The tags set by grpc_ctxtags are kept, they are
+	// filled with the request fields later on.
+	tags := grpc_ctxtags.Extract(ctx)
+	if tags == grpc_ctxtags.NoopTags {
+		tags = grpc_ctxtags.NewTags()
+		ctx = grpc_ctxtags.SetInContext(ctx, tags)
+	}
+
+	tags.Set(requestid.LogKey, id)
+
+	return ctx
+}
+
+func requestIDMetadata(ctx context.Context) metadata.MD {
+	id, _ := requestid.FromContext(ctx)
+
+	return metadata.Pairs(requestid.MetadataKey, id)
+}
+
+// requestID is the gateway middleware taking the request id from
+// the X-Request-Id header, or generating one, and writing it in
+// the X-Request-Id header of both the request, forwarded as grpc
+// metadata, and the response.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		r.Header.Set(requestid.Header, id)
+		w.Header().Set(requestid.Header, id)
+
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
@@ -0,0 +1,154 @@
+package grpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/requestid"
+	portsgrpc "github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestServer_RequestID(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		ctx       context.Context
+		requestID string
+	}{
+		"Sent": {
+			ctx: metadata.AppendToOutgoingContext(
+				context.Background(),
+				requestid.MetadataKey,
+				"request-1",
+			),
+			requestID: "request-1",
+		},
+		"PassedAlong": {
+			ctx: requestid.NewContext(
+				context.Background(),
+				"request-2",
+			),
+			requestID: "request-2",
+		},
+		"Invalid": {
+			ctx: metadata.AppendToOutgoingContext(
+				context.Background(),
+				requestid.MetadataKey,
+				"request 3",
+			),
+		},
+		"Generated": {
+			ctx: context.Background(),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			_, conn := newTestServer(t, app.Application{
+				Commands: app.Commands{
+					CreateUser: command.MustNewCreateUserHandler(
+						memory.NewUserRepository(),
+					),
+				},
+			})
+
+			var header metadata.MD
+
+			_, err := startergrpc.NewGoStarterClient(conn).CreateUser(
+				test.ctx,
+				&startergrpc.CreateUserRequest{Email: "user@test.com"},
+				grpc.Header(&header),
+			)
+			i.NoErr(err)
+
+			ids := header.Get(requestid.MetadataKey)
+
+			i.Equal(len(ids), 1)
+
+			if test.requestID != "" {
+				i.Equal(ids[0], test.requestID)
+			} else {
+				i.True(ids[0] != "request 3")
+				i.True(requestid.Valid(ids[0]))
+			}
+		})
+	}
+}
+
+func TestGateway_RequestID(t *testing.T) {
+	t.Parallel()
+
+	mws, err := portsgrpc.HTTPMiddlewares(new(config.Config))
+	is.New(t).NoErr(err)
+
+	var forwarded string
+
+	handler := mws.Handler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			forwarded = r.Header.Get(requestid.Header)
+
+			id, _ := requestid.FromContext(r.Context())
+			is.New(t).Equal(id, forwarded)
+		},
+	))
+
+	tests := map[string]struct {
+		requestID string
+		kept      bool
+	}{
+		"Sent": {
+			requestID: "request-1",
+			kept:      true,
+		},
+		"Invalid": {
+			requestID: "request\t1",
+		},
+		"Generated": {},
+	}
+
+	// The subtests share forwarded, they aren't parallel.
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			i := is.New(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+
+			if test.requestID != "" {
+				req.Header.Set(requestid.Header, test.requestID)
+			}
+
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(requestid.Header)
+
+			i.True(requestid.Valid(id))
+			i.Equal(forwarded, id)
+
+			if test.kept {
+				i.Equal(id, test.requestID)
+			} else {
+				i.True(id != test.requestID)
+			}
+		})
+	}
+}
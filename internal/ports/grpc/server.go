@@ -2,10 +2,14 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"sync"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/oklog/run"
 	startergrpc "github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
 
 	"github.com/purposeinplay/go-commons/auth"
@@ -14,6 +18,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 
 	"google.golang.org/grpc/health/grpc_health_v1"
 
@@ -22,6 +27,7 @@ import (
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
 
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/openapi"
 	"go.uber.org/zap"
 )
 
@@ -36,68 +42,249 @@ type Server struct {
 	app        app.Application
 	cfg        *config.Config
 	server     *grpccommons.Server
+	grpcServer *grpc.Server
 	jwtManager *auth.JWTManager
+
+	// gatewayEndpoint is the endpoint of the grpc server
+	// dialed by the gateway.
+	gatewayEndpoint string
+
+	// gatewayListener is the in-memory listener connecting the
+	// gateway when the grpc server listens to its own port.
+	gatewayListener net.Listener
+
+	// httpServer serves the gateway on httpListener and,
+	// in the single port mode, the grpc server too.
+	httpServer   *http.Server
+	httpListener net.Listener
+
+	// gatewayConn connects the gateway to the grpc server.
+	gatewayConn *grpc.ClientConn
+
+	// closing is closed when the server starts closing,
+	// ending the health watches.
+	closing   chan struct{}
+	closeOnce *sync.Once
 }
 
 // NewGrpcServer runs a grpc server.
+//
+// It fails when the config is invalid or a port can't be listened to.
 func NewGrpcServer(
 	logger *zap.Logger,
 	cfg *config.Config,
 	application app.Application,
 	jwtManager *auth.JWTManager,
-) *Server {
+	serverOpts ...ServerOption,
+) (*Server, error) {
+	var options serverOptions
+
+	for _, o := range serverOpts {
+		o.apply(&options)
+	}
+
 	srv := &Server{
 		app:        application,
 		cfg:        cfg,
 		logger:     logger.Named("grpc.server"),
 		jwtManager: jwtManager,
+
+		gatewayEndpoint: grpcAddress(cfg),
+
+		closing:   make(chan struct{}),
+		closeOnce: new(sync.Once),
+	}
+
+	inProcessGateway := cfg.SERVER.SinglePort || cfg.SERVER.InProcessGateway
+
+	if inProcessGateway {
+		srv.gatewayEndpoint = inProcessEndpoint
 	}
 
-	const servicePath = "/starter.apigrpc.GoStarter/"
+	servicePath := "/" + startergrpc.GoStarter_ServiceDesc.ServiceName + "/"
 
+	// The webhooks send the events to any endpoint and expose their
+	// payloads, only the admins can manage them.
 	authRoles := map[string][]string{
-		servicePath + "FindUser": {"user"},
+		servicePath + "FindUser":                  {"user"},
+		servicePath + "CreateWebhookSubscription": {"admin"},
+		servicePath + "ListWebhookSubscriptions":  {"admin"},
+		servicePath + "DeleteWebhookSubscription": {"admin"},
+		servicePath + "ListWebhookDeliveries":     {"admin"},
+		servicePath + "RedeliverWebhook":          {"admin"},
 	}
 
 	authInterceptor := auth.NewAuthInterceptor(logger, jwtManager, authRoles)
 
+	streamAuthRoles := map[string][]string{
+		servicePath + "StreamUsers": {"admin"},
+		servicePath + "ImportUsers": {"admin"},
+	}
+
+	httpMWs, err := httpMiddlewares(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("http middlewares: %w", err)
+	}
+
+	// The gRPC-Web and Connect requests are served by the grpc server
+	// once they passed through the gateway middlewares.
+	httpMWs = append(httpMWs, srv.webProtocols)
+
 	opts := []grpccommons.ServerOption{
+		grpccommons.WithUnaryServerInterceptor(requestIDInterceptor),
 		grpccommons.WithUnaryServerInterceptorRecovery(
 			func(p any) (err error) {
 				return srv.handlePanicRecover(p)
 			},
 		),
+	}
+
+	clients := newClientIdentifier(cfg, jwtManager)
+
+	if options.rateLimitStore != nil {
+		rateLimiter := newRateLimiter(
+			logger,
+			cfg,
+			options.rateLimitStore,
+			clients,
+		)
+
+		opts = append(
+			opts,
+			grpccommons.WithUnaryServerInterceptor(rateLimiter.unary),
+		)
+	}
+
+	opts = append(
+		opts,
+		grpccommons.WithUnaryServerInterceptor(validationInterceptor),
+		grpccommons.WithUnaryServerInterceptor(readMaskInterceptor),
+	)
+
+	if options.idempotencyStore != nil {
+		opts = append(
+			opts,
+			grpccommons.WithUnaryServerInterceptor(
+				newIdempotencyInterceptor(
+					logger,
+					options.idempotencyStore,
+					options.idempotencyTTL,
+					clients,
+				).Unary(),
+			),
+		)
+	}
 
+	opts = append(
+		opts,
 		grpccommons.WithUnaryServerInterceptor(
 			authInterceptor.Unary(),
 		),
-		grpccommons.WithAddress(
-			fmt.Sprintf("%s:%d", cfg.SERVER.Address, cfg.SERVER.Port),
-		),
+		grpccommons.WithUnaryServerInterceptor(sessionInterceptor),
 		grpccommons.WithUnaryServerInterceptorLogger(
 			logger.Named("grpc.server.interceptor"),
 		),
 		grpccommons.WithUnaryServerInterceptorCodeGen(),
+		grpccommons.WithUnaryServerInterceptorHandleErr(srv.handleErr),
 		grpccommons.WithRegisterServerFunc(srv.registerGrpcServer),
-		grpccommons.WithRegisterGatewayFunc(srv.registerGatewayServer),
 		grpccommons.WithDebug(logger.Named("grpc.server.debug")),
+	)
+
+	grpcServerOpts := srv.streamServerOptions(logger, streamAuthRoles)
+
+	if !cfg.SERVER.Reflection {
+		grpcServerOpts = append(
+			[]grpc.ServerOption{
+				grpc.ChainStreamInterceptor(noReflectionInterceptor),
+			},
+			grpcServerOpts...,
+		)
+	}
+
+	opts = append(opts, grpccommons.WithGRPCServerOptions(grpcServerOpts))
+
+	var (
+		grpcLis      net.Listener
+		inProcessLis *bufconn.Listener
+	)
+
+	if inProcessGateway {
+		inProcessLis = bufconn.Listen(inProcessBufferSize)
+	}
+
+	if cfg.SERVER.SinglePort {
+		// The grpc server is served on the port of the gateway,
+		// its own listener only connects the gateway.
+		grpcLis = inProcessLis
+	} else {
+		grpcLis, err = net.Listen("tcp", grpcAddress(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("listen grpc: %w", err)
+		}
+
+		if inProcessLis != nil {
+			srv.gatewayListener = inProcessLis
+		}
+	}
+
+	// closeListeners closes the listeners when the server
+	// fails to be created.
+	closeListeners := func() {
+		_ = grpcLis.Close()
+
+		if srv.gatewayListener != nil {
+			_ = srv.gatewayListener.Close()
+		}
 	}
 
+	// The gateway is served by httpServer, go-commons
+	// only serves the grpc server.
+	opts = append(
+		opts,
+		grpccommons.WithNoGateway(),
+		grpccommons.WithGRPCListener(grpcLis),
+	)
+
 	grpcServer, err := grpccommons.NewServer(opts...)
 	if err != nil {
-		panic(err)
+		closeListeners()
+
+		return nil, fmt.Errorf("new grpc server: %w", err)
 	}
 
 	srv.server = grpcServer
 
+	srv.httpServer, err = srv.newGatewayServer(
+		httpMWs,
+		gatewayDialOptions(inProcessLis),
+		cfg.SERVER.SinglePort,
+	)
+	if err != nil {
+		closeListeners()
+
+		return nil, fmt.Errorf("new gateway server: %w", err)
+	}
+
+	srv.httpListener, err = net.Listen(
+		"tcp",
+		fmt.Sprintf("%s:%d", cfg.SERVER.Address, cfg.SERVER.Port),
+	)
+	if err != nil {
+		_ = srv.gatewayConn.Close()
+
+		closeListeners()
+
+		return nil, fmt.Errorf("listen http: %w", err)
+	}
+
 	go func() {
-		err := grpcServer.ListenAndServe()
+		err := srv.ListenAndServe()
 		if err != nil {
 			logger.Error("listen and serve err", zap.Error(err))
 		}
 	}()
 
-	return srv
+	return srv, nil
 }
 
 // NewGrpcTestServer returns a new grpc server to be used in tests.
@@ -107,15 +294,23 @@ func NewGrpcTestServer(
 	listener net.Listener,
 ) *Server {
 	srv := &Server{
-		app:    application,
-		logger: logger.Named("grpc.server"),
+		app:       application,
+		logger:    logger.Named("grpc.server"),
+		closing:   make(chan struct{}),
+		closeOnce: new(sync.Once),
 	}
 
 	opts := []grpccommons.ServerOption{
+		grpccommons.WithUnaryServerInterceptor(requestIDInterceptor),
+		grpccommons.WithUnaryServerInterceptor(validationInterceptor),
+		grpccommons.WithUnaryServerInterceptor(readMaskInterceptor),
 		grpccommons.WithUnaryServerInterceptorHandleErr(srv.handleErr),
 
 		grpccommons.WithNoGateway(),
 		grpccommons.WithGRPCListener(listener),
+		grpccommons.WithGRPCServerOptions(
+			srv.streamServerOptions(logger, nil),
+		),
 		grpccommons.WithUnaryServerInterceptorLogger(
 			logger.Named("grpc.test_server.interceptor"),
 		),
@@ -133,14 +328,84 @@ func NewGrpcTestServer(
 	return srv
 }
 
-// ListenAndServe wraps the underlying
-// grpccommons.Server ListenAndServe method.
+// ListenAndServe wraps the underlying grpccommons.Server
+// ListenAndServe method, serving the gateway too when
+// there is one. Both are stopped when one of them fails.
 func (s *Server) ListenAndServe() error {
-	return s.server.ListenAndServe()
+	if s.httpServer == nil {
+		return s.server.ListenAndServe()
+	}
+
+	var g run.Group
+
+	g.Add(
+		s.server.ListenAndServe,
+		func(error) {
+			_ = s.closeGRPCServer()
+		},
+	)
+
+	if s.gatewayListener != nil {
+		g.Add(
+			func() error {
+				return s.grpcServer.Serve(s.gatewayListener)
+			},
+			func(error) {
+				_ = s.gatewayListener.Close()
+			},
+		)
+	}
+
+	g.Add(
+		func() error {
+			err := s.httpServer.Serve(s.httpListener)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return nil
+		},
+		func(error) {
+			_ = s.httpServer.Close()
+		},
+	)
+
+	return g.Run()
 }
 
 // Close terminates the server.
 func (s *Server) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+	})
+
+	if s.httpServer != nil {
+		// The gateway is closed first,
+		// as it sits above the grpc server.
+		err := s.httpServer.Shutdown(context.Background())
+		if err != nil {
+			return fmt.Errorf("shutdown http server: %w", err)
+		}
+	}
+
+	if s.gatewayConn != nil {
+		err := s.gatewayConn.Close()
+		if err != nil && status.Code(err) != codes.Canceled {
+			return fmt.Errorf("close gateway connection: %w", err)
+		}
+	}
+
+	return s.closeGRPCServer()
+}
+
+// closeGRPCServer closes the grpc server. In the single port mode,
+// its connections are served by the gateway and can't be drained
+// by the graceful stop of go-commons, they are closed first.
+func (s *Server) closeGRPCServer() error {
+	if s.cfg != nil && s.cfg.SERVER.SinglePort {
+		s.grpcServer.Stop()
+	}
+
 	return s.server.Close()
 }
 
@@ -163,31 +428,112 @@ func (*Server) Check(
 }
 
 // Watch works like Check, but it creates an ongoing connection.
-func (*Server) Watch(
-	*grpc_health_v1.HealthCheckRequest,
-	grpc_health_v1.Health_WatchServer,
+// It sends SERVING then, when the server closes, NOT_SERVING
+// and ends the stream.
+func (s *Server) Watch(
+	_ *grpc_health_v1.HealthCheckRequest,
+	stream grpc_health_v1.Health_WatchServer,
 ) error {
-	return status.Error(codes.Unimplemented, "unimplemented")
+	err := stream.Send(&grpc_health_v1.HealthCheckResponse{
+		Status: grpc_health_v1.HealthCheckResponse_SERVING,
+	})
+	if err != nil {
+		return fmt.Errorf("send status: %w", err)
+	}
+
+	select {
+	case <-stream.Context().Done():
+		return status.FromContextError(stream.Context().Err()).Err()
+
+	case <-s.closing:
+		err := stream.Send(&grpc_health_v1.HealthCheckResponse{
+			Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		})
+		if err != nil {
+			return fmt.Errorf("send status: %w", err)
+		}
+
+		return nil
+	}
 }
 
 func (s *Server) registerGrpcServer(server *grpc.Server) {
+	s.grpcServer = server
+
 	startergrpc.RegisterGoStarterServer(server, s)
 	grpc_health_v1.RegisterHealthServer(server, s)
 }
 
-func (s Server) registerGatewayServer(
+func (s *Server) registerGatewayServer(
 	mux *runtime.ServeMux,
 	dialOptions []grpc.DialOption,
 ) error {
-	err := startergrpc.RegisterGoStarterHandlerFromEndpoint(
+	conn, err := grpc.DialContext(
+		context.Background(),
+		s.gatewayEndpoint,
+		dialOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("dial gRPC server: %w", err)
+	}
+
+	// The connection is shared by the gateway handlers
+	// and closed with the server.
+	s.gatewayConn = conn
+
+	err = startergrpc.RegisterGoStarterHandler(
 		context.Background(),
 		mux,
-		fmt.Sprintf("%s:%d", s.cfg.SERVER.Address, s.cfg.SERVER.Port-1),
-		dialOptions,
+		conn,
 	)
 	if err != nil {
 		return fmt.Errorf("register gRPC gateway: %w", err)
 	}
 
+	err = mux.HandlePath(
+		http.MethodPost,
+		importUsersPath,
+		importUsersCSV(mux, startergrpc.NewGoStarterClient(conn)),
+	)
+	if err != nil {
+		return fmt.Errorf("register import users handler: %w", err)
+	}
+
+	err = registerDocs(mux)
+	if err != nil {
+		return fmt.Errorf("register docs: %w", err)
+	}
+
+	return nil
+}
+
+// registerDocs serves the OpenAPI specs and the docs UI.
+func registerDocs(mux *runtime.ServeMux) error {
+	docs, err := openapi.NewDocs(startergrpc.OpenAPI)
+	if err != nil {
+		return fmt.Errorf("new docs: %w", err)
+	}
+
+	handlers := map[string]http.HandlerFunc{
+		openapi.SpecPath:   docs.ServeV2,
+		openapi.SpecV3Path: docs.ServeV3,
+		openapi.DocsPath:   docs.ServeUI,
+	}
+
+	for path, handler := range handlers {
+		handler := handler
+
+		err = mux.HandlePath(
+			http.MethodGet,
+			path,
+			func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				handler(w, r)
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("handle %s: %w", path, err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/purposeinplay/go-commons/auth"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/session"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// sessionInterceptor identifies the session of the request by its
+// bearer token, so the reads following a write of the same client
+// can be routed to the primary database.
+//
+// The token is hashed so it is not kept around in memory.
+func sessionInterceptor(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	token, err := auth.ExtractTokenFromMetadata(md)
+	if err != nil {
+		return handler(ctx, req)
+	}
+
+	sum := sha256.Sum256([]byte(token))
+
+	return handler(session.NewContext(ctx, hex.EncodeToString(sum[:])), req)
+}
@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/purposeinplay/go-commons/auth"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// streamServerOptions returns the grpc server options installing the
+// stream interceptors, go-commons only installs unary ones.
+//
+// Like for the unary RPCs, the streams recover from panics, carry
+// a request id, are logged, authorized against the roles allowed to call them and
+// their errors are converted to grpc statuses.
+func (s *Server) streamServerOptions(
+	logger *zap.Logger,
+	authRoles map[string][]string,
+) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainStreamInterceptor(
+			grpc_recovery.StreamServerInterceptor(
+				grpc_recovery.WithRecoveryHandler(s.handlePanicRecover),
+			),
+			grpc_ctxtags.StreamServerInterceptor(
+				grpc_ctxtags.WithFieldExtractor(
+					grpc_ctxtags.CodeGenRequestFieldExtractor,
+				),
+			),
+			streamRequestIDInterceptor,
+			grpc_zap.StreamServerInterceptor(
+				logger.Named("grpc.server.interceptor"),
+			),
+			s.streamAuthInterceptor(authRoles),
+			s.streamErrInterceptor,
+		),
+	}
+}
+
+// streamAuthInterceptor authorizes the streams whose method is in
+// authRoles for the callers with one of the listed roles, the other
+// streams are public.
+func (s *Server) streamAuthInterceptor(
+	authRoles map[string][]string,
+) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		roles, ok := authRoles[info.FullMethod]
+		if !ok {
+			return handler(srv, stream)
+		}
+
+		md, _ := metadata.FromIncomingContext(stream.Context())
+
+		token, err := auth.ExtractTokenFromMetadata(md)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, "auth token is invalid")
+		}
+
+		claims, err := s.jwtManager.Verify(token)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, "auth token is invalid")
+		}
+
+		for _, role := range roles {
+			if role != claims.Role {
+				continue
+			}
+
+			wrapped := grpc_middleware.WrapServerStream(stream)
+			wrapped.WrappedContext = auth.WithUser(stream.Context(), claims)
+
+			return handler(srv, wrapped)
+		}
+
+		return status.Error(
+			codes.PermissionDenied,
+			"no permission to access this RPC",
+		)
+	}
+}
+
+// streamErrInterceptor converts the errors returned by the stream
+// handlers to grpc statuses, like handleErr does for the unary ones
+// except that internal errors are only logged.
+// A stream canceled by the client ends with its context error.
+func (s *Server) streamErrInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	err := handler(srv, stream)
+	if err == nil {
+		return nil
+	}
+
+	if ctxErr := stream.Context().Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+
+	var applicationError *errors.Error
+
+	if errors.As(err, &applicationError) {
+		st := errorToGRPCStatus(applicationError)
+
+		details := errorDetailsToGRPCDetails(applicationError)
+		if details == nil {
+			return st.Err()
+		}
+
+		withDetails, detailsErr := st.WithDetails(details)
+		if detailsErr != nil {
+			return st.Err()
+		}
+
+		return withDetails.Err()
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+
+	s.logErr(err)
+
+	return status.Error(codes.Internal, "internal error.")
+}
@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+)
+
+// StreamUsers streams all the users of the system.
+//
+// A batch is read only after the previous one was sent, Send blocks
+// while the client doesn't keep up, and the stream stops as soon as
+// the client cancels it.
+func (s *Server) StreamUsers(
+	req *startergrpc.StreamUsersRequest,
+	stream startergrpc.GoStarter_StreamUsersServer,
+) error {
+	err := s.app.Queries.StreamUsers.Handle(
+		stream.Context(),
+		int(req.GetBatchSize()),
+		func(users []query.User) error {
+			for _, u := range users {
+				err := stream.Send(userToProto(u))
+				if err != nil {
+					return fmt.Errorf("send user: %w", err)
+				}
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("stream users query: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+)
+
+func TestServer_StreamUsers(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+	ctx := context.Background()
+
+	repo := memory.NewUserRepository()
+
+	ids := make(map[string]bool)
+
+	for n := 0; n < 5; n++ {
+		id, err := uuid.Parse(fmt.Sprintf(
+			"00000000-0000-0000-0000-%012d",
+			n+1,
+		))
+		i.NoErr(err)
+
+		u := user.MustNew(id, fmt.Sprintf("user%d@test.com", n))
+
+		i.NoErr(repo.CreateUser(ctx, u))
+
+		ids[u.ID().String()] = true
+	}
+
+	_, conn := newTestServer(t, app.Application{
+		Queries: app.Queries{
+			StreamUsers: query.MustNewStreamUsersHandler(repo),
+		},
+	})
+
+	client := startergrpc.NewGoStarterClient(conn)
+
+	t.Run("StreamsAllUsers", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		stream, err := client.StreamUsers(
+			ctx,
+			&startergrpc.StreamUsersRequest{BatchSize: 2},
+		)
+		i.NoErr(err)
+
+		var received []*startergrpc.User
+
+		for {
+			u, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+
+			i.NoErr(err)
+
+			received = append(received, u)
+		}
+
+		i.Equal(len(ids), len(received))
+
+		for idx, u := range received {
+			i.True(ids[u.GetId()])
+
+			if idx > 0 {
+				i.True(received[idx-1].GetId() < u.GetId())
+			}
+		}
+	})
+
+}
@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/auth"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// UpdateUser changes the fields of a user named by the update mask.
+func (s *Server) UpdateUser(
+	ctx context.Context,
+	req *startergrpc.UpdateUserRequest,
+) (*startergrpc.UpdateUserResponse, error) {
+	if req.User.Id != nil && req.User.GetId() != req.Id {
+		return nil, invalidRequestError(
+			&errdetails.BadRequest_FieldViolation{
+				Field:       "user.id",
+				Description: "must be the id of the user",
+			},
+		)
+	}
+
+	mask, err := userUpdateMask(req.UpdateMask)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := auth.UUIDFromContextJWT(ctx, s.jwtManager)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"authenticate user: %w",
+			err,
+		)
+	}
+
+	if u.String() != req.Id {
+		return nil, errors.NewUnauthorizedError("user")
+	}
+
+	userID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, errors.NewInvalidError("invalid user id")
+	}
+
+	version := req.User.GetVersion()
+
+	if version == 0 {
+		version, err = versionFromIfMatch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("version from if match: %w", err)
+		}
+	}
+
+	if mask[userEmailPath] {
+		err = s.app.Commands.ChangeUserEmail.Handle(ctx, command.ChangeUserEmail{
+			ID:              userID,
+			Email:           req.User.GetEmail(),
+			ExpectedVersion: version,
+		})
+		if err != nil {
+			return nil, fmt.Errorf(
+				"change user email command: %w",
+				err,
+			)
+		}
+	}
+
+	user, err := s.app.Queries.UserByID.Handle(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"user by id query: %w",
+			err,
+		)
+	}
+
+	return &startergrpc.UpdateUserResponse{
+		User: userToProto(user),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// userEmailPath is the update mask path of the user email.
+const userEmailPath = "email"
+
+// userUpdatePaths holds the paths accepted in the update mask of
+// UpdateUser, mapped to whether the field they name is mutable.
+var userUpdatePaths = map[string]bool{
+	"id":          false,
+	userEmailPath: true,
+	"version":     false,
+}
+
+// userToProto returns the response entity of the user.
+func userToProto(u query.User) *startergrpc.User {
+	return &startergrpc.User{
+		Id:      proto.String(u.ID.String()),
+		Email:   proto.String(u.Email),
+		Version: proto.Int64(u.Version),
+	}
+}
+
+// userUpdateMask returns the mutable fields of the user named by
+// the update mask, all of them when the mask is not set or is "*".
+//
+// The mask is rejected when it names a field not accepted by
+// UpdateUser or no mutable field.
+func userUpdateMask(mask *fieldmaskpb.FieldMask) (map[string]bool, error) {
+	paths := mask.GetPaths()
+
+	if len(paths) == 0 || len(paths) == 1 && paths[0] == "*" {
+		paths = make([]string, 0, len(userUpdatePaths))
+
+		for path := range userUpdatePaths {
+			paths = append(paths, path)
+		}
+	}
+
+	mutable := make(map[string]bool, len(userUpdatePaths))
+
+	for _, path := range paths {
+		isMutable, ok := userUpdatePaths[path]
+		if !ok {
+			return nil, invalidRequestError(
+				&errdetails.BadRequest_FieldViolation{
+					Field:       "update_mask",
+					Description: "must not contain " + path,
+				},
+			)
+		}
+
+		if isMutable {
+			mutable[path] = true
+		}
+	}
+
+	if len(mutable) == 0 {
+		return nil, invalidRequestError(
+			&errdetails.BadRequest_FieldViolation{
+				Field:       "update_mask",
+				Description: "must contain a mutable field",
+			},
+		)
+	}
+
+	return mutable, nil
+}
@@ -45,6 +45,8 @@ func TestServer_CreateUser(t *testing.T) {
 	}
 
 	for name, test := range tests {
+		test := test
+
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
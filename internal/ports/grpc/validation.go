@@ -0,0 +1,175 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// validationInterceptor checks the request against the rules declared
+// on its fields in the proto, with the startergrpc.v1.rules option,
+// and rejects it with InvalidArgument listing the field violations
+// before its handler runs.
+func validationInterceptor(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	violations := validateMessage(msg.ProtoReflect(), "")
+	if len(violations) == 0 {
+		return handler(ctx, req)
+	}
+
+	return nil, invalidRequestError(violations...)
+}
+
+// invalidRequestError returns an InvalidArgument status error
+// listing the field violations.
+func invalidRequestError(
+	violations ...*errdetails.BadRequest_FieldViolation,
+) error {
+	st := status.New(codes.InvalidArgument, "invalid request")
+
+	withDetails, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: violations,
+	})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// validateMessage returns the violations of the rules declared on the
+// fields of the message and of its nested messages, the fields are
+// named by their path prefixed with the given one.
+func validateMessage(
+	m protoreflect.Message,
+	prefix string,
+) []*errdetails.BadRequest_FieldViolation {
+	var (
+		violations []*errdetails.BadRequest_FieldViolation
+		fields     = m.Descriptor().Fields()
+	)
+
+	for idx := 0; idx < fields.Len(); idx++ {
+		fd := fields.Get(idx)
+		name := prefix + string(fd.Name())
+
+		rules, _ := proto.GetExtension(
+			fd.Options(),
+			startergrpc.E_Rules,
+		).(*startergrpc.FieldRules)
+
+		if rules != nil {
+			violation := validateField(m, fd, rules)
+			if violation != "" {
+				violations = append(
+					violations,
+					&errdetails.BadRequest_FieldViolation{
+						Field:       name,
+						Description: violation,
+					},
+				)
+			}
+		}
+
+		if fd.Kind() == protoreflect.MessageKind &&
+			fd.Cardinality() != protoreflect.Repeated &&
+			m.Has(fd) {
+			violations = append(
+				violations,
+				validateMessage(m.Get(fd).Message(), name+".")...,
+			)
+		}
+	}
+
+	return violations
+}
+
+// validateField returns the description of the first rule the
+// field violates, empty when it satisfies all of them.
+func validateField(
+	m protoreflect.Message,
+	fd protoreflect.FieldDescriptor,
+	rules *startergrpc.FieldRules,
+) string {
+	if !m.Has(fd) {
+		if rules.GetRequired() {
+			return "must be set"
+		}
+
+		return ""
+	}
+
+	if fd.Kind() != protoreflect.StringKind ||
+		fd.Cardinality() == protoreflect.Repeated {
+		return ""
+	}
+
+	value := m.Get(fd).String()
+	length := uint32(utf8.RuneCountInString(value))
+
+	switch {
+	case rules.GetMinLen() > 0 && length < rules.GetMinLen():
+		return fmt.Sprintf(
+			"must be at least %d characters",
+			rules.GetMinLen(),
+		)
+
+	case rules.GetMaxLen() > 0 && length > rules.GetMaxLen():
+		return fmt.Sprintf(
+			"must be at most %d characters",
+			rules.GetMaxLen(),
+		)
+
+	case rules.GetEmail() && !isEmail(value):
+		return "must be an email address"
+
+	case rules.GetUuid() && !isUUID(value):
+		return "must be a UUID"
+
+	case rules.GetHttpUrl() && !isHTTPURL(value):
+		return "must be an absolute http or https URL"
+	}
+
+	return ""
+}
+
+func isEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+
+	return err == nil && addr.Name == "" && addr.Address == value
+}
+
+func isUUID(value string) bool {
+	_, err := uuid.Parse(value)
+
+	return err == nil
+}
+
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	return u.Scheme == "http" || u.Scheme == "https"
+}
@@ -0,0 +1,165 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_Validation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+
+	_, conn := newTestServer(t, app.Application{})
+
+	client := startergrpc.NewGoStarterClient(conn)
+
+	// violations returns the field violations of the error by field.
+	violations := func(t *testing.T, err error) map[string]string {
+		i := is.New(t)
+
+		st, ok := status.FromError(err)
+		i.True(ok)
+		i.Equal(codes.InvalidArgument, st.Code())
+
+		byField := make(map[string]string)
+
+		for _, d := range st.Details() {
+			br, ok := d.(*errdetails.BadRequest)
+			if !ok {
+				continue
+			}
+
+			for _, v := range br.GetFieldViolations() {
+				byField[v.GetField()] = v.GetDescription()
+			}
+		}
+
+		return byField
+	}
+
+	t.Run("CreateUser", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		_, err := client.CreateUser(ctx, &startergrpc.CreateUserRequest{})
+		i.Equal(
+			map[string]string{"email": "must be set"},
+			violations(t, err),
+		)
+
+		_, err = client.CreateUser(ctx, &startergrpc.CreateUserRequest{
+			Email: "not an email",
+		})
+		i.Equal(
+			map[string]string{"email": "must be an email address"},
+			violations(t, err),
+		)
+	})
+
+	t.Run("GetUser", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		_, err := client.GetUser(ctx, &startergrpc.GetUserRequest{
+			Id: "1",
+		})
+		i.Equal(
+			map[string]string{"id": "must be a UUID"},
+			violations(t, err),
+		)
+	})
+
+	t.Run("CreateWebhookSubscription", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		_, err := client.CreateWebhookSubscription(
+			ctx,
+			&startergrpc.CreateWebhookSubscriptionRequest{
+				Url:    "ftp://example.com",
+				Secret: "short",
+			},
+		)
+		i.Equal(
+			map[string]string{
+				"url":         "must be an absolute http or https URL",
+				"event_types": "must be set",
+				"secret":      "must be at least 16 characters",
+			},
+			violations(t, err),
+		)
+	})
+
+	t.Run("WebhookURL", func(t *testing.T) {
+		t.Parallel()
+
+		tests := map[string]struct {
+			url       string
+			violation string
+		}{
+			"HTTP": {
+				url:       "http://example.com/webhooks",
+				violation: "must be an https URL",
+			},
+			"Loopback": {
+				url: "https://127.0.0.1/webhooks",
+				violation: "must not resolve to a loopback, " +
+					"link-local or private address",
+			},
+			"Localhost": {
+				url: "https://localhost:8443/webhooks",
+				violation: "must not resolve to a loopback, " +
+					"link-local or private address",
+			},
+			"Private": {
+				url: "https://10.0.0.1/webhooks",
+				violation: "must not resolve to a loopback, " +
+					"link-local or private address",
+			},
+			"LinkLocal": {
+				url: "https://169.254.169.254/latest/meta-data",
+				violation: "must not resolve to a loopback, " +
+					"link-local or private address",
+			},
+			"IPv6Loopback": {
+				url: "https://[::1]/webhooks",
+				violation: "must not resolve to a loopback, " +
+					"link-local or private address",
+			},
+		}
+
+		for name, test := range tests {
+			test := test
+
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				i := is.New(t)
+
+				_, err := client.CreateWebhookSubscription(
+					ctx,
+					&startergrpc.CreateWebhookSubscriptionRequest{
+						Url:        test.url,
+						EventTypes: []string{"user.created"},
+						Secret:     "0123456789abcdef",
+					},
+				)
+				i.Equal(
+					map[string]string{"url": test.violation},
+					violations(t, err),
+				)
+			})
+		}
+	})
+}
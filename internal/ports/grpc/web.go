@@ -0,0 +1,853 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// The content types of the web protocols served on the gateway.
+const (
+	grpcWebContentType        = "application/grpc-web"
+	grpcWebTextContentType    = "application/grpc-web-text"
+	connectProtoContentType   = "application/proto"
+	connectJSONContentType    = "application/json"
+	connectStreamProtoContent = "application/connect+proto"
+	connectStreamJSONContent  = "application/connect+json"
+
+	// grpcContentType is the content type of the requests
+	// forwarded to the grpc server.
+	grpcContentType = "application/grpc+proto"
+)
+
+// The flags of the messages framed by the streaming protocols.
+const (
+	flagCompressed = 0b00000001
+	// flagConnectEndStream marks the end of a Connect stream.
+	flagConnectEndStream = 0b00000010
+	// flagGRPCWebTrailer marks the trailers of a gRPC-Web response.
+	flagGRPCWebTrailer = 0b10000000
+
+	envelopeHeaderSize = 5
+)
+
+// webProtocols returns the middleware serving the gRPC-Web and
+// Connect requests for the GoStarter service with the grpc server,
+// so they go through the same interceptors as the grpc requests.
+// The other requests are passed to the next handler.
+//
+// gRPC-Web is served in its binary and text encodings. Connect is
+// served for the unary RPCs, posted as application/proto or
+// application/json, and for the streaming ones, posted as
+// application/connect+proto or application/connect+json.
+func (s *Server) webProtocols(next http.Handler) http.Handler {
+	servicePath := "/" + startergrpc.GoStarter_ServiceDesc.ServiceName + "/"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost ||
+			!strings.HasPrefix(r.URL.Path, servicePath) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		contentType := strings.TrimSpace(
+			strings.Split(r.Header.Get("Content-Type"), ";")[0],
+		)
+
+		switch contentType {
+		case grpcWebContentType, grpcWebContentType + "+proto":
+			s.serveGRPCWeb(w, r, false)
+
+		case grpcWebTextContentType, grpcWebTextContentType + "+proto":
+			s.serveGRPCWeb(w, r, true)
+
+		case connectProtoContentType, connectJSONContentType:
+			s.serveConnectUnary(w, r, contentType)
+
+		case connectStreamProtoContent, connectStreamJSONContent:
+			s.serveConnectStream(w, r, contentType)
+
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// serveGRPC serves the request on the grpc server, reading the framed
+// request messages from body and passing the response to res.
+func (s *Server) serveGRPC(
+	r *http.Request,
+	body io.Reader,
+	res grpcResponse,
+) {
+	req := r.Clone(r.Context())
+
+	// The grpc server only serves HTTP/2 requests,
+	// the bridged ones are answered by res.
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.Header.Set("Content-Type", grpcContentType)
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	req.Body = io.NopCloser(body)
+
+	w := &grpcResponseWriter{
+		header: make(http.Header),
+		res:    res,
+	}
+
+	s.grpcServer.ServeHTTP(w, req)
+
+	w.commit()
+
+	res.writeEnd(trailerStatus(w.header), trailerMetadata(w.header))
+}
+
+// grpcResponse receives the response of the grpc server.
+type grpcResponse interface {
+	// writeHeader is called with the header metadata
+	// before the first message.
+	writeHeader(md http.Header)
+	// writeMessage is called with every marshaled response message.
+	writeMessage(msg []byte)
+	// writeEnd is called with the status and the trailer metadata
+	// once the call ended.
+	writeEnd(st *status.Status, trailer http.Header)
+}
+
+// grpcResponseWriter is the http.ResponseWriter the grpc server
+// writes the response to, it splits the response in header
+// metadata, messages and trailers.
+type grpcResponseWriter struct {
+	header    http.Header
+	committed bool
+	buf       bytes.Buffer
+	res       grpcResponse
+}
+
+func (w *grpcResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *grpcResponseWriter) WriteHeader(int) {
+	w.commit()
+}
+
+func (w *grpcResponseWriter) Write(p []byte) (int, error) {
+	w.commit()
+
+	w.buf.Write(p)
+
+	for {
+		flags, msg, ok := nextEnvelope(&w.buf)
+		if !ok {
+			return len(p), nil
+		}
+
+		// The grpc server doesn't compress the messages
+		// of the clients not accepting it.
+		if flags&flagCompressed == 0 {
+			w.res.writeMessage(msg)
+		}
+	}
+}
+
+// Flush satisfies the http.Flusher interface, required by
+// the grpc server.
+func (w *grpcResponseWriter) Flush() {
+	w.commit()
+}
+
+// commit passes the header metadata to the response the first time
+// the grpc server writes the header, a message or the trailers.
+func (w *grpcResponseWriter) commit() {
+	if w.committed {
+		return
+	}
+
+	w.committed = true
+
+	md := make(http.Header, len(w.header))
+
+	for key, values := range w.header {
+		switch strings.ToLower(key) {
+		case "content-type", "trailer", "date", "grpc-encoding":
+			continue
+		}
+
+		md[key] = values
+	}
+
+	w.res.writeHeader(md)
+}
+
+// nextEnvelope removes the next framed message from the buffer,
+// ok is false when the buffer doesn't hold a whole message yet.
+func nextEnvelope(buf *bytes.Buffer) (flags byte, msg []byte, ok bool) {
+	if buf.Len() < envelopeHeaderSize {
+		return 0, nil, false
+	}
+
+	header := buf.Bytes()[:envelopeHeaderSize]
+	size := int(binary.BigEndian.Uint32(header[1:]))
+
+	if buf.Len() < envelopeHeaderSize+size {
+		return 0, nil, false
+	}
+
+	flags = header[0]
+	buf.Next(envelopeHeaderSize)
+
+	msg = make([]byte, size)
+	copy(msg, buf.Next(size))
+
+	return flags, msg, true
+}
+
+// readEnvelope reads a framed message.
+func readEnvelope(r io.Reader) (flags byte, msg []byte, err error) {
+	var header [envelopeHeaderSize]byte
+
+	_, err = io.ReadFull(r, header[:])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	msg = make([]byte, binary.BigEndian.Uint32(header[1:]))
+
+	_, err = io.ReadFull(r, msg)
+	if err != nil {
+		return 0, nil, fmt.Errorf("read message: %w", err)
+	}
+
+	return header[0], msg, nil
+}
+
+// envelope frames the message with the given flags.
+func envelope(flags byte, msg []byte) []byte {
+	b := make([]byte, envelopeHeaderSize+len(msg))
+
+	b[0] = flags
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	copy(b[envelopeHeaderSize:], msg)
+
+	return b
+}
+
+// trailerStatus returns the status of the call
+// written in the trailers by the grpc server.
+func trailerStatus(h http.Header) *status.Status {
+	if details := h.Get("Grpc-Status-Details-Bin"); details != "" {
+		b, err := base64.RawStdEncoding.DecodeString(
+			strings.TrimRight(details, "="),
+		)
+		if err == nil {
+			st := new(spb.Status)
+
+			if proto.Unmarshal(b, st) == nil {
+				return status.FromProto(st)
+			}
+		}
+	}
+
+	code, err := strconv.Atoi(h.Get("Grpc-Status"))
+	if err != nil {
+		return status.New(codes.Unknown, "missing grpc status")
+	}
+
+	msg, err := url.PathUnescape(h.Get("Grpc-Message"))
+	if err != nil {
+		msg = h.Get("Grpc-Message")
+	}
+
+	return status.New(codes.Code(code), msg)
+}
+
+// trailerMetadata returns the trailer metadata
+// written by the grpc server.
+func trailerMetadata(h http.Header) http.Header {
+	md := make(http.Header)
+
+	for key, values := range h {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			md[strings.TrimPrefix(key, http.TrailerPrefix)] = values
+		}
+	}
+
+	return md
+}
+
+// serveGRPCWeb serves a gRPC-Web request, encoded
+// in base64 when text is set.
+func (s *Server) serveGRPCWeb(
+	w http.ResponseWriter,
+	r *http.Request,
+	text bool,
+) {
+	var body io.Reader = r.Body
+
+	contentType := grpcWebContentType + "+proto"
+
+	if text {
+		body = &base64Reader{r: r.Body}
+		contentType = grpcWebTextContentType + "+proto"
+	}
+
+	s.serveGRPC(r, body, &grpcWebResponse{
+		w:           w,
+		contentType: contentType,
+		text:        text,
+	})
+}
+
+// grpcWebResponse writes the response of the grpc server
+// as a gRPC-Web response.
+type grpcWebResponse struct {
+	w           http.ResponseWriter
+	contentType string
+	text        bool
+}
+
+func (res *grpcWebResponse) writeHeader(md http.Header) {
+	h := res.w.Header()
+
+	for key, values := range md {
+		h[key] = values
+	}
+
+	h.Set("Content-Type", res.contentType)
+	res.w.WriteHeader(http.StatusOK)
+}
+
+func (res *grpcWebResponse) writeMessage(msg []byte) {
+	res.write(envelope(0, msg))
+}
+
+func (res *grpcWebResponse) writeEnd(st *status.Status, trailer http.Header) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "grpc-status: %d\r\n", st.Code())
+
+	if st.Message() != "" {
+		fmt.Fprintf(&b, "grpc-message: %s\r\n", url.PathEscape(st.Message()))
+	}
+
+	if p := st.Proto(); len(p.GetDetails()) > 0 {
+		details, err := proto.Marshal(p)
+		if err == nil {
+			fmt.Fprintf(
+				&b,
+				"grpc-status-details-bin: %s\r\n",
+				base64.RawStdEncoding.EncodeToString(details),
+			)
+		}
+	}
+
+	for key, values := range trailer {
+		for _, value := range values {
+			fmt.Fprintf(&b, "%s: %s\r\n", strings.ToLower(key), value)
+		}
+	}
+
+	res.write(envelope(flagGRPCWebTrailer, []byte(b.String())))
+}
+
+func (res *grpcWebResponse) write(b []byte) {
+	if res.text {
+		b = []byte(base64.StdEncoding.EncodeToString(b))
+	}
+
+	_, _ = res.w.Write(b)
+
+	if f, ok := res.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// base64Reader decodes the base64 chunks, each one padded,
+// of a gRPC-Web text request.
+type base64Reader struct {
+	r       io.Reader
+	group   [4]byte
+	decoded []byte
+}
+
+func (br *base64Reader) Read(p []byte) (int, error) {
+	for len(br.decoded) == 0 {
+		_, err := io.ReadFull(br.r, br.group[:])
+		if err == io.ErrUnexpectedEOF {
+			return 0, fmt.Errorf("truncated base64: %w", err)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		var decoded [3]byte
+
+		n, err := base64.StdEncoding.Decode(decoded[:], br.group[:])
+		if err != nil {
+			return 0, fmt.Errorf("decode base64: %w", err)
+		}
+
+		br.decoded = decoded[:n]
+	}
+
+	n := copy(p, br.decoded)
+	br.decoded = br.decoded[n:]
+
+	return n, nil
+}
+
+// serveConnectUnary serves a Connect unary request.
+func (s *Server) serveConnectUnary(
+	w http.ResponseWriter,
+	r *http.Request,
+	contentType string,
+) {
+	res := &connectUnaryResponse{
+		w:           w,
+		contentType: contentType,
+	}
+
+	codec, err := newConnectCodec(r.URL.Path, contentType)
+	if err != nil {
+		res.writeEnd(status.Convert(err), nil)
+
+		return
+	}
+
+	res.codec = codec
+
+	setGRPCTimeout(r)
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		res.writeEnd(status.New(codes.InvalidArgument, err.Error()), nil)
+
+		return
+	}
+
+	msg, err := codec.requestToProto(b)
+	if err != nil {
+		res.writeEnd(status.Convert(err), nil)
+
+		return
+	}
+
+	s.serveGRPC(r, bytes.NewReader(envelope(0, msg)), res)
+}
+
+// connectUnaryResponse writes the response of the grpc server as a
+// Connect unary response, once the call ended.
+type connectUnaryResponse struct {
+	w           http.ResponseWriter
+	contentType string
+	codec       connectCodec
+	header      http.Header
+	msg         []byte
+}
+
+func (res *connectUnaryResponse) writeHeader(md http.Header) {
+	res.header = md
+}
+
+func (res *connectUnaryResponse) writeMessage(msg []byte) {
+	res.msg = msg
+}
+
+func (res *connectUnaryResponse) writeEnd(
+	st *status.Status,
+	trailer http.Header,
+) {
+	h := res.w.Header()
+
+	for key, values := range res.header {
+		h[key] = values
+	}
+
+	for key, values := range trailer {
+		h["Trailer-"+http.CanonicalHeaderKey(key)] = values
+	}
+
+	if st.Code() != codes.OK {
+		h.Set("Content-Type", connectJSONContentType)
+		res.w.WriteHeader(connectHTTPStatus(st.Code()))
+
+		_ = json.NewEncoder(res.w).Encode(newConnectError(st))
+
+		return
+	}
+
+	msg, err := res.codec.responseFromProto(res.msg)
+	if err != nil {
+		res.writeEnd(status.New(codes.Internal, err.Error()), nil)
+
+		return
+	}
+
+	h.Set("Content-Type", res.contentType)
+	res.w.WriteHeader(http.StatusOK)
+
+	_, _ = res.w.Write(msg)
+}
+
+// serveConnectStream serves a Connect streaming request.
+func (s *Server) serveConnectStream(
+	w http.ResponseWriter,
+	r *http.Request,
+	contentType string,
+) {
+	res := &connectStreamResponse{
+		w:           w,
+		contentType: contentType,
+	}
+
+	codec, err := newConnectCodec(r.URL.Path, contentType)
+	if err != nil {
+		res.writeHeader(nil)
+		res.writeEnd(status.Convert(err), nil)
+
+		return
+	}
+
+	res.codec = codec
+
+	setGRPCTimeout(r)
+
+	// The request messages are converted to protobuf
+	// while the grpc server reads them.
+	pr, pw := io.Pipe()
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		pw.CloseWithError(convertConnectStream(r.Body, pw, codec))
+	}()
+
+	s.serveGRPC(r, pr, res)
+
+	_ = pr.Close()
+
+	wg.Wait()
+}
+
+// convertConnectStream writes the messages of the Connect request
+// stream to the grpc request stream.
+func convertConnectStream(
+	r io.Reader,
+	w io.Writer,
+	codec connectCodec,
+) error {
+	for {
+		flags, msg, err := readEnvelope(r)
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if flags&flagCompressed != 0 {
+			return fmt.Errorf("compressed messages are not supported")
+		}
+
+		msg, err = codec.requestToProto(msg)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(envelope(0, msg))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// connectStreamResponse writes the response of the grpc
+// server as a Connect streaming response.
+type connectStreamResponse struct {
+	w           http.ResponseWriter
+	contentType string
+	codec       connectCodec
+}
+
+func (res *connectStreamResponse) writeHeader(md http.Header) {
+	h := res.w.Header()
+
+	for key, values := range md {
+		h[key] = values
+	}
+
+	h.Set("Content-Type", res.contentType)
+	res.w.WriteHeader(http.StatusOK)
+}
+
+func (res *connectStreamResponse) writeMessage(msg []byte) {
+	msg, err := res.codec.responseFromProto(msg)
+	if err != nil {
+		return
+	}
+
+	res.write(envelope(0, msg))
+}
+
+func (res *connectStreamResponse) writeEnd(
+	st *status.Status,
+	trailer http.Header,
+) {
+	end := struct {
+		Error    *connectError       `json:"error,omitempty"`
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}{}
+
+	if st.Code() != codes.OK {
+		end.Error = newConnectError(st)
+	}
+
+	if len(trailer) > 0 {
+		end.Metadata = make(map[string][]string, len(trailer))
+
+		for key, values := range trailer {
+			end.Metadata[strings.ToLower(key)] = values
+		}
+	}
+
+	b, err := json.Marshal(end)
+	if err != nil {
+		b = []byte(`{"error": {"code": "internal"}}`)
+	}
+
+	res.write(envelope(flagConnectEndStream, b))
+}
+
+func (res *connectStreamResponse) write(b []byte) {
+	_, _ = res.w.Write(b)
+
+	if f, ok := res.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// setGRPCTimeout forwards the Connect-Timeout-Ms header
+// as the timeout of the grpc call.
+func setGRPCTimeout(r *http.Request) {
+	timeout := r.Header.Get("Connect-Timeout-Ms")
+	if timeout == "" {
+		return
+	}
+
+	if _, err := strconv.ParseUint(timeout, 10, 64); err == nil {
+		r.Header.Set("Grpc-Timeout", timeout+"m")
+	}
+}
+
+// connectCodec converts the messages of a method
+// between protobuf and their Connect encoding.
+type connectCodec struct {
+	input, output protoreflect.MessageType
+	json          bool
+}
+
+// newConnectCodec returns the codec of the method called at the
+// given path with the given content type.
+func newConnectCodec(path, contentType string) (connectCodec, error) {
+	// The path is formatted as /package.Service/Method.
+	name := strings.Replace(strings.TrimPrefix(path, "/"), "/", ".", 1)
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(
+		protoreflect.FullName(name),
+	)
+	if err != nil {
+		return connectCodec{}, status.Errorf(
+			codes.Unimplemented,
+			"unknown method %s",
+			name,
+		)
+	}
+
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return connectCodec{}, status.Errorf(
+			codes.Unimplemented,
+			"%s is not a method",
+			name,
+		)
+	}
+
+	input, err := protoregistry.GlobalTypes.FindMessageByName(
+		method.Input().FullName(),
+	)
+	if err != nil {
+		return connectCodec{}, status.Error(codes.Internal, err.Error())
+	}
+
+	output, err := protoregistry.GlobalTypes.FindMessageByName(
+		method.Output().FullName(),
+	)
+	if err != nil {
+		return connectCodec{}, status.Error(codes.Internal, err.Error())
+	}
+
+	return connectCodec{
+		input:  input,
+		output: output,
+		json: contentType == connectJSONContentType ||
+			contentType == connectStreamJSONContent,
+	}, nil
+}
+
+// requestToProto returns the request message marshaled as protobuf.
+func (c connectCodec) requestToProto(msg []byte) ([]byte, error) {
+	if !c.json {
+		return msg, nil
+	}
+
+	m := c.input.New().Interface()
+
+	err := protojson.Unmarshal(msg, m)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return b, nil
+}
+
+// responseFromProto returns the response message in the encoding
+// of the request.
+func (c connectCodec) responseFromProto(msg []byte) ([]byte, error) {
+	if !c.json {
+		return msg, nil
+	}
+
+	m := c.output.New().Interface()
+
+	err := proto.Unmarshal(msg, m)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("marshal response: %w", err)
+	}
+
+	return b, nil
+}
+
+// connectError represents the error of a Connect call.
+type connectError struct {
+	Code    string               `json:"code"`
+	Message string               `json:"message,omitempty"`
+	Details []connectErrorDetail `json:"details,omitempty"`
+}
+
+// connectErrorDetail represents an error detail,
+// its value is the marshaled protobuf message.
+type connectErrorDetail struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func newConnectError(st *status.Status) *connectError {
+	e := &connectError{
+		Code:    connectCode(st.Code()),
+		Message: st.Message(),
+	}
+
+	for _, d := range st.Proto().GetDetails() {
+		typeName := d.GetTypeUrl()[strings.LastIndex(d.GetTypeUrl(), "/")+1:]
+
+		e.Details = append(e.Details, connectErrorDetail{
+			Type:  typeName,
+			Value: base64.RawStdEncoding.EncodeToString(d.GetValue()),
+		})
+	}
+
+	return e
+}
+
+// connectCode returns the Connect name of the code,
+// like invalid_argument.
+func connectCode(code codes.Code) string {
+	var b strings.Builder
+
+	for i, r := range code.String() {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// connectHTTPStatus returns the HTTP status of the
+// Connect unary calls failing with the code.
+func connectHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.Canceled:
+		return 499
+
+	case codes.InvalidArgument,
+		codes.FailedPrecondition,
+		codes.OutOfRange:
+		return http.StatusBadRequest
+
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+
+	case codes.NotFound:
+		return http.StatusNotFound
+
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+
+	default:
+		return http.StatusInternalServerError
+	}
+}
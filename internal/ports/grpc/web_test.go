@@ -0,0 +1,299 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/user"
+	"google.golang.org/protobuf/proto"
+)
+
+// newWebTestServer returns a test server serving the web protocols,
+// the other requests are answered with 404 Not Found.
+func newWebTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	i := is.New(t)
+
+	repo := memory.NewUserRepository()
+
+	for n := 0; n < 3; n++ {
+		id, err := uuid.Parse(fmt.Sprintf(
+			"00000000-0000-0000-0000-%012d",
+			n+1,
+		))
+		i.NoErr(err)
+
+		i.NoErr(repo.CreateUser(
+			context.Background(),
+			user.MustNew(id, fmt.Sprintf("user%d@test.com", n)),
+		))
+	}
+
+	s, _ := newTestServer(t, app.Application{
+		Commands: app.Commands{
+			CreateUser: command.MustNewCreateUserHandler(repo),
+		},
+		Queries: app.Queries{
+			StreamUsers: query.MustNewStreamUsersHandler(repo),
+		},
+	})
+
+	ts := httptest.NewServer(s.WebProtocols(http.NotFoundHandler()))
+
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+// frame returns the message framed with the given flags.
+func frame(flags byte, msg []byte) []byte {
+	b := make([]byte, 5+len(msg))
+
+	b[0] = flags
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	copy(b[5:], msg)
+
+	return b
+}
+
+// frames returns the framed messages of the body.
+func frames(t *testing.T, body []byte) (flags []byte, msgs [][]byte) {
+	t.Helper()
+
+	for len(body) > 0 {
+		is.New(t).True(len(body) >= 5)
+
+		size := int(binary.BigEndian.Uint32(body[1:5]))
+
+		flags = append(flags, body[0])
+		msgs = append(msgs, body[5:5+size])
+
+		body = body[5+size:]
+	}
+
+	return flags, msgs
+}
+
+func postWeb(
+	t *testing.T,
+	url, contentType string,
+	body []byte,
+) (*http.Response, []byte) {
+	t.Helper()
+
+	i := is.New(t)
+
+	res, err := http.Post(url, contentType, bytes.NewReader(body))
+	i.NoErr(err)
+
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	i.NoErr(err)
+
+	return res, b
+}
+
+func TestServer_WebProtocols(t *testing.T) {
+	t.Parallel()
+
+	servicePath := func(t *testing.T) string {
+		t.Helper()
+
+		return newWebTestServer(t).URL + "/" +
+			startergrpc.GoStarter_ServiceDesc.ServiceName + "/"
+	}
+
+	t.Run("ConnectUnary", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		res, body := postWeb(
+			t,
+			servicePath(t)+"CreateUser",
+			"application/json",
+			[]byte(`{"email": "connect@test.com"}`),
+		)
+
+		i.Equal(res.StatusCode, http.StatusOK)
+		i.Equal(res.Header.Get("Content-Type"), "application/json")
+
+		var resp struct {
+			User struct {
+				ID    string `json:"id"`
+				Email string `json:"email"`
+			} `json:"user"`
+		}
+
+		i.NoErr(json.Unmarshal(body, &resp))
+		i.Equal(resp.User.Email, "connect@test.com")
+		i.True(resp.User.ID != "")
+	})
+
+	t.Run("ConnectUnaryError", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		res, body := postWeb(
+			t,
+			servicePath(t)+"CreateUser",
+			"application/json",
+			[]byte(`{"email": "invalid"}`),
+		)
+
+		i.Equal(res.StatusCode, http.StatusBadRequest)
+		i.Equal(res.Header.Get("Content-Type"), "application/json")
+
+		var connectErr struct {
+			Code    string `json:"code"`
+			Details []struct {
+				Type string `json:"type"`
+			} `json:"details"`
+		}
+
+		i.NoErr(json.Unmarshal(body, &connectErr))
+		i.Equal(connectErr.Code, "invalid_argument")
+		i.Equal(len(connectErr.Details), 1)
+		i.Equal(connectErr.Details[0].Type, "google.rpc.BadRequest")
+	})
+
+	t.Run("ConnectUnaryUnknownMethod", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		res, _ := postWeb(
+			t,
+			servicePath(t)+"Unknown",
+			"application/json",
+			[]byte(`{}`),
+		)
+
+		i.Equal(res.StatusCode, http.StatusNotImplemented)
+	})
+
+	t.Run("ConnectStream", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		res, body := postWeb(
+			t,
+			servicePath(t)+"StreamUsers",
+			"application/connect+json",
+			frame(0, []byte(`{"batchSize": 2}`)),
+		)
+
+		i.Equal(res.StatusCode, http.StatusOK)
+		i.Equal(res.Header.Get("Content-Type"), "application/connect+json")
+
+		flags, msgs := frames(t, body)
+
+		i.Equal(len(msgs), 4)
+		i.Equal(flags[3], byte(0b10))
+		i.Equal(string(msgs[3]), `{}`)
+
+		for _, msg := range msgs[:3] {
+			var u struct {
+				Email string `json:"email"`
+			}
+
+			i.NoErr(json.Unmarshal(msg, &u))
+			i.True(strings.HasSuffix(u.Email, "@test.com"))
+		}
+	})
+
+	for name, text := range map[string]bool{
+		"GRPCWeb":     false,
+		"GRPCWebText": true,
+	} {
+		text := text
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			req, err := proto.Marshal(&startergrpc.CreateUserRequest{
+				Email: "grpcweb@test.com",
+			})
+			i.NoErr(err)
+
+			contentType := "application/grpc-web+proto"
+			reqBody := frame(0, req)
+
+			if text {
+				contentType = "application/grpc-web-text"
+				reqBody = []byte(base64.StdEncoding.EncodeToString(reqBody))
+			}
+
+			res, body := postWeb(t, servicePath(t)+"CreateUser", contentType, reqBody)
+
+			i.Equal(res.StatusCode, http.StatusOK)
+
+			if text {
+				i.Equal(
+					res.Header.Get("Content-Type"),
+					"application/grpc-web-text+proto",
+				)
+
+				body = decodeChunks(t, body)
+			}
+
+			flags, msgs := frames(t, body)
+
+			i.Equal(len(msgs), 2)
+			i.Equal(flags[1], byte(0x80))
+			i.Equal(string(msgs[1]), "grpc-status: 0\r\n")
+
+			var resp startergrpc.CreateUserResponse
+
+			i.NoErr(proto.Unmarshal(msgs[0], &resp))
+			i.Equal(resp.GetUser().GetEmail(), "grpcweb@test.com")
+		})
+	}
+}
+
+// decodeChunks decodes the padded base64 chunks of
+// a gRPC-Web text response.
+func decodeChunks(t *testing.T, body []byte) []byte {
+	t.Helper()
+
+	var decoded []byte
+
+	for _, chunk := range strings.SplitAfter(string(body), "=") {
+		for len(chunk) > 0 {
+			n := len(chunk)
+			if idx := strings.IndexByte(chunk, '='); idx >= 0 {
+				n = idx + strings.Count(chunk[idx:], "=")
+			}
+
+			b, err := base64.StdEncoding.DecodeString(chunk[:n])
+			is.New(t).NoErr(err)
+
+			decoded = append(decoded, b...)
+			chunk = chunk[n:]
+		}
+	}
+
+	return decoded
+}
@@ -0,0 +1,282 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/webhooks"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/domain/webhook"
+)
+
+// errWebhooksUnavailable is returned by the webhook RPCs when the
+// application is backed by a database without webhook support.
+var errWebhooksUnavailable = status.Error(
+	codes.Unimplemented,
+	"webhooks are not available with this database driver",
+)
+
+// CreateWebhookSubscription subscribes an endpoint to events.
+func (s *Server) CreateWebhookSubscription(
+	ctx context.Context,
+	req *startergrpc.CreateWebhookSubscriptionRequest,
+) (*startergrpc.CreateWebhookSubscriptionResponse, error) {
+	if violation := checkWebhookURL(ctx, req.Url); violation != "" {
+		return nil, invalidRequestError(
+			&errdetails.BadRequest_FieldViolation{
+				Field:       "url",
+				Description: violation,
+			},
+		)
+	}
+
+	if !s.app.Webhooks {
+		return nil, errWebhooksUnavailable
+	}
+
+	secret := req.Secret
+
+	if secret == "" {
+		var err error
+
+		secret, err = webhook.GenerateSecret()
+		if err != nil {
+			return nil, fmt.Errorf("generate secret: %w", err)
+		}
+	}
+
+	newSubscriptionID := uuid.New()
+
+	err := s.app.Commands.CreateWebhookSubscription.Handle(
+		ctx,
+		command.CreateWebhookSubscription{
+			ID:         newSubscriptionID,
+			URL:        req.Url,
+			EventTypes: req.EventTypes,
+			Secret:     secret,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"create webhook subscription command: %w",
+			err,
+		)
+	}
+
+	return &startergrpc.CreateWebhookSubscriptionResponse{
+		Subscription: &startergrpc.WebhookSubscription{
+			Id:         newSubscriptionID.String(),
+			Url:        req.Url,
+			EventTypes: req.EventTypes,
+			CreatedAt:  timestamppb.Now(),
+		},
+		Secret: secret,
+	}, nil
+}
+
+// ListWebhookSubscriptions returns all the webhook subscriptions.
+func (s *Server) ListWebhookSubscriptions(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*startergrpc.ListWebhookSubscriptionsResponse, error) {
+	if !s.app.Webhooks {
+		return nil, errWebhooksUnavailable
+	}
+
+	subs, err := s.app.Queries.WebhookSubscriptions.Handle(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"webhook subscriptions query: %w",
+			err,
+		)
+	}
+
+	resSubs := make([]*startergrpc.WebhookSubscription, 0, len(subs))
+
+	for _, sub := range subs {
+		resSubs = append(resSubs, webhookSubscriptionToGRPC(sub))
+	}
+
+	return &startergrpc.ListWebhookSubscriptionsResponse{
+		Subscriptions: resSubs,
+	}, nil
+}
+
+// DeleteWebhookSubscription removes a webhook subscription.
+func (s *Server) DeleteWebhookSubscription(
+	ctx context.Context,
+	req *startergrpc.DeleteWebhookSubscriptionRequest,
+) (*emptypb.Empty, error) {
+	if !s.app.Webhooks {
+		return nil, errWebhooksUnavailable
+	}
+
+	subscriptionID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, errors.NewInvalidError("subscription id")
+	}
+
+	err = s.app.Commands.DeleteWebhookSubscription.Handle(
+		ctx,
+		command.DeleteWebhookSubscription{ID: subscriptionID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"delete webhook subscription command: %w",
+			err,
+		)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListWebhookDeliveries returns the delivery log of a
+// webhook subscription.
+func (s *Server) ListWebhookDeliveries(
+	ctx context.Context,
+	req *startergrpc.ListWebhookDeliveriesRequest,
+) (*startergrpc.ListWebhookDeliveriesResponse, error) {
+	if !s.app.Webhooks {
+		return nil, errWebhooksUnavailable
+	}
+
+	subscriptionID, err := uuid.Parse(req.SubscriptionId)
+	if err != nil {
+		return nil, errors.NewInvalidError("subscription id")
+	}
+
+	filter := webhook.DeliveryFilter{
+		SubscriptionID: subscriptionID,
+	}
+
+	if req.Status != "" {
+		status, err := webhook.ParseDeliveryStatus(req.Status)
+		if err != nil {
+			return nil, fmt.Errorf("parse status: %w", err)
+		}
+
+		filter.Status = &status
+	}
+
+	deliveries, err := s.app.Queries.WebhookDeliveries.Handle(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"webhook deliveries query: %w",
+			err,
+		)
+	}
+
+	resDeliveries := make([]*startergrpc.WebhookDelivery, 0, len(deliveries))
+
+	for _, d := range deliveries {
+		resDeliveries = append(resDeliveries, webhookDeliveryToGRPC(d))
+	}
+
+	return &startergrpc.ListWebhookDeliveriesResponse{
+		Deliveries: resDeliveries,
+	}, nil
+}
+
+// RedeliverWebhook sends a webhook delivery again.
+func (s *Server) RedeliverWebhook(
+	ctx context.Context,
+	req *startergrpc.RedeliverWebhookRequest,
+) (*emptypb.Empty, error) {
+	if !s.app.Webhooks {
+		return nil, errWebhooksUnavailable
+	}
+
+	deliveryID, err := uuid.Parse(req.DeliveryId)
+	if err != nil {
+		return nil, errors.NewInvalidError("delivery id")
+	}
+
+	err = s.app.Commands.RedeliverWebhook.Handle(
+		ctx,
+		command.RedeliverWebhook{DeliveryID: deliveryID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"redeliver webhook command: %w",
+			err,
+		)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// checkWebhookURL returns the description of the reason the endpoint
+// can't receive webhooks, empty when it can: it must be an https URL
+// whose host doesn't resolve to a loopback, link-local, private or
+// unspecified address, so subscriptions can't be used to reach the
+// services next to the server.
+func checkWebhookURL(ctx context.Context, endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return "must be an https URL"
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return "must have a resolvable host"
+	}
+
+	for _, addr := range addrs {
+		if webhooks.IsInternalIP(addr.IP) {
+			return "must not resolve to a loopback, " +
+				"link-local or private address"
+		}
+	}
+
+	return ""
+}
+
+func webhookSubscriptionToGRPC(
+	s query.WebhookSubscription,
+) *startergrpc.WebhookSubscription {
+	return &startergrpc.WebhookSubscription{
+		Id:         s.ID.String(),
+		Url:        s.URL,
+		EventTypes: s.EventTypes,
+		CreatedAt:  timestamppb.New(s.CreatedAt),
+	}
+}
+
+func webhookDeliveryToGRPC(
+	d query.WebhookDelivery,
+) *startergrpc.WebhookDelivery {
+	return &startergrpc.WebhookDelivery{
+		Id:             d.ID.String(),
+		SubscriptionId: d.SubscriptionID.String(),
+		EventId:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       int32(d.Attempts),
+		NextAttemptAt:  timestamppb.New(d.NextAttemptAt),
+		LastStatusCode: int32(d.LastStatusCode),
+		LastError:      d.LastError,
+		CreatedAt:      timestamppb.New(d.CreatedAt),
+		DeliveredAt:    timestampOrNil(d.DeliveredAt),
+	}
+}
+
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,87 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestServer_WebhooksUnavailable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+
+	// The application of the memory and SQLite drivers
+	// doesn't have webhooks.
+	_, conn := newTestServer(t, app.Application{})
+
+	client := startergrpc.NewGoStarterClient(conn)
+
+	tests := map[string]func() error{
+		"CreateWebhookSubscription": func() error {
+			_, err := client.CreateWebhookSubscription(
+				ctx,
+				&startergrpc.CreateWebhookSubscriptionRequest{
+					Url:        "https://93.184.216.34/webhooks",
+					EventTypes: []string{"user.created"},
+				},
+			)
+
+			return err
+		},
+		"ListWebhookSubscriptions": func() error {
+			_, err := client.ListWebhookSubscriptions(ctx, &emptypb.Empty{})
+
+			return err
+		},
+		"DeleteWebhookSubscription": func() error {
+			_, err := client.DeleteWebhookSubscription(
+				ctx,
+				&startergrpc.DeleteWebhookSubscriptionRequest{
+					Id: uuid.New().String(),
+				},
+			)
+
+			return err
+		},
+		"ListWebhookDeliveries": func() error {
+			_, err := client.ListWebhookDeliveries(
+				ctx,
+				&startergrpc.ListWebhookDeliveriesRequest{
+					SubscriptionId: uuid.New().String(),
+				},
+			)
+
+			return err
+		},
+		"RedeliverWebhook": func() error {
+			_, err := client.RedeliverWebhook(
+				ctx,
+				&startergrpc.RedeliverWebhookRequest{
+					DeliveryId: uuid.New().String(),
+				},
+			)
+
+			return err
+		},
+	}
+
+	for name, call := range tests {
+		call := call
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			i.Equal(codes.Unimplemented, status.Code(call()))
+		})
+	}
+}
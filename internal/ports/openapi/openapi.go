@@ -0,0 +1,148 @@
+package openapi
+
+import (
+	// Embeds the docs UI.
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// The paths the spec and the docs are served at.
+const (
+	SpecPath   = "/openapi.json"
+	SpecV3Path = "/openapi.v3.json"
+	DocsPath   = "/docs"
+)
+
+//go:embed ui/index.html
+var uiPage []byte
+
+// Docs serves an OpenAPI v2 spec, its OpenAPI 3 conversion and
+// the docs UI. The specs are served with the host the request
+// was sent to.
+type Docs struct {
+	v2 map[string]json.RawMessage
+	v3 *openapi3.T
+}
+
+// NewDocs returns the Docs of the given OpenAPI v2 spec.
+func NewDocs(spec []byte) (*Docs, error) {
+	var v2 map[string]json.RawMessage
+
+	err := json.Unmarshal(spec, &v2)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal spec: %w", err)
+	}
+
+	var doc openapi2.T
+
+	err = json.Unmarshal(spec, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal openapi v2 spec: %w", err)
+	}
+
+	v3, err := openapi2conv.ToV3(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("convert to openapi 3: %w", err)
+	}
+
+	return &Docs{
+		v2: v2,
+		v3: v3,
+	}, nil
+}
+
+// V2 returns the OpenAPI v2 spec served from the given host
+// with the given scheme.
+func (d *Docs) V2(host, scheme string) ([]byte, error) {
+	// The fields of the generated spec are kept as they are,
+	// a copy is patched to not share the map.
+	spec := make(map[string]any, len(d.v2)+3)
+
+	for key, value := range d.v2 {
+		spec[key] = value
+	}
+
+	spec["host"] = host
+	spec["basePath"] = "/"
+	spec["schemes"] = []string{scheme}
+
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal openapi v2 spec: %w", err)
+	}
+
+	return b, nil
+}
+
+// V3 returns the OpenAPI 3 spec served from the given host
+// with the given scheme.
+func (d *Docs) V3(host, scheme string) ([]byte, error) {
+	spec := *d.v3
+
+	spec.Servers = openapi3.Servers{
+		{URL: scheme + "://" + host},
+	}
+
+	b, err := json.Marshal(&spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal openapi 3 spec: %w", err)
+	}
+
+	return b, nil
+}
+
+// ServeV2 writes the OpenAPI v2 spec.
+func (d *Docs) ServeV2(w http.ResponseWriter, r *http.Request) {
+	serveSpec(w, r, d.V2)
+}
+
+// ServeV3 writes the OpenAPI 3 spec.
+func (d *Docs) ServeV3(w http.ResponseWriter, r *http.Request) {
+	serveSpec(w, r, d.V3)
+}
+
+// ServeUI writes the docs UI, a single page browsing
+// the spec served at SpecPath.
+func (*Docs) ServeUI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	_, _ = w.Write(uiPage)
+}
+
+func serveSpec(
+	w http.ResponseWriter,
+	r *http.Request,
+	spec func(host, scheme string) ([]byte, error),
+) {
+	b, err := spec(r.Host, scheme(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, _ = w.Write(b)
+}
+
+// scheme returns the scheme the request was sent with,
+// as forwarded by a proxy terminating TLS.
+func scheme(r *http.Request) string {
+	switch proto := r.Header.Get("X-Forwarded-Proto"); proto {
+	case "http", "https":
+		return proto
+	}
+
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
@@ -0,0 +1,154 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/apigrpc/v1"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/openapi"
+)
+
+// refs returns the $ref values found in the JSON value.
+func refs(v any) []string {
+	var found []string
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				found = append(found, ref)
+
+				continue
+			}
+
+			found = append(found, refs(value)...)
+		}
+
+	case []any:
+		for _, value := range v {
+			found = append(found, refs(value)...)
+		}
+	}
+
+	return found
+}
+
+func TestSpec_Refs(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	var spec struct {
+		Definitions map[string]any `json:"definitions"`
+	}
+
+	i.NoErr(json.Unmarshal(startergrpc.OpenAPI, &spec))
+
+	var doc any
+
+	i.NoErr(json.Unmarshal(startergrpc.OpenAPI, &doc))
+
+	const prefix = "#/definitions/"
+
+	for _, ref := range refs(doc) {
+		if !strings.HasPrefix(ref, prefix) {
+			t.Errorf("ref %q is not a definition", ref)
+
+			continue
+		}
+
+		if _, ok := spec.Definitions[strings.TrimPrefix(ref, prefix)]; !ok {
+			t.Errorf("ref %q is not defined", ref)
+		}
+	}
+}
+
+func TestDocs(t *testing.T) {
+	t.Parallel()
+
+	docs, err := openapi.NewDocs(startergrpc.OpenAPI)
+	is.New(t).NoErr(err)
+
+	serve := func(
+		handler http.HandlerFunc,
+		path string,
+	) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "api.example.com"
+		req.Header.Set("X-Forwarded-Proto", "https")
+
+		rec := httptest.NewRecorder()
+
+		handler(rec, req)
+
+		return rec
+	}
+
+	t.Run("V2", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		rec := serve(docs.ServeV2, openapi.SpecPath)
+
+		i.Equal(http.StatusOK, rec.Code)
+		i.Equal("application/json", rec.Header().Get("Content-Type"))
+
+		var spec struct {
+			Swagger  string         `json:"swagger"`
+			Host     string         `json:"host"`
+			BasePath string         `json:"basePath"`
+			Schemes  []string       `json:"schemes"`
+			Paths    map[string]any `json:"paths"`
+		}
+
+		i.NoErr(json.Unmarshal(rec.Body.Bytes(), &spec))
+
+		i.Equal("2.0", spec.Swagger)
+		i.Equal("api.example.com", spec.Host)
+		i.Equal("/", spec.BasePath)
+		i.Equal([]string{"https"}, spec.Schemes)
+		i.True(spec.Paths["/v1/users"] != nil)
+	})
+
+	t.Run("V3", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		rec := serve(docs.ServeV3, openapi.SpecV3Path)
+
+		i.Equal(http.StatusOK, rec.Code)
+
+		var spec struct {
+			OpenAPI string `json:"openapi"`
+			Servers []struct {
+				URL string `json:"url"`
+			} `json:"servers"`
+			Paths map[string]any `json:"paths"`
+		}
+
+		i.NoErr(json.Unmarshal(rec.Body.Bytes(), &spec))
+
+		i.True(strings.HasPrefix(spec.OpenAPI, "3."))
+		i.Equal(1, len(spec.Servers))
+		i.Equal("https://api.example.com", spec.Servers[0].URL)
+		i.True(spec.Paths["/v1/users"] != nil)
+	})
+
+	t.Run("UI", func(t *testing.T) {
+		t.Parallel()
+
+		i := is.New(t)
+
+		rec := serve(docs.ServeUI, openapi.DocsPath)
+
+		i.Equal(http.StatusOK, rec.Code)
+		i.Equal("text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		i.True(strings.Contains(rec.Body.String(), "openapi.json"))
+	})
+}
@@ -0,0 +1,3 @@
+// Package openapi serves the OpenAPI spec of the API, as generated
+// and converted to OpenAPI 3, and the docs browsing it.
+package openapi
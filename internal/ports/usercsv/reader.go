@@ -0,0 +1,123 @@
+package usercsv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// Names of the columns read from the CSV files.
+const (
+	ColumnID    = "id"
+	ColumnEmail = "email"
+)
+
+// Reader reads the users to import from a CSV file.
+//
+// The first record is the header, it names an email column and,
+// optionally, an id column, in any order. The other columns
+// are ignored.
+type Reader struct {
+	csv      *csv.Reader
+	idCol    int
+	emailCol int
+}
+
+// NewReader reads the header of the CSV file and returns a Reader
+// for the records following it.
+func NewReader(r io.Reader) (*Reader, error) {
+	cr := csv.NewReader(r)
+
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.NewInvalidError("csv: empty file")
+	}
+
+	if err != nil {
+		return nil, errors.NewInvalidError("csv header: " + err.Error())
+	}
+
+	reader := &Reader{
+		csv:      cr,
+		idCol:    -1,
+		emailCol: -1,
+	}
+
+	for i, name := range header {
+		// Spreadsheets may prefix the file with a byte order mark.
+		name = strings.TrimPrefix(name, "\ufeff")
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case ColumnID:
+			reader.idCol = i
+
+		case ColumnEmail:
+			reader.emailCol = i
+		}
+	}
+
+	if reader.emailCol < 0 {
+		return nil, errors.NewInvalidError("csv header: no email column")
+	}
+
+	return reader, nil
+}
+
+// Read returns the next user, io.EOF when there are no more users.
+func (r *Reader) Read() (command.ImportUserRow, error) {
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return command.ImportUserRow{}, io.EOF
+	}
+
+	if err != nil {
+		return command.ImportUserRow{}, errors.NewInvalidError(
+			"csv: " + err.Error(),
+		)
+	}
+
+	return command.ImportUserRow{
+		ID:    field(record, r.idCol),
+		Email: field(record, r.emailCol),
+	}, nil
+}
+
+// ReadBatch returns up to n users, io.EOF when there are no more users.
+func (r *Reader) ReadBatch(n int) ([]command.ImportUserRow, error) {
+	rows := make([]command.ImportUserRow, 0, n)
+
+	for len(rows) < n {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read row: %w", err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, io.EOF
+	}
+
+	return rows, nil
+}
+
+func field(record []string, col int) string {
+	if col < 0 || col >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[col])
+}
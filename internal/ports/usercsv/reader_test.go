@@ -0,0 +1,75 @@
+package usercsv_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/ports/usercsv"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		file string
+		rows []command.ImportUserRow
+	}{
+		"EmailOnly": {
+			file: "email\na@test.com\nb@test.com\n",
+			rows: []command.ImportUserRow{
+				{Email: "a@test.com"},
+				{Email: "b@test.com"},
+			},
+		},
+		"AnyColumnOrder": {
+			file: "\ufeffName, Email ,ID\n" +
+				"A, a@test.com ,00000000-0000-0000-0000-000000000001\n" +
+				"B,b@test.com\n",
+			rows: []command.ImportUserRow{
+				{
+					ID:    "00000000-0000-0000-0000-000000000001",
+					Email: "a@test.com",
+				},
+				{Email: "b@test.com"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			i := is.New(t)
+
+			r, err := usercsv.NewReader(strings.NewReader(test.file))
+			i.NoErr(err)
+
+			rows, err := r.ReadBatch(1)
+			i.NoErr(err)
+			i.Equal(test.rows[:1], rows)
+
+			rows, err = r.ReadBatch(10)
+			i.NoErr(err)
+			i.Equal(test.rows[1:], rows)
+
+			_, err = r.ReadBatch(10)
+			i.Equal(io.EOF, err)
+		})
+	}
+}
+
+func TestNewReaderInvalidHeader(t *testing.T) {
+	t.Parallel()
+
+	i := is.New(t)
+
+	for _, file := range []string{"", "id,name\n"} {
+		_, err := usercsv.NewReader(strings.NewReader(file))
+		i.True(err != nil)
+	}
+}
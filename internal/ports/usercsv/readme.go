@@ -0,0 +1,3 @@
+// Package usercsv reads the users to import from CSV files
+// and writes the import reports as CSV.
+package usercsv
@@ -0,0 +1,111 @@
+package usercsv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// ReportRow represents the outcome of importing a user,
+// as written in a report.
+type ReportRow struct {
+	// Row is the position of the user in the import, starting from 1.
+	Row    int
+	ID     string
+	Email  string
+	Status string
+	Error  string
+}
+
+// NewReportRow returns the report row of the result of
+// the user at the given position.
+func NewReportRow(row int, res command.ImportUserResult) ReportRow {
+	reportRow := ReportRow{
+		Row:    row,
+		Email:  res.Email,
+		Status: string(res.Status),
+		Error:  ErrorMessage(res.Err),
+	}
+
+	if !res.ID.IsZero() {
+		reportRow.ID = res.ID.String()
+	}
+
+	return reportRow
+}
+
+// ErrorMessage returns the reason a user failed to be imported,
+// as reported to the clients.
+func ErrorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var appErr *errors.Error
+
+	if !errors.As(err, &appErr) {
+		return "internal error"
+	}
+
+	return appErr.Type().String() + ": " + appErr.Message()
+}
+
+// ReportWriter writes import reports as CSV.
+type ReportWriter struct {
+	csv         *csv.Writer
+	wroteHeader bool
+}
+
+// NewReportWriter returns a ReportWriter writing to w.
+func NewReportWriter(w io.Writer) *ReportWriter {
+	return &ReportWriter{
+		csv: csv.NewWriter(w),
+	}
+}
+
+// Write writes the row, preceded by the header on the first call.
+func (w *ReportWriter) Write(row ReportRow) error {
+	if !w.wroteHeader {
+		err := w.csv.Write([]string{
+			"row",
+			ColumnID,
+			ColumnEmail,
+			"status",
+			"error",
+		})
+		if err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+
+		w.wroteHeader = true
+	}
+
+	err := w.csv.Write([]string{
+		strconv.Itoa(row.Row),
+		row.ID,
+		row.Email,
+		row.Status,
+		row.Error,
+	})
+	if err != nil {
+		return fmt.Errorf("write row: %w", err)
+	}
+
+	return nil
+}
+
+// Flush writes the buffered rows to the underlying writer.
+func (w *ReportWriter) Flush() error {
+	w.csv.Flush()
+
+	err := w.csv.Error()
+	if err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+
+	return nil
+}
@@ -2,8 +2,16 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/cache"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/jobs"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/memory"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/sqlite"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/webhooks"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
 	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
@@ -12,45 +20,302 @@ import (
 	"gorm.io/gorm"
 )
 
+// Values accepted by the Storage config.
+const (
+	storageDatabase = "database"
+	storageMemory   = "memory"
+)
+
 // NewApplication returns a production application.
+//
+// It also starts the outbox relay which publishes the domain
+// events and the worker which sends the webhook deliveries,
+// both run until cleanup is called.
 func NewApplication(
 	ctx context.Context,
 	logger *zap.Logger,
 	cfg *config.Config,
+	opts ...Option,
 ) (
 	application app.Application,
 	cleanup func() error,
 ) {
+	switch cfg.Storage {
+	case storageDatabase, "":
+
+	case storageMemory:
+		logger.Warn("using in memory storage, data is lost on exit")
+
+		return NewMemoryApplication(), func() error {
+			return nil
+		}
+
+	default:
+		logger.Fatal("unknown storage", zap.String("storage", cfg.Storage))
+	}
+
+	switch cfg.DB.Driver {
+	case psql.DriverName, "":
+
+	case sqlite.DriverName:
+		return newSQLiteApplication(ctx, logger, cfg, opts...)
+
+	default:
+		logger.Fatal("unknown database driver", zap.String("driver", cfg.DB.Driver))
+	}
+
 	db, err := psql.Connect(cfg)
 	if err != nil {
 		logger.Fatal("connecting to database: %+v", zap.Error(err))
 	}
 
+	queryLogger, err := newQueryLogger(logger, cfg)
+	if err != nil {
+		logger.Fatal("new query logger", zap.Error(err))
+	}
+
+	db.Logger = queryLogger
+
+	options := newOptions(opts)
+
+	addPool(logger, options.pools, "primary", db)
+
+	replicaDBs, err := psql.ConnectReplicas(cfg)
+	if err != nil {
+		logger.Fatal("connecting to replicas", zap.Error(err))
+	}
+
+	for i, replicaDB := range replicaDBs {
+		replicaDB.Logger = queryLogger
+
+		addPool(
+			logger,
+			options.pools,
+			"replica "+cfg.DB.REPLICAS[i],
+			replicaDB,
+		)
+	}
+
+	replicas := psql.NewReplicas(
+		logger,
+		db,
+		replicaDBs,
+		psql.WithMaxLag(cfg.DB.MaxReplicaLag),
+		psql.WithCheckInterval(cfg.DB.ReplicaCheckInterval),
+		psql.WithReadYourWritesWindow(cfg.DB.ReadYourWritesWindow),
+	)
+
+	publisher, err := newEventPublisher(cfg)
+	if err != nil {
+		logger.Fatal("new event publisher", zap.Error(err))
+	}
+
+	cacheBackend, err := newCacheBackend(cfg)
+	if err != nil {
+		logger.Fatal("new cache backend", zap.Error(err))
+	}
+
+	webhookRepo := psql.NewWebhookRepository(db)
+
+	publishers := outbox.MultiPublisher{
+		publisher,
+		webhooks.NewDispatcher(webhookRepo),
+	}
+
+	if cacheBackend != nil {
+		publishers = append(
+			publishers,
+			cache.NewUserInvalidator(logger, cacheBackend),
+		)
+	}
+
+	relay := outbox.NewRelay(
+		logger,
+		psql.NewOutboxStore(db),
+		publishers,
+		outbox.WithPollInterval(cfg.OUTBOX.PollInterval),
+		outbox.WithBatchSize(cfg.OUTBOX.BatchSize),
+	)
+
+	webhookWorker := webhooks.NewWorker(
+		logger,
+		webhookRepo,
+		webhooks.WithTimeout(cfg.WEBHOOKS.Timeout),
+		webhooks.WithPollInterval(cfg.WEBHOOKS.PollInterval),
+		webhooks.WithBatchSize(cfg.WEBHOOKS.BatchSize),
+		webhooks.WithMaxAttempts(cfg.WEBHOOKS.MaxAttempts),
+		webhooks.WithBackoff(cfg.WEBHOOKS.MinBackoff, cfg.WEBHOOKS.MaxBackoff),
+	)
+
+	workersCtx, cancelWorkers := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+
+	const workers = 3
+
+	wg.Add(workers)
+
+	go func() {
+		defer wg.Done()
+
+		relay.Run(workersCtx)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		replicas.Run(workersCtx)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		webhookWorker.Run(workersCtx)
+	}()
+
 	return bootstrap(
 			ctx,
 			logger,
 			cfg,
 			db,
+			jobs.NewRegistry(),
+			cacheBackend,
+			psql.WithReplicas(replicas),
 		), func() error {
+			cancelWorkers()
+			wg.Wait()
+
 			return nil
 		}
 }
 
+// NewWorker returns the worker running the background jobs.
+//
+// It runs separately from the application so it can be
+// scaled independently.
+func NewWorker(
+	ctx context.Context,
+	logger *zap.Logger,
+	cfg *config.Config,
+) (
+	worker *jobs.Worker,
+	cleanup func() error,
+) {
+	db, err := psql.Connect(cfg)
+	if err != nil {
+		logger.Fatal("connecting to database: %+v", zap.Error(err))
+	}
+
+	queryLogger, err := newQueryLogger(logger, cfg)
+	if err != nil {
+		logger.Fatal("new query logger", zap.Error(err))
+	}
+
+	db.Logger = queryLogger
+
+	registry := jobs.NewRegistry()
+
+	_ = bootstrap(ctx, logger, cfg, db, registry, nil)
+
+	return jobs.NewWorker(
+			logger,
+			psql.NewJobStore(db),
+			psql.NewAdvisoryLocker(db),
+			registry,
+			jobs.WithConcurrency(cfg.JOBS.Concurrency),
+			jobs.WithPollInterval(cfg.JOBS.PollInterval),
+			jobs.WithLease(cfg.JOBS.Lease),
+		), func() error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return fmt.Errorf("retrieve underlying db: %w", err)
+			}
+
+			return sqlDB.Close()
+		}
+}
+
 func bootstrap(
 	_ context.Context,
-	_ *zap.Logger,
-	_ *config.Config,
+	logger *zap.Logger,
+	cfg *config.Config,
 	db *gorm.DB,
+	jobRegistry *jobs.Registry,
+	cacheBackend cache.Backend,
+	repoOpts ...psql.RepositoryOption,
 ) app.Application {
-	userRepo := psql.NewUserRepository(db)
+	var (
+		userRepo cache.UserRepository = psql.NewUserRepository(db, repoOpts...)
+		uow      command.UnitOfWork   = psql.NewUnitOfWork(
+			db,
+			psql.WithRepositoryOptions(repoOpts...),
+		)
+	)
+
+	if cacheBackend != nil {
+		userRepo = cache.NewUsers(
+			logger,
+			cacheBackend,
+			userRepo,
+			cache.WithTTL(cfg.CACHE.TTL),
+		)
+		uow = cache.NewUnitOfWork(logger, cacheBackend, uow)
+	}
+
+	webhookRepo := psql.NewWebhookRepository(db, repoOpts...)
+
+	registerJobs(jobRegistry, logger, cfg, db)
+
+	return app.Application{
+		Commands: app.Commands{
+			CreateUser:      command.MustNewCreateUserHandler(userRepo),
+			ChangeUserEmail: command.MustNewChangeUserEmailHandler(uow),
+			DeleteUser:      command.MustNewDeleteUserHandler(uow),
+			ImportUsers:     command.MustNewImportUsersHandler(userRepo),
+
+			CreateWebhookSubscription: command.
+				MustNewCreateWebhookSubscriptionHandler(webhookRepo),
+			DeleteWebhookSubscription: command.
+				MustNewDeleteWebhookSubscriptionHandler(webhookRepo),
+			RedeliverWebhook: command.
+				MustNewRedeliverWebhookHandler(webhookRepo),
+		},
+		Queries: app.Queries{
+			FindUsers:   query.MustNewFindUsersHandler(userRepo),
+			UserByID:    query.MustNewUserByIDHandler(userRepo),
+			StreamUsers: query.MustNewStreamUsersHandler(userRepo),
+
+			WebhookSubscriptions: query.
+				MustNewWebhookSubscriptionsHandler(webhookRepo),
+			WebhookDeliveries: query.
+				MustNewWebhookDeliveriesHandler(webhookRepo),
+		},
+		Webhooks: true,
+	}
+}
+
+// NewMemoryApplication returns an application that keeps
+// its data in memory.
+//
+// Only the user features are available, the latest domain events
+// are kept in memory and never published.
+func NewMemoryApplication() app.Application {
+	var (
+		userRepo = memory.NewUserRepository()
+		uow      = memory.NewUnitOfWork(userRepo)
+	)
 
 	return app.Application{
 		Commands: app.Commands{
-			CreateUser: command.MustNewCreateUserHandler(userRepo),
+			CreateUser:      command.MustNewCreateUserHandler(userRepo),
+			ChangeUserEmail: command.MustNewChangeUserEmailHandler(uow),
+			DeleteUser:      command.MustNewDeleteUserHandler(uow),
+			ImportUsers:     command.MustNewImportUsersHandler(userRepo),
 		},
 		Queries: app.Queries{
-			FindUsers: query.MustNewFindUsersHandler(userRepo),
-			UserByID:  query.MustNewUserByIDHandler(userRepo),
+			FindUsers:   query.MustNewFindUsersHandler(userRepo),
+			UserByID:    query.MustNewUserByIDHandler(userRepo),
+			StreamUsers: query.MustNewStreamUsersHandler(userRepo),
 		},
 	}
 }
@@ -62,5 +327,5 @@ func NewTestApplication(
 	cfg *config.Config,
 	db *gorm.DB,
 ) app.Application {
-	return bootstrap(ctx, logger, cfg, db)
+	return bootstrap(ctx, logger, cfg, db, jobs.NewRegistry(), nil)
 }
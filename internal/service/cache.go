@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/go-redis/redis"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/cache"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// Values accepted by the CACHE.BACKEND config.
+const (
+	cacheBackendNone   = "none"
+	cacheBackendMemory = "memory"
+	cacheBackendRedis  = "redis"
+)
+
+// newCacheBackend returns the cache.Backend selected in config,
+// nil when caching is disabled.
+func newCacheBackend(cfg *config.Config) (cache.Backend, error) {
+	switch cfg.CACHE.Backend {
+	case cacheBackendNone, "":
+		return nil, nil
+
+	case cacheBackendMemory:
+		const defaultSize = 10000
+
+		size := cfg.CACHE.Size
+		if size <= 0 {
+			size = defaultSize
+		}
+
+		return cache.NewLRU(size), nil
+
+	case cacheBackendRedis:
+		if cfg.CACHE.RedisAddress == "" {
+			return nil, errors.NewInvalidError("empty cache redis address")
+		}
+
+		return cache.NewRedis(redis.NewClient(&redis.Options{
+			Addr: cfg.CACHE.RedisAddress,
+		})), nil
+
+	default:
+		return nil, errors.NewInvalidError(
+			fmt.Sprintf("unknown cache backend %q", cfg.CACHE.Backend),
+		)
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// Values accepted by the OUTBOX.PUBLISHER config.
+const (
+	eventPublisherMemory  = "memory"
+	eventPublisherWebhook = "webhook"
+	eventPublisherBroker  = "broker"
+)
+
+// newEventPublisher returns the outbox.EventPublisher selected in config.
+func newEventPublisher(cfg *config.Config) (outbox.EventPublisher, error) {
+	switch cfg.OUTBOX.Publisher {
+	case eventPublisherMemory:
+		return outbox.NewMemoryPublisher(), nil
+
+	case eventPublisherWebhook:
+		if cfg.OUTBOX.WebhookURL == "" {
+			return nil, errors.NewInvalidError("empty outbox webhook url")
+		}
+
+		return outbox.NewWebhookPublisher(
+			cfg.OUTBOX.WebhookURL,
+			cfg.OUTBOX.WebhookTimeout,
+		), nil
+
+	// The local broker stands in for NATS/Kafka until
+	// a real broker is wired in.
+	case eventPublisherBroker, "":
+		return outbox.NewBrokerPublisher(
+			outbox.NewLocalBroker(),
+			cfg.OUTBOX.TopicPrefix,
+		), nil
+
+	default:
+		return nil, errors.NewInvalidError(
+			fmt.Sprintf("unknown outbox publisher %q", cfg.OUTBOX.Publisher),
+		)
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/idempotency"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/sqlite"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"go.uber.org/zap"
+)
+
+// NewIdempotencyStore returns the idempotency.Store matching the
+// storage of the application.
+//
+// The keys are kept in PostgreSQL, in their own connection pool,
+// and in memory for the other storages.
+func NewIdempotencyStore(
+	logger *zap.Logger,
+	cfg *config.Config,
+	opts ...Option,
+) (
+	store idempotency.Store,
+	cleanup func() error,
+	err error,
+) {
+	noCleanup := func() error {
+		return nil
+	}
+
+	if cfg.Storage == storageMemory {
+		return idempotency.NewMemory(), noCleanup, nil
+	}
+
+	if cfg.DB.Driver == sqlite.DriverName {
+		logger.Warn("sqlite storage, idempotency keys kept in memory")
+
+		return idempotency.NewMemory(), noCleanup, nil
+	}
+
+	queryLogger, err := newQueryLogger(logger, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new query logger: %w", err)
+	}
+
+	db, err := psql.Connect(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
+	}
+
+	db.Logger = queryLogger
+
+	addPool(logger, newOptions(opts).pools, "idempotency", db)
+
+	return psql.NewIdempotencyStore(db), func() error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("retrieve underlying db: %w", err)
+		}
+
+		return sqlDB.Close()
+	}, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/jobs"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// pruneJobs removes the jobs finished before the retention period.
+type pruneJobs struct {
+	Retention time.Duration `json:"retention"`
+}
+
+// Kind satisfies the jobs.Args interface.
+func (pruneJobs) Kind() string {
+	return "jobs.prune"
+}
+
+// pruneOutbox removes the outbox messages published
+// before the retention period.
+type pruneOutbox struct {
+	Retention time.Duration `json:"retention"`
+}
+
+// Kind satisfies the jobs.Args interface.
+func (pruneOutbox) Kind() string {
+	return "outbox.prune"
+}
+
+// pruneIdempotencyKeys removes the expired idempotency keys.
+type pruneIdempotencyKeys struct{}
+
+// Kind satisfies the jobs.Args interface.
+func (pruneIdempotencyKeys) Kind() string {
+	return "idempotency.prune"
+}
+
+// registerJobs registers the job handlers and the periodic jobs.
+func registerJobs(
+	registry *jobs.Registry,
+	logger *zap.Logger,
+	cfg *config.Config,
+	db *gorm.DB,
+) {
+	var (
+		jobStore         = psql.NewJobStore(db)
+		outboxStore      = psql.NewOutboxStore(db)
+		idempotencyStore = psql.NewIdempotencyStore(db)
+	)
+
+	jobs.Register(registry, func(ctx context.Context, args pruneJobs) error {
+		n, err := jobStore.Prune(ctx, time.Now().Add(-args.Retention))
+		if err != nil {
+			return fmt.Errorf("prune jobs: %w", err)
+		}
+
+		logger.Info("pruned jobs", zap.Int64("count", n))
+
+		return nil
+	})
+
+	jobs.Register(registry, func(ctx context.Context, args pruneOutbox) error {
+		n, err := outboxStore.Prune(ctx, time.Now().Add(-args.Retention))
+		if err != nil {
+			return fmt.Errorf("prune outbox: %w", err)
+		}
+
+		logger.Info("pruned outbox messages", zap.Int64("count", n))
+
+		return nil
+	})
+
+	jobs.Register(
+		registry,
+		func(ctx context.Context, _ pruneIdempotencyKeys) error {
+			n, err := idempotencyStore.Prune(ctx, time.Now())
+			if err != nil {
+				return fmt.Errorf("prune idempotency keys: %w", err)
+			}
+
+			logger.Info("pruned idempotency keys", zap.Int64("count", n))
+
+			return nil
+		},
+	)
+
+	if cfg.JOBS.PruneInterval > 0 {
+		registry.Periodic(
+			"idempotency.prune",
+			jobs.Every(cfg.JOBS.PruneInterval),
+			pruneIdempotencyKeys{},
+		)
+	}
+
+	if cfg.JOBS.Retention > 0 && cfg.JOBS.PruneInterval > 0 {
+		schedule := jobs.Every(cfg.JOBS.PruneInterval)
+
+		registry.Periodic(
+			"jobs.prune",
+			schedule,
+			pruneJobs{Retention: cfg.JOBS.Retention},
+		)
+
+		registry.Periodic(
+			"outbox.prune",
+			schedule,
+			pruneOutbox{Retention: cfg.JOBS.Retention},
+		)
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/psql"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"go.uber.org/zap"
+)
+
+// newQueryLogger returns the psql.Logger configured
+// in the LOG.SQL config.
+func newQueryLogger(
+	logger *zap.Logger,
+	cfg *config.Config,
+) (*psql.Logger, error) {
+	opts := []psql.LoggerOption{
+		psql.WithSlowThreshold(cfg.LOG.SQL.SlowThreshold),
+	}
+
+	if cfg.LOG.SQL.Level != "" {
+		level, err := psql.ParseLogLevel(cfg.LOG.SQL.Level)
+		if err != nil {
+			return nil, fmt.Errorf("parse sql log level: %w", err)
+		}
+
+		opts = append(opts, psql.WithLogLevel(level))
+	}
+
+	if cfg.LOG.SQL.Params {
+		opts = append(opts, psql.WithParams())
+	}
+
+	return psql.NewLogger(logger, opts...), nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"database/sql"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Pools collects the database connection pools opened by the
+// service to report their statistics.
+type Pools struct {
+	mu  sync.Mutex
+	dbs map[string]*sql.DB
+}
+
+// NewPools returns empty Pools, filled by the constructors
+// given WithPools.
+func NewPools() *Pools {
+	return &Pools{
+		mu:  sync.Mutex{},
+		dbs: make(map[string]*sql.DB),
+	}
+}
+
+// Stats returns the statistics of every pool by name.
+func (p *Pools) Stats() map[string]sql.DBStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]sql.DBStats, len(p.dbs))
+
+	for name, db := range p.dbs {
+		stats[name] = db.Stats()
+	}
+
+	return stats
+}
+
+// add adds the pool of db, nothing is done on nil Pools.
+func (p *Pools) add(name string, db *sql.DB) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.dbs[name] = db
+}
+
+// addPool adds the pool of the gorm db to pools.
+func addPool(logger *zap.Logger, pools *Pools, name string, db *gorm.DB) {
+	if pools == nil {
+		return
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Error(
+			"retrieve underlying db",
+			zap.String("pool", name),
+			zap.Error(err),
+		)
+
+		return
+	}
+
+	pools.add(name, sqlDB)
+}
+
+type options struct {
+	pools *Pools
+}
+
+// Option configures the constructors of the service.
+type Option interface {
+	apply(*options)
+}
+
+type funcOption struct {
+	f func(*options)
+}
+
+func (f *funcOption) apply(o *options) {
+	f.f(o)
+}
+
+func newFuncOption(f func(*options)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+// WithPools adds the database connection pools opened
+// to the given Pools.
+func WithPools(pools *Pools) Option {
+	return newFuncOption(func(o *options) {
+		o.pools = pools
+	})
+}
+
+func newOptions(opts []Option) options {
+	var o options
+
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	return o
+}
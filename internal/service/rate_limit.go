@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/go-redis/redis"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/ratelimit"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/errors"
+)
+
+// Values accepted by the RATE_LIMIT.STORE config.
+const (
+	rateLimitStoreNone   = "none"
+	rateLimitStoreMemory = "memory"
+	rateLimitStoreRedis  = "redis"
+)
+
+// NewRateLimitStore returns the ratelimit.Store selected in config,
+// nil when the rate limiting is disabled.
+func NewRateLimitStore(cfg *config.Config) (ratelimit.Store, error) {
+	switch cfg.RATE_LIMIT.Store {
+	case rateLimitStoreNone, "":
+		return nil, nil
+
+	case rateLimitStoreMemory:
+		return ratelimit.NewMemory(), nil
+
+	case rateLimitStoreRedis:
+		if cfg.RATE_LIMIT.RedisAddress == "" {
+			return nil, errors.NewInvalidError(
+				"empty rate limit redis address",
+			)
+		}
+
+		return ratelimit.NewRedis(redis.NewClient(&redis.Options{
+			Addr: cfg.RATE_LIMIT.RedisAddress,
+		})), nil
+
+	default:
+		return nil, errors.NewInvalidError(
+			fmt.Sprintf("unknown rate limit store %q", cfg.RATE_LIMIT.Store),
+		)
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/outbox"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/adapters/sqlite"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/command"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/app/query"
+	"github.com/purposeinplay/go-starter-grpc-gateway/internal/common/config"
+	"go.uber.org/zap"
+)
+
+// newSQLiteApplication returns an application backed by SQLite.
+//
+// Only the user features are available, the domain events are
+// published by the outbox relay until cleanup is called.
+func newSQLiteApplication(
+	ctx context.Context,
+	logger *zap.Logger,
+	cfg *config.Config,
+	opts ...Option,
+) (
+	application app.Application,
+	cleanup func() error,
+) {
+	db, err := sqlite.Connect(cfg)
+	if err != nil {
+		logger.Fatal("connecting to database", zap.Error(err))
+	}
+
+	newOptions(opts).pools.add("sqlite", db)
+
+	publisher, err := newEventPublisher(cfg)
+	if err != nil {
+		logger.Fatal("new event publisher", zap.Error(err))
+	}
+
+	relay := outbox.NewRelay(
+		logger,
+		sqlite.NewOutboxStore(db),
+		publisher,
+		outbox.WithPollInterval(cfg.OUTBOX.PollInterval),
+		outbox.WithBatchSize(cfg.OUTBOX.BatchSize),
+	)
+
+	relayCtx, cancelRelay := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		relay.Run(relayCtx)
+	}()
+
+	var (
+		userRepo = sqlite.NewUserRepository(db)
+		uow      = sqlite.NewUnitOfWork(db)
+	)
+
+	return app.Application{
+		Commands: app.Commands{
+			CreateUser:      command.MustNewCreateUserHandler(userRepo),
+			ChangeUserEmail: command.MustNewChangeUserEmailHandler(uow),
+			DeleteUser:      command.MustNewDeleteUserHandler(uow),
+			ImportUsers:     command.MustNewImportUsersHandler(userRepo),
+		},
+		Queries: app.Queries{
+			FindUsers:   query.MustNewFindUsersHandler(userRepo),
+			UserByID:    query.MustNewUserByIDHandler(userRepo),
+			StreamUsers: query.MustNewStreamUsersHandler(userRepo),
+		},
+	}, func() error {
+		cancelRelay()
+		wg.Wait()
+
+		err := db.Close()
+		if err != nil {
+			return fmt.Errorf("close database: %w", err)
+		}
+
+		return nil
+	}
+}
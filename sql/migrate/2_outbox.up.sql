@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    outbox_id        BIGSERIAL PRIMARY KEY,
+    aggregate_type   VARCHAR(255) NOT NULL,
+    aggregate_id     UUID NOT NULL,
+    event_type       VARCHAR(255) NOT NULL,
+    payload          JSONB NOT NULL,
+    attempts         INTEGER NOT NULL DEFAULT 0,
+    last_error       TEXT,
+
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    next_attempt_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    published_at     TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx
+    ON outbox (aggregate_id, outbox_id)
+    WHERE published_at IS NULL;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    webhook_subscription_id  UUID PRIMARY KEY,
+    url                      TEXT NOT NULL,
+    event_types              TEXT[] NOT NULL,
+    secret                   VARCHAR(255) NOT NULL,
+
+    created_at               TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    deleted_at               TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    webhook_delivery_id      UUID PRIMARY KEY,
+    webhook_subscription_id  UUID NOT NULL REFERENCES webhook_subscriptions (webhook_subscription_id),
+    event_id                 BIGINT NOT NULL,
+    event_type               VARCHAR(255) NOT NULL,
+    payload                  JSONB NOT NULL,
+    occurred_at              TIMESTAMP WITH TIME ZONE NOT NULL,
+    status                   VARCHAR(32) NOT NULL,
+    attempts                 INTEGER NOT NULL DEFAULT 0,
+    last_status_code         INTEGER NOT NULL DEFAULT 0,
+    last_error               TEXT NOT NULL DEFAULT '',
+
+    created_at               TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    next_attempt_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    delivered_at             TIMESTAMP WITH TIME ZONE,
+
+    UNIQUE (webhook_subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    job_id        BIGSERIAL PRIMARY KEY,
+    kind          VARCHAR(255) NOT NULL,
+    payload       JSONB NOT NULL,
+    status        VARCHAR(32) NOT NULL DEFAULT 'pending',
+    attempts      INTEGER NOT NULL DEFAULT 0,
+    max_attempts  INTEGER NOT NULL,
+    unique_key    VARCHAR(255) UNIQUE,
+    last_error    TEXT,
+
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    run_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    finished_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS jobs_pending_idx
+    ON jobs (run_at)
+    WHERE status = 'pending';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key  VARCHAR(64) PRIMARY KEY,
+    fingerprint      VARCHAR(64) NOT NULL,
+    response         BYTEA,
+
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires_at       TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx
+    ON idempotency_keys (expires_at);
@@ -3,8 +3,91 @@ CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 CREATE TABLE IF NOT EXISTS users (
     user_id          UUID PRIMARY KEY,
     email       VARCHAR(255)  UNIQUE,
+    version     BIGINT NOT NULL DEFAULT 1,
 
     created_at  TIMESTAMP WITH TIME ZONE,
     updated_at  TIMESTAMP WITH TIME ZONE,
     deleted_at  TIMESTAMP WITH TIME ZONE
-);
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    outbox_id        BIGSERIAL PRIMARY KEY,
+    aggregate_type   VARCHAR(255) NOT NULL,
+    aggregate_id     UUID NOT NULL,
+    event_type       VARCHAR(255) NOT NULL,
+    payload          JSONB NOT NULL,
+    attempts         INTEGER NOT NULL DEFAULT 0,
+    last_error       TEXT,
+
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    next_attempt_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    published_at     TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx
+    ON outbox (aggregate_id, outbox_id)
+    WHERE published_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    webhook_subscription_id  UUID PRIMARY KEY,
+    url                      TEXT NOT NULL,
+    event_types              TEXT[] NOT NULL,
+    secret                   VARCHAR(255) NOT NULL,
+
+    created_at               TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    deleted_at               TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    webhook_delivery_id      UUID PRIMARY KEY,
+    webhook_subscription_id  UUID NOT NULL REFERENCES webhook_subscriptions (webhook_subscription_id),
+    event_id                 BIGINT NOT NULL,
+    event_type               VARCHAR(255) NOT NULL,
+    payload                  JSONB NOT NULL,
+    occurred_at              TIMESTAMP WITH TIME ZONE NOT NULL,
+    status                   VARCHAR(32) NOT NULL,
+    attempts                 INTEGER NOT NULL DEFAULT 0,
+    last_status_code         INTEGER NOT NULL DEFAULT 0,
+    last_error               TEXT NOT NULL DEFAULT '',
+
+    created_at               TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    next_attempt_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    delivered_at             TIMESTAMP WITH TIME ZONE,
+
+    UNIQUE (webhook_subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS jobs (
+    job_id        BIGSERIAL PRIMARY KEY,
+    kind          VARCHAR(255) NOT NULL,
+    payload       JSONB NOT NULL,
+    status        VARCHAR(32) NOT NULL DEFAULT 'pending',
+    attempts      INTEGER NOT NULL DEFAULT 0,
+    max_attempts  INTEGER NOT NULL,
+    unique_key    VARCHAR(255) UNIQUE,
+    last_error    TEXT,
+
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    run_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    finished_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS jobs_pending_idx
+    ON jobs (run_at)
+    WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key  VARCHAR(64) PRIMARY KEY,
+    fingerprint      VARCHAR(64) NOT NULL,
+    response         BYTEA,
+
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires_at       TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx
+    ON idempotency_keys (expires_at);
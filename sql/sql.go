@@ -0,0 +1,14 @@
+// Package sql holds the database schemas and migrations.
+//
+// The SQLite migrations are embedded so the binary can
+// migrate a database without any other files.
+package sql
+
+import (
+	"embed"
+)
+
+// SQLiteMigrations holds the SQLite migrations, under the sqlite directory.
+//
+//go:embed sqlite/*.sql
+var SQLiteMigrations embed.FS
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    outbox_id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    aggregate_type   TEXT NOT NULL,
+    aggregate_id     TEXT NOT NULL,
+    event_type       TEXT NOT NULL,
+    payload          TEXT NOT NULL,
+    attempts         INTEGER NOT NULL DEFAULT 0,
+    last_error       TEXT,
+
+    created_at       TIMESTAMP NOT NULL,
+    next_attempt_at  TIMESTAMP NOT NULL,
+    published_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx
+    ON outbox (aggregate_id, outbox_id)
+    WHERE published_at IS NULL;